@@ -1,113 +1,296 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"go-grpc-rest-demo/internal/client"
+	"go-grpc-rest-demo/internal/client/output"
+	"go-grpc-rest-demo/internal/server/model"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
 	clientConfig *client.Config
 	cli          client.Client
+	printer      *output.Printer
+
+	// configPath and contextName pick the config file and its context
+	configPath, contextName string
+
+	// idempotencyKey is the key of the command's mutating request
+	idempotencyKey string
 )
 
 func main() {
 	clientConfig = client.DefaultConfig()
 
+	err := newRootCmd().Execute()
+	if cli != nil {
+		_ = cli.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCmd builds the command tree. Global flags default to the current
+// settings, so the shell can build a tree per line without losing them.
+func newRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "client",
 		Short: "CLI client for the gRPC REST demo",
 		Long:  "A command line interface to interact with the user and product services",
+		// main and the shell report the error once
+		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Arguments are valid by now, so later failures need no usage text
+			cmd.SilenceUsage = true
+			if idempotencyKey != "" {
+				cmd.SetContext(client.WithIdempotencyKey(cmd.Context(), idempotencyKey))
+			}
+
+			if session != nil {
+				return session.prepare(cmd)
+			}
+			if err := resolveConfig(cmd.Root().PersistentFlags()); err != nil {
+				return err
+			}
+
 			var err error
+			printer, err = output.NewPrinter(clientConfig.OutputFormat)
+			if err != nil {
+				return err
+			}
+
 			cli, err = client.NewClient(clientConfig)
 			if err != nil {
 				return fmt.Errorf("failed to create client: %v", err)
 			}
 			return nil
 		},
-		PersistentPostRun: func(cmd *cobra.Command, args []string) {
-			if cli != nil {
-				_ = cli.Close()
-			}
-		},
 	}
 
-	rootCmd.PersistentFlags().StringVarP(&clientConfig.Mode, "mode", "m", clientConfig.Mode, "Client mode: grpc, rest")
-	rootCmd.PersistentFlags().StringVar(&clientConfig.GRPCAddr, "grpc-addr", clientConfig.GRPCAddr, "gRPC server address")
-	rootCmd.PersistentFlags().StringVar(&clientConfig.RESTAddr, "rest-addr", clientConfig.RESTAddr, "REST server address")
-	rootCmd.PersistentFlags().DurationVar(&clientConfig.Timeout, "timeout", clientConfig.Timeout, "Request timeout")
-	rootCmd.PersistentFlags().StringVar(&clientConfig.OutputFormat, "output", clientConfig.OutputFormat, "Output format: json, table")
-	rootCmd.PersistentFlags().BoolVarP(&clientConfig.Verbose, "verbose", "v", clientConfig.Verbose, "Verbose output")
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&configPath, "config", configPath, "Config file (default: $GRPC_REST_DEMO_CONFIG or ~/.config/grpc-rest-demo/config.yaml)")
+	flags.StringVar(&contextName, "context", contextName, "Config file context to use (default: $GRPC_REST_DEMO_CONTEXT or the current context)")
+	flags.StringVarP(&clientConfig.Mode, "mode", "m", clientConfig.Mode, "Client mode: grpc, rest, both (reads run over both transports and are compared; writes use gRPC)")
+	flags.StringVar(&clientConfig.GRPCAddr, "grpc-addr", clientConfig.GRPCAddr, "gRPC server address")
+	flags.StringVar(&clientConfig.RESTAddr, "rest-addr", clientConfig.RESTAddr, "REST server address")
+	flags.DurationVar(&clientConfig.Timeout, "timeout", clientConfig.Timeout, "Request timeout")
+	flags.StringVarP(&clientConfig.OutputFormat, "output", "o", clientConfig.OutputFormat,
+		"Output format: json, yaml, table, wide, csv, go-template=TEMPLATE or jsonpath=EXPRESSION")
+	flags.BoolVarP(&clientConfig.Verbose, "verbose", "v", clientConfig.Verbose, "Verbose output")
+	flags.StringVar(&idempotencyKey, "idempotency-key", "", "Idempotency key of the command's mutating request (generated when empty)")
+	flags.StringVar(&clientConfig.Tenant, "tenant", clientConfig.Tenant, "Tenant to act for (the server's default tenant when empty)")
+	flags.StringVar(&clientConfig.Token, "token", clientConfig.Token, "Access token to send as a bearer token")
+	flags.BoolVar(&clientConfig.TLS.Enabled, "tls", clientConfig.TLS.Enabled, "Use TLS for gRPC (REST uses TLS for https addresses)")
+	flags.StringVar(&clientConfig.TLS.CAFile, "tls-ca-file", clientConfig.TLS.CAFile, "PEM file of the CAs to verify the server with (default: system roots)")
+	flags.StringVar(&clientConfig.TLS.ServerName, "tls-server-name", clientConfig.TLS.ServerName, "Name to verify the server certificate against")
+	flags.BoolVar(&clientConfig.TLS.InsecureSkipVerify, "tls-insecure-skip-verify", clientConfig.TLS.InsecureSkipVerify, "Accept any server certificate (for testing only)")
+	flags.IntVar(&clientConfig.Retry.MaxAttempts, "max-attempts", clientConfig.Retry.MaxAttempts, "Attempts per call on transient errors, including the first (1 disables retries)")
+	flags.DurationVar(&clientConfig.Retry.HedgingDelay, "hedging-delay", clientConfig.Retry.HedgingDelay, "Send another attempt of read-only gRPC calls after this long without a response (0 disables hedging)")
 
-	rootCmd.AddCommand(userCommands(), productCommands())
-
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	rootCmd.AddCommand(userCommands(), productCommands(), orderCommands(), authCommands(), tenantCommands(), configCommands(), shellCommand())
+	return rootCmd
 }
 
 func userCommands() *cobra.Command {
 	userCmd := &cobra.Command{
 		Use:   "user",
 		Short: "User management commands",
-		Long:  "Commands to manage users (create, get, update, delete, list)",
+		Long:  "Commands to manage users (create, get, update, delete, list, export, check-policy, verify-email, resend-verification)",
 	}
 
+	var username, email, fullName string
 	createUserCmd := &cobra.Command{
-		Use:   "create [username] [email] [full_name]",
+		Use:   "create --username NAME --email EMAIL --full-name NAME",
 		Short: "Create a new user",
-		Args:  cobra.ExactArgs(3),
-		Run: func(cmd *cobra.Command, args []string) {
-			var result any
-			var err error
-
-			if clientConfig.Mode == "grpc" {
-				result, err = cli.CreateUserGRPC(cmd.Context(), args[0], args[1], args[2])
-			} else {
-				result, err = cli.CreateUserREST(cmd.Context(), args[0], args[1], args[2])
-			}
-			printResult(result, err, "create user")
+		Args:  bindArgs("username", "email", "full-name"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := cli.Users().Create(cmd.Context(), username, email, fullName)
+			return printResult(result, err, "create user")
 		},
 	}
+	createUserCmd.Flags().StringVar(&username, "username", "", "Username")
+	createUserCmd.Flags().StringVar(&email, "email", "", "Email address")
+	createUserCmd.Flags().StringVar(&fullName, "full-name", "", "Full name")
 
+	var id string
 	getUserCmd := &cobra.Command{
-		Use:   "get [id]",
+		Use:   "get --id ID",
 		Short: "Get a user by ID",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			var result any
-			var err error
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRead(cmd, "get user", func(ctx context.Context, c client.Client) (any, error) {
+				return c.Users().Get(ctx, id)
+			})
+		},
+	}
+	getUserCmd.Flags().StringVar(&id, "id", "", "User ID")
 
-			if clientConfig.Mode == "grpc" {
-				result, err = cli.GetUserGRPC(cmd.Context(), args[0])
-			} else {
-				result, err = cli.GetUserREST(cmd.Context(), args[0])
-			}
-			printResult(result, err, "get user")
+	var active bool
+	updateUserCmd := &cobra.Command{
+		Use:   "update --id ID [--username] [--email] [--full-name] [--active]",
+		Short: "Update a user",
+		Long:  "Update the given fields of a user; fields without a flag keep their value. Use --active=false to deactivate the user.",
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := cli.Users().Update(cmd.Context(), id, optional(cmd, "username", username), optional(cmd, "email", email),
+				optional(cmd, "full-name", fullName), optional(cmd, "active", active))
+			return printResult(result, err, "update user")
 		},
 	}
+	updateUserCmd.Flags().StringVar(&id, "id", "", "User ID")
+	updateUserCmd.Flags().StringVar(&username, "username", "", "New username")
+	updateUserCmd.Flags().StringVar(&email, "email", "", "New email address")
+	updateUserCmd.Flags().StringVar(&fullName, "full-name", "", "New full name")
+	updateUserCmd.Flags().BoolVar(&active, "active", true, "Whether the user is active")
 
 	deleteUserCmd := &cobra.Command{
-		Use:   "delete [id]",
+		Use:   "delete --id ID",
 		Short: "Delete a user",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := cli.DeleteUser(cmd.Context(), args[0]); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete user: %v\n", err)
-				return
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.Users().Delete(cmd.Context(), id); err != nil {
+				return failed("delete user", err)
+			}
+			fmt.Printf("User %s deleted successfully\n", id)
+			return nil
+		},
+	}
+	deleteUserCmd.Flags().StringVar(&id, "id", "", "User ID")
+
+	var file, exportFormat, orderBy, filter string
+	exportUserCmd := &cobra.Command{
+		Use:   "export --file FILE",
+		Short: "Export users to a file",
+		Long:  "Write a point-in-time snapshot of the matching users to a file as NDJSON, CSV or length-delimited protobuf",
+		Args:  bindArgs("file"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := exportFormat
+			if format == "" {
+				format = formatFromPath(file, model.ExportFormatNDJSON)
+			}
+
+			return exportToFile(file, "users", func(f *os.File) (int64, error) {
+				return cli.Users().Export(cmd.Context(), format, orderBy, optional(cmd, "filter", filter), model.TimeRange{}, f)
+			})
+		},
+	}
+	exportUserCmd.Flags().StringVar(&file, "file", "", "File to write")
+	exportUserCmd.Flags().StringVar(&exportFormat, "format", "", "File format: ndjson, csv, protobuf (default: from file extension)")
+	exportUserCmd.Flags().StringVar(&orderBy, "order-by", "", `Ordering such as "created_at desc, username"`)
+	exportUserCmd.Flags().StringVar(&orderBy, "sort-by", "", "Sort by a single field (same as --order-by)")
+	exportUserCmd.Flags().StringVar(&filter, "filter", "", `Filter expression such as "is_active = false AND email = \"*@corp.com\"", or a bare value matching username, email or full_name`)
+
+	var page, pageSize int32
+	listUsersCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List users",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRead(cmd, "list users", func(ctx context.Context, c client.Client) (any, error) {
+				result, err := c.Users().List(ctx, page, pageSize, orderBy, optional(cmd, "filter", filter), model.TimeRange{})
+				return listOf("users", result, err)
+			})
+		},
+	}
+	listUsersCmd.Flags().Int32Var(&page, "page", 1, "Page number")
+	listUsersCmd.Flags().Int32Var(&pageSize, "page-size", 10, "Items per page")
+	listUsersCmd.Flags().StringVar(&orderBy, "order-by", "", `Ordering such as "created_at desc, username"`)
+	listUsersCmd.Flags().StringVar(&orderBy, "sort-by", "", "Sort by a single field (same as --order-by)")
+	listUsersCmd.Flags().StringVar(&filter, "filter", "", `Filter expression such as "is_active = false AND email = \"*@corp.com\"", or a bare value matching username, email or full_name`)
+
+	var repair bool
+	checkPolicyCmd := &cobra.Command{
+		Use:   "check-policy",
+		Short: "Report users that break the username and email policy",
+		Long:  "Report stored users whose username or email breaks the identity policy. With --repair, values that only need normalizing are rewritten; other violations are left for manual attention.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repair {
+				result, err := cli.Users().CheckPolicy(cmd.Context(), repair)
+				return printResult(result, err, "check user policy")
+			}
+			return runRead(cmd, "check user policy", func(ctx context.Context, c client.Client) (any, error) {
+				return c.Users().CheckPolicy(ctx, false)
+			})
+		},
+	}
+	checkPolicyCmd.Flags().BoolVar(&repair, "repair", false, "Rewrite values that only need normalizing")
+
+	var token string
+	verifyEmailCmd := &cobra.Command{
+		Use:   "verify-email --token TOKEN",
+		Short: "Verify a user's email with the token sent to it",
+		Args:  bindArgs("token"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := cli.Users().VerifyEmail(cmd.Context(), token)
+			return printResult(result, err, "verify email")
+		},
+	}
+	verifyEmailCmd.Flags().StringVar(&token, "token", "", "Verification token")
+
+	resendVerificationCmd := &cobra.Command{
+		Use:   "resend-verification --id ID",
+		Short: "Send a user a new email verification token",
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.Users().ResendVerification(cmd.Context(), id); err != nil {
+				return failed("resend verification", err)
+			}
+			fmt.Printf("Verification email sent to user %s\n", id)
+			return nil
+		},
+	}
+	resendVerificationCmd.Flags().StringVar(&id, "id", "", "User ID")
+
+	var password, currentPassword, setupToken string
+	setPasswordCmd := &cobra.Command{
+		Use:   "set-password --id ID --password PASSWORD [--setup-token TOKEN]",
+		Short: "Set a user's password",
+		Long:  "Set a user's password without the current one. Replacing an existing password needs the user's own access token (--token); without one only the first password can be set, with the setup token returned when the user was created (--setup-token).",
+		Args:  bindArgs("id", "password"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.Users().SetPassword(cmd.Context(), id, password, setupToken); err != nil {
+				return failed("set password", err)
+			}
+			fmt.Printf("Password of user %s set successfully\n", id)
+			return nil
+		},
+	}
+	setPasswordCmd.Flags().StringVar(&id, "id", "", "User ID")
+	setPasswordCmd.Flags().StringVar(&password, "password", "", "New password")
+	setPasswordCmd.Flags().StringVar(&setupToken, "setup-token", "", "Password setup token from user creation")
+
+	changePasswordCmd := &cobra.Command{
+		Use:   "change-password --id ID --current-password PASSWORD --new-password PASSWORD",
+		Short: "Change a user's password",
+		Args:  bindArgs("id", "current-password", "new-password"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.Users().ChangePassword(cmd.Context(), id, currentPassword, password); err != nil {
+				return failed("change password", err)
 			}
-			fmt.Printf("User %s deleted successfully\n", args[0])
+			fmt.Printf("Password of user %s changed successfully\n", id)
+			return nil
 		},
 	}
+	changePasswordCmd.Flags().StringVar(&id, "id", "", "User ID")
+	changePasswordCmd.Flags().StringVar(&currentPassword, "current-password", "", "Current password")
+	changePasswordCmd.Flags().StringVar(&password, "new-password", "", "New password")
 
-	userCmd.AddCommand(createUserCmd, getUserCmd, deleteUserCmd)
+	userCmd.AddCommand(createUserCmd, getUserCmd, updateUserCmd, deleteUserCmd, listUsersCmd, exportUserCmd, checkPolicyCmd, verifyEmailCmd,
+		resendVerificationCmd, setPasswordCmd, changePasswordCmd)
 	return userCmd
 }
 
@@ -115,69 +298,646 @@ func productCommands() *cobra.Command {
 	productCmd := &cobra.Command{
 		Use:   "product",
 		Short: "Product management commands",
-		Long:  "Commands to manage products (create, get, search)",
+		Long:  "Commands to manage products (create, get, update, delete, search, import, export)",
 	}
 
+	var name, description, price, currency, category string
+	var quantity int32
 	createProductCmd := &cobra.Command{
-		Use:   "create [name] [description] [price] [quantity] [category]",
+		Use:   "create --name NAME --description TEXT --price PRICE --quantity N --category NAME",
 		Short: "Create a new product",
-		Args:  cobra.ExactArgs(5),
-		Run: func(cmd *cobra.Command, args []string) {
-			price, err := strconv.ParseFloat(args[2], 64)
+		Args:  bindArgs("name", "description", "price", "quantity", "category"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			money, err := model.ParseMoney(currency, price)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Invalid price: %v\n", err)
-				return
-			}
-			quantity, err := strconv.ParseInt(args[3], 10, 32)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Invalid quantity: %v\n", err)
-				return
+				return fmt.Errorf("invalid price: %v", err)
 			}
 
-			var result any
-			if clientConfig.Mode == "grpc" {
-				result, err = cli.CreateProductGRPC(cmd.Context(), args[0], args[1], args[4], price, int32(quantity))
-			} else {
-				result, err = cli.CreateProductREST(cmd.Context(), args[0], args[1], args[4], price, int32(quantity))
-			}
-			printResult(result, err, "create product")
+			result, err := cli.Products().Create(cmd.Context(), name, description, category, money, quantity)
+			return printResult(result, err, "create product")
 		},
 	}
+	createProductCmd.Flags().StringVar(&name, "name", "", "Product name")
+	createProductCmd.Flags().StringVar(&description, "description", "", "Product description")
+	createProductCmd.Flags().StringVar(&price, "price", "", "Price such as 12.50")
+	createProductCmd.Flags().Int32Var(&quantity, "quantity", 0, "Quantity in stock")
+	createProductCmd.Flags().StringVar(&category, "category", "", "Category name")
+	createProductCmd.Flags().StringVar(&currency, "currency", model.DefaultCurrency, "Currency code of the price")
 
+	var id string
 	getProductCmd := &cobra.Command{
-		Use:   "get [id]",
+		Use:   "get --id ID",
 		Short: "Get a product by ID",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			var result any
-			var err error
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRead(cmd, "get product", func(ctx context.Context, c client.Client) (any, error) {
+				return c.Products().Get(ctx, id)
+			})
+		},
+	}
+	getProductCmd.Flags().StringVar(&id, "id", "", "Product ID")
+
+	updateProductCmd := &cobra.Command{
+		Use:   "update --id ID [--name] [--description] [--price] [--quantity] [--category]",
+		Short: "Update a product",
+		Long:  "Update the given fields of a product; fields without a flag keep their value",
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var money *model.Money
+			if cmd.Flags().Changed("price") {
+				parsed, err := model.ParseMoney(currency, price)
+				if err != nil {
+					return fmt.Errorf("invalid price: %v", err)
+				}
+				money = &parsed
+			}
+
+			result, err := cli.Products().Update(cmd.Context(), id, optional(cmd, "name", name), optional(cmd, "description", description),
+				optional(cmd, "category", category), money, optional(cmd, "quantity", quantity))
+			return printResult(result, err, "update product")
+		},
+	}
+	updateProductCmd.Flags().StringVar(&id, "id", "", "Product ID")
+	updateProductCmd.Flags().StringVar(&name, "name", "", "New name")
+	updateProductCmd.Flags().StringVar(&description, "description", "", "New description")
+	updateProductCmd.Flags().StringVar(&price, "price", "", "New price such as 12.50")
+	updateProductCmd.Flags().StringVar(&currency, "currency", model.DefaultCurrency, "Currency code of the new price")
+	updateProductCmd.Flags().Int32Var(&quantity, "quantity", 0, "New quantity in stock")
+	updateProductCmd.Flags().StringVar(&category, "category", "", "New category name")
 
-			if clientConfig.Mode == "grpc" {
-				result, err = cli.GetProductGRPC(cmd.Context(), args[0])
-			} else {
-				result, err = cli.GetProductREST(cmd.Context(), args[0])
+	deleteProductCmd := &cobra.Command{
+		Use:   "delete --id ID",
+		Short: "Delete a product",
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.Products().Delete(cmd.Context(), id); err != nil {
+				return failed("delete product", err)
 			}
-			printResult(result, err, "get product")
+			fmt.Printf("Product %s deleted successfully\n", id)
+			return nil
+		},
+	}
+	deleteProductCmd.Flags().StringVar(&id, "id", "", "Product ID")
+
+	var file, importFormat string
+	importProductCmd := &cobra.Command{
+		Use:   "import --file FILE",
+		Short: "Import products from a CSV or NDJSON file",
+		Long:  "Upsert products from a CSV file with a header row (name,description,price,quantity,category) or an NDJSON file with one product per line",
+		Args:  bindArgs("file"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := importFormat
+			if format == "" {
+				format = formatFromPath(file, model.ImportFormatCSV)
+			}
+
+			f, err := os.Open(file)
+			if err != nil {
+				return fmt.Errorf("failed to open file: %v", err)
+			}
+			defer func() { _ = f.Close() }()
+
+			result, err := cli.Products().Import(cmd.Context(), format, f)
+			return printResult(result, err, "import products")
+		},
+	}
+	importProductCmd.Flags().StringVar(&file, "file", "", "File to read")
+	importProductCmd.Flags().StringVar(&importFormat, "format", "", "File format: csv, ndjson (default: from file extension)")
+
+	var exportFormat, query, categoryID, orderBy, searchFilter string
+	var minPrice, maxPrice float64
+	var includeDescendants bool
+	exportProductCmd := &cobra.Command{
+		Use:   "export --file FILE",
+		Short: "Export products to a file",
+		Long:  "Write a point-in-time snapshot of the matching products to a file as NDJSON, CSV or length-delimited protobuf",
+		Args:  bindArgs("file"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := exportFormat
+			if format == "" {
+				format = formatFromPath(file, model.ExportFormatNDJSON)
+			}
+
+			return exportToFile(file, "products", func(f *os.File) (int64, error) {
+				return cli.Products().Export(cmd.Context(), format, optional(cmd, "query", query), optional(cmd, "category", category),
+					optional(cmd, "category-id", categoryID), optional(cmd, "filter", searchFilter), includeDescendants,
+					optional(cmd, "min-price", minPrice), optional(cmd, "max-price", maxPrice), model.TimeRange{}, orderBy, f)
+			})
 		},
 	}
+	exportProductCmd.Flags().StringVar(&file, "file", "", "File to write")
+	exportProductCmd.Flags().StringVar(&exportFormat, "format", "", "File format: ndjson, csv, protobuf (default: from file extension)")
+	exportProductCmd.Flags().StringVar(&query, "query", "", "Search query (matches name or description)")
+	exportProductCmd.Flags().StringVar(&category, "category", "", "Filter by category")
+	exportProductCmd.Flags().Float64Var(&minPrice, "min-price", 0, "Minimum price filter")
+	exportProductCmd.Flags().Float64Var(&maxPrice, "max-price", 0, "Maximum price filter")
+	exportProductCmd.Flags().StringVar(&categoryID, "category-id", "", "Filter by category ID")
+	exportProductCmd.Flags().BoolVar(&includeDescendants, "include-descendants", false, "Also export products in descendants of --category-id")
+	exportProductCmd.Flags().StringVar(&searchFilter, "filter", "", `Filter expression such as "quantity > 0 AND name:pro"`)
+	exportProductCmd.Flags().StringVar(&orderBy, "order-by", "", `Ordering such as "price desc, name" (default: name)`)
 
-	productCmd.AddCommand(createProductCmd, getProductCmd)
+	var page, pageSize int32
+	searchProductsCmd := &cobra.Command{
+		Use:   "search",
+		Short: "Search products",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRead(cmd, "search products", func(ctx context.Context, c client.Client) (any, error) {
+				result, err := c.Products().Search(ctx, optional(cmd, "query", query), optional(cmd, "category", category),
+					optional(cmd, "filter", searchFilter), optional(cmd, "min-price", minPrice), optional(cmd, "max-price", maxPrice),
+					model.TimeRange{}, orderBy, page, pageSize)
+				return listOf("products", result, err)
+			})
+		},
+	}
+	searchProductsCmd.Flags().StringVar(&query, "query", "", "Search query (matches name or description)")
+	searchProductsCmd.Flags().StringVar(&category, "category", "", "Filter by category")
+	searchProductsCmd.Flags().Float64Var(&minPrice, "min-price", 0, "Minimum price filter")
+	searchProductsCmd.Flags().Float64Var(&maxPrice, "max-price", 0, "Maximum price filter")
+	searchProductsCmd.Flags().StringVar(&searchFilter, "filter", "", `Filter expression such as "quantity > 0 AND name:pro"`)
+	searchProductsCmd.Flags().StringVar(&orderBy, "order-by", "", `Ordering such as "price desc, name" (default: name)`)
+	searchProductsCmd.Flags().Int32Var(&page, "page", 1, "Page number")
+	searchProductsCmd.Flags().Int32Var(&pageSize, "page-size", 10, "Items per page")
+
+	productCmd.AddCommand(createProductCmd, getProductCmd, updateProductCmd, deleteProductCmd, searchProductsCmd, importProductCmd, exportProductCmd)
 	return productCmd
 }
 
-func printResult(v any, err error, operation string) {
+func orderCommands() *cobra.Command {
+	orderCmd := &cobra.Command{
+		Use:   "order",
+		Short: "Order management commands",
+		Long:  "Commands to manage orders (create, get, list, cancel)",
+	}
+
+	var userID string
+	var itemArgs []string
+	createOrderCmd := &cobra.Command{
+		Use:   "create --user-id ID --item PRODUCT_ID:QUANTITY...",
+		Short: "Place an order",
+		Args: func(cmd *cobra.Command, args []string) error {
+			// Positional form: [user_id] [product_id:quantity]...
+			if !cmd.Flags().Changed("user-id") && len(args) > 0 {
+				userID, args = args[0], args[1:]
+			}
+			itemArgs = append(itemArgs, args...)
+			if userID == "" {
+				return fmt.Errorf(`required flag(s) "user-id" not set`)
+			}
+			if len(itemArgs) == 0 {
+				return fmt.Errorf(`required flag(s) "item" not set`)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := parseOrderItems(itemArgs)
+			if err != nil {
+				return fmt.Errorf("invalid order item: %v", err)
+			}
+
+			result, err := cli.Orders().Create(cmd.Context(), userID, items)
+			return printResult(result, err, "create order")
+		},
+	}
+	createOrderCmd.Flags().StringVar(&userID, "user-id", "", "ID of the ordering user")
+	createOrderCmd.Flags().StringArrayVar(&itemArgs, "item", nil, "Ordered product as product_id:quantity (repeatable)")
+
+	var id string
+	getOrderCmd := &cobra.Command{
+		Use:   "get --id ID",
+		Short: "Get an order by ID",
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRead(cmd, "get order", func(ctx context.Context, c client.Client) (any, error) {
+				return c.Orders().Get(ctx, id)
+			})
+		},
+	}
+	getOrderCmd.Flags().StringVar(&id, "id", "", "Order ID")
+
+	var page, pageSize int32
+	listOrdersCmd := &cobra.Command{
+		Use:   "list --user-id ID",
+		Short: "List the orders of a user",
+		Args:  bindArgs("user-id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRead(cmd, "list orders", func(ctx context.Context, c client.Client) (any, error) {
+				result, err := c.Orders().List(ctx, userID, page, pageSize)
+				return listOf("orders", result, err)
+			})
+		},
+	}
+	listOrdersCmd.Flags().StringVar(&userID, "user-id", "", "ID of the user whose orders to list")
+	listOrdersCmd.Flags().Int32Var(&page, "page", 1, "Page number")
+	listOrdersCmd.Flags().Int32Var(&pageSize, "page-size", 10, "Items per page")
+
+	cancelOrderCmd := &cobra.Command{
+		Use:   "cancel --id ID",
+		Short: "Cancel an order and restore its stock",
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := cli.Orders().Cancel(cmd.Context(), id)
+			return printResult(result, err, "cancel order")
+		},
+	}
+	cancelOrderCmd.Flags().StringVar(&id, "id", "", "Order ID")
+
+	orderCmd.AddCommand(createOrderCmd, getOrderCmd, listOrdersCmd, cancelOrderCmd)
+	return orderCmd
+}
+
+func authCommands() *cobra.Command {
+	authCmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Authentication commands",
+		Long:  "Commands to log in and refresh access tokens",
+	}
+
+	var username, password string
+	loginCmd := &cobra.Command{
+		Use:   "login --username NAME --password PASSWORD",
+		Short: "Log in with a username or email and password",
+		Args:  bindArgs("username", "password"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := cli.Auth().Login(cmd.Context(), username, password)
+			return printResult(result, err, "log in")
+		},
+	}
+	loginCmd.Flags().StringVar(&username, "username", "", "Username or email")
+	loginCmd.Flags().StringVar(&password, "password", "", "Password")
+
+	var refreshToken string
+	refreshCmd := &cobra.Command{
+		Use:   "refresh --refresh-token TOKEN",
+		Short: "Exchange a refresh token for new tokens",
+		Args:  bindArgs("refresh-token"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := cli.Auth().Refresh(cmd.Context(), refreshToken)
+			return printResult(result, err, "refresh token")
+		},
+	}
+	refreshCmd.Flags().StringVar(&refreshToken, "refresh-token", "", "Refresh token from login")
+
+	authCmd.AddCommand(loginCmd, refreshCmd)
+	return authCmd
+}
+
+func tenantCommands() *cobra.Command {
+	tenantCmd := &cobra.Command{
+		Use:   "tenant",
+		Short: "Tenant management commands",
+		Long:  "Commands to manage the tenants users and products are scoped to (create, get, list, delete)",
+	}
+
+	var id, displayName string
+	createTenantCmd := &cobra.Command{
+		Use:   "create --id ID --display-name NAME",
+		Short: "Create a new tenant",
+		Args:  bindArgs("id", "display-name"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := cli.Tenants().Create(cmd.Context(), id, displayName)
+			return printResult(result, err, "create tenant")
+		},
+	}
+	createTenantCmd.Flags().StringVar(&id, "id", "", "Tenant ID")
+	createTenantCmd.Flags().StringVar(&displayName, "display-name", "", "Display name")
+
+	getTenantCmd := &cobra.Command{
+		Use:   "get --id ID",
+		Short: "Get a tenant by ID",
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRead(cmd, "get tenant", func(ctx context.Context, c client.Client) (any, error) {
+				return c.Tenants().Get(ctx, id)
+			})
+		},
+	}
+	getTenantCmd.Flags().StringVar(&id, "id", "", "Tenant ID")
+
+	listTenantsCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tenants",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRead(cmd, "list tenants", func(ctx context.Context, c client.Client) (any, error) {
+				return c.Tenants().List(ctx)
+			})
+		},
+	}
+
+	deleteTenantCmd := &cobra.Command{
+		Use:   "delete --id ID",
+		Short: "Delete a tenant without users, products, categories or orders",
+		Args:  bindArgs("id"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.Tenants().Delete(cmd.Context(), id); err != nil {
+				return failed("delete tenant", err)
+			}
+			fmt.Printf("Tenant %s deleted successfully\n", id)
+			return nil
+		},
+	}
+	deleteTenantCmd.Flags().StringVar(&id, "id", "", "Tenant ID")
+
+	tenantCmd.AddCommand(createTenantCmd, getTenantCmd, listTenantsCmd, deleteTenantCmd)
+	return tenantCmd
+}
+
+func configCommands() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Client configuration commands",
+		Long: "Commands to manage the named contexts of the config file (use-context, get-contexts, set). " +
+			"Settings apply with flags first, then GRPC_REST_DEMO_* environment variables, then the context, then the defaults.",
+		// The config commands work on the file alone and need no client
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return nil
+		},
+	}
+
+	var name string
+	useContextCmd := &cobra.Command{
+		Use:   "use-context --name NAME",
+		Short: "Make a context the current one",
+		Args:  bindArgs("name"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := updateConfigFile(func(file *client.ConfigFile) error {
+				return file.UseContext(name)
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Switched to context %s\n", name)
+			return nil
+		},
+	}
+	useContextCmd.Flags().StringVar(&name, "name", "", "Context name")
+
+	getContextsCmd := &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List the contexts of the config file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := client.ConfigPath(configPath)
+			if err != nil {
+				return err
+			}
+			file, err := client.LoadConfigFile(path)
+			if err != nil {
+				return err
+			}
+
+			current := file.ContextName(contextName)
+			tw := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+			fmt.Fprintln(tw, "CURRENT\tNAME\tGRPC ADDR\tREST ADDR\tMODE\tTENANT\tOUTPUT")
+			for _, name := range file.ContextNames() {
+				ctx := file.Contexts[name]
+				marker := ""
+				if name == current {
+					marker = "*"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", marker, name, ctx.GRPCAddr, ctx.RESTAddr, ctx.Mode, ctx.Tenant, ctx.Output)
+			}
+			return tw.Flush()
+		},
+	}
+
+	var key, value string
+	setCmd := &cobra.Command{
+		Use:   "set --key KEY --value VALUE",
+		Short: "Change a setting of a context",
+		Long: "Change a setting of the context named by --context, or of the current context, creating the context if needed. " +
+			"Keys: " + strings.Join(client.ContextKeys, ", ") + ".",
+		Args: bindArgs("key", "value"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if key == "output" {
+				if _, err := output.NewPrinter(value); err != nil {
+					return err
+				}
+			}
+			var name string
+			err := updateConfigFile(func(file *client.ConfigFile) error {
+				name = file.ContextName(contextName)
+				return file.Set(name, key, value)
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Set %s of context %s\n", key, name)
+			return nil
+		},
+	}
+	setCmd.Flags().StringVar(&key, "key", "", "Setting to change")
+	setCmd.Flags().StringVar(&value, "value", "", "New value")
+
+	configCmd.AddCommand(useContextCmd, getContextsCmd, setCmd)
+	return configCmd
+}
+
+// resolveConfig applies the config file context and the environment under
+// the flags given on the command line, which keep their values
+func resolveConfig(flags *pflag.FlagSet) error {
+	given := map[*pflag.Flag]string{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			given[f] = f.Value.String()
+		}
+	})
+
+	config, err := client.LoadConfig(configPath, contextName)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to %s (%s): %v\n", operation, clientConfig.Mode, err)
-		return
+		return err
 	}
-	printJSON(v)
+	*clientConfig = *config
+	for f, value := range given {
+		if err := f.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid argument %q for %q flag: %v", value, "--"+f.Name, err)
+		}
+	}
+	return nil
+}
+
+// updateConfigFile loads the config file, changes it and saves it
+func updateConfigFile(update func(file *client.ConfigFile) error) error {
+	path, err := client.ConfigPath(configPath)
+	if err != nil {
+		return err
+	}
+	file, err := client.LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if err := update(file); err != nil {
+		return err
+	}
+	return file.Save(path)
+}
+
+// bindArgs makes the named flags required and lets positional arguments
+// stand in for them, in order, skipping flags that were given by name. So
+// "user get 42" and "user get --id 42" are the same command.
+func bindArgs(names ...string) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		var missing []string
+		for _, name := range names {
+			if cmd.Flags().Changed(name) {
+				continue
+			}
+			if len(args) == 0 {
+				missing = append(missing, name)
+				continue
+			}
+			if err := cmd.Flags().Set(name, args[0]); err != nil {
+				return fmt.Errorf("invalid argument %q for %q flag: %v", args[0], "--"+name, err)
+			}
+			args = args[1:]
+		}
+
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected argument(s) %q", args)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf(`required flag(s) "%s" not set`, strings.Join(missing, `", "`))
+		}
+		return nil
+	}
+}
+
+// parseOrderItems parses product_id:quantity arguments
+func parseOrderItems(args []string) ([]model.CreateOrderItem, error) {
+	items := make([]model.CreateOrderItem, len(args))
+	for i, arg := range args {
+		productID, quantityStr, ok := strings.Cut(arg, ":")
+		if !ok || productID == "" {
+			return nil, fmt.Errorf("%q is not in product_id:quantity form", arg)
+		}
+		quantity, err := strconv.ParseInt(quantityStr, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q has an invalid quantity", arg)
+		}
+		items[i] = model.CreateOrderItem{ProductID: productID, Quantity: int32(quantity)}
+	}
+	return items, nil
+}
+
+// formatFromPath guesses an import or export format from a file extension
+func formatFromPath(path, fallback string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return model.ExportFormatNDJSON
+	case ".csv":
+		return model.ExportFormatCSV
+	case ".pb", ".binpb":
+		return model.ExportFormatProtobuf
+	default:
+		return fallback
+	}
+}
+
+// optional returns the flag value only when the flag was set
+func optional[T any](cmd *cobra.Command, name string, value T) *T {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+	return &value
+}
+
+// exportToFile runs an export into a new file, removing the file if the export fails
+func exportToFile(path, resource string, export func(file *os.File) (int64, error)) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+
+	n, err := export(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return failed("export "+resource, err)
+	}
+	fmt.Printf("Exported %s to %s (%d bytes)\n", resource, path, n)
+	return nil
+}
+
+// failed describes an error returned by the server for the failed operation
+func failed(operation string, err error) error {
+	return fmt.Errorf("failed to %s (%s): %w", operation, clientConfig.Mode, err)
+}
+
+func printResult(v any, err error, operation string) error {
+	if err != nil {
+		return failed(operation, err)
+	}
+	if session != nil && strings.HasPrefix(operation, "create ") {
+		session.remember(v)
+	}
+	return printer.Print(os.Stdout, v)
+}
+
+// listOf shows a page under the name of the resource it lists
+func listOf[T any](resource string, page *client.Page[T], err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return output.List{
+		Resource:   resource,
+		Items:      page.Items,
+		TotalCount: page.TotalCount,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+	}, nil
 }
 
-func printJSON(v any) {
-	data, err := json.MarshalIndent(v, "", "  ")
+// runRead prints the result of a read-only call. In both mode the call runs
+// over gRPC and REST at once: the gRPC result is printed, the latencies and
+// differences are reported on stderr, and any difference fails the command.
+func runRead(cmd *cobra.Command, operation string, call func(ctx context.Context, c client.Client) (any, error)) error {
+	if clientConfig.Mode != "both" {
+		result, err := call(cmd.Context(), cli)
+		return printResult(result, err, operation)
+	}
+
+	report, err := cli.Compare(cmd.Context(), operation, call)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to marshal JSON: %v\n", err)
-		return
+		return failed(operation, err)
+	}
+	if err := printParity(report); err != nil {
+		return err
+	}
+	if err := printResult(report.GRPC.Value, report.GRPC.Err, operation); err != nil {
+		return err
+	}
+	if len(report.Differences) > 0 {
+		return fmt.Errorf("gRPC and REST results of %s differ in %d field(s)", operation, len(report.Differences))
+	}
+	return nil
+}
+
+// printParity reports the latency of each transport and the fields whose
+// results differ
+func printParity(report *client.ParityReport) error {
+	w := os.Stderr
+	fmt.Fprintf(w, "%s: gRPC %s, REST %s", report.Operation, report.GRPC.Latency.Round(time.Microsecond), report.REST.Latency.Round(time.Microsecond))
+	if len(report.Differences) == 0 {
+		fmt.Fprintln(w, ", results match")
+		return nil
+	}
+	fmt.Fprintf(w, ", %d difference(s)\n", len(report.Differences))
+
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, "  FIELD\tGRPC\tREST")
+	for _, d := range report.Differences {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", d.Path, orMissing(d.GRPC), orMissing(d.REST))
+	}
+	return tw.Flush()
+}
+
+func orMissing(value string) string {
+	if value == "" {
+		return "(missing)"
 	}
-	fmt.Println(string(data))
+	return value
 }
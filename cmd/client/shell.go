@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"go-grpc-rest-demo/internal/client"
+	"go-grpc-rest-demo/internal/client/output"
+	"go-grpc-rest-demo/internal/client/shell"
+	"go-grpc-rest-demo/internal/server/model"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// maxHistory is how many lines the shell history file keeps
+const maxHistory = 500
+
+// session is the running shell, or nil outside it
+var session *shellSession
+
+// shellSession keeps one client open across the lines of the shell. Each
+// line runs on a new command tree starting from config, so flags given on
+// a line only apply to that line.
+type shellSession struct {
+	config client.Config
+	client client.Client
+
+	editor      *shell.LineEditor
+	historyPath string
+	// vars are the $variables of command lines
+	vars     map[string]string
+	failures int
+	done     bool
+}
+
+// dialSettings are the settings a client connects with; the others are read
+// on every call
+type dialSettings struct {
+	mode, grpcAddr, restAddr string
+	tls                      client.TLSConfig
+	maxAttempts              int
+	hedgingDelay             time.Duration
+}
+
+func dialSettingsOf(c *client.Config) dialSettings {
+	return dialSettings{c.Mode, c.GRPCAddr, c.RESTAddr, c.TLS, c.Retry.MaxAttempts, c.Retry.HedgingDelay}
+}
+
+func shellCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell",
+		Short: "Start an interactive shell",
+		Long: "Run commands at a prompt over one connection, with history, tab completion and $variables. " +
+			"Lines passing a password or token are left out of the history. " +
+			"Created IDs are kept as $last_user, $last_product, $last_order and $last_tenant, " +
+			"and a JSON object ending a line, which may span lines, gives the flags of a command: " +
+			`user create {"username": "ann", "email": "ann@example.com", "full_name": "Ann"}. ` +
+			"Use mode and use-context to switch the connection. Without a terminal, commands are read from stdin " +
+			"and the shell fails if any of them does.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if session != nil {
+				return fmt.Errorf("already in the shell")
+			}
+			if idempotencyKey != "" {
+				return fmt.Errorf("--idempotency-key names one request; give it on the shell line that sends it")
+			}
+			return runShell()
+		},
+	}
+}
+
+func runShell() error {
+	s := &shellSession{
+		config: *clientConfig,
+		client: cli,
+		editor: shell.NewLineEditor(os.Stdin, os.Stdout),
+		vars:   map[string]string{},
+	}
+	s.editor.Complete = s.complete
+	session = s
+	defer func() { session = nil }()
+
+	if s.editor.Interactive() {
+		if path, err := client.ConfigPath(configPath); err == nil {
+			s.historyPath = filepath.Join(filepath.Dir(path), "history")
+			s.loadHistory()
+		}
+		fmt.Printf("Connected in %s mode (%s). Type \"help\" for commands and \"exit\" to leave.\n", s.config.Mode, s.address())
+	}
+
+	for !s.done {
+		line, err := s.readCommand()
+		if errors.Is(err, shell.ErrInterrupted) {
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.run(line); err != nil {
+			s.failures++
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+
+	s.saveHistory()
+	if !s.editor.Interactive() && s.failures > 0 {
+		return fmt.Errorf("%d command(s) failed", s.failures)
+	}
+	return nil
+}
+
+// readCommand reads a line, and the further lines of a JSON payload it
+// starts, as one line. Lines passing a password or token are left out of
+// the history.
+func (s *shellSession) readCommand() (string, error) {
+	line, err := s.editor.ReadLine(s.prompt())
+	if err != nil {
+		return "", err
+	}
+	command, payload, found := shell.CutPayload(line)
+	for found && !shell.PayloadComplete(payload) {
+		more, err := s.editor.ReadLine("... ")
+		if err != nil {
+			return "", err
+		}
+		payload += " " + strings.TrimSpace(more)
+	}
+
+	line = command + payload
+	if !shell.HasSecret(line) {
+		s.editor.AddHistory(line)
+	}
+	return line, nil
+}
+
+// run executes one command line
+func (s *shellSession) run(line string) error {
+	command, payload, found := shell.CutPayload(line)
+	args, err := shell.SplitWords(command, s.lookup)
+	if err != nil {
+		return err
+	}
+	if found {
+		flags, err := shell.PayloadFlags(payload, s.lookup)
+		if err != nil {
+			return err
+		}
+		args = append(args, flags...)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	// Ctrl-C cancels the running command instead of leaving the shell
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	*clientConfig = s.config
+	defer func() {
+		if cli != s.client {
+			_ = cli.Close()
+			cli = s.client
+		}
+		*clientConfig = s.config
+	}()
+
+	root := s.newRootCmd()
+	root.SetArgs(args)
+	return root.ExecuteContext(ctx)
+}
+
+// newRootCmd adds the shell commands to the command tree
+func (s *shellSession) newRootCmd() *cobra.Command {
+	root := newRootCmd()
+	root.CompletionOptions.DisableDefaultCmd = true
+	root.AddCommand(s.commands()...)
+	return root
+}
+
+// prepare readies a line's command: its printer, and its own client when
+// the line changes how to connect
+func (s *shellSession) prepare(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+	if flags.Changed("config") || flags.Changed("context") {
+		return fmt.Errorf("use use-context to switch contexts in the shell")
+	}
+
+	var err error
+	printer, err = output.NewPrinter(clientConfig.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	if dialSettingsOf(clientConfig) != dialSettingsOf(&s.config) {
+		c, err := client.NewClient(clientConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %v", err)
+		}
+		cli = c
+	}
+	return nil
+}
+
+// reconnect replaces the session's client with one for config
+func (s *shellSession) reconnect(config client.Config) error {
+	*clientConfig = config
+	c, err := client.NewClient(clientConfig)
+	if err != nil {
+		*clientConfig = s.config
+		return fmt.Errorf("failed to create client: %v", err)
+	}
+
+	_ = s.client.Close()
+	s.client, s.config, cli = c, config, c
+	fmt.Printf("Connected in %s mode (%s)\n", config.Mode, s.address())
+	return nil
+}
+
+// commands are the commands only the shell has
+func (s *shellSession) commands() []*cobra.Command {
+	modeCmd := &cobra.Command{
+		Use:               "mode [grpc|rest|both]",
+		Short:             "Show the client mode or switch to another",
+		Args:              cobra.MaximumNArgs(1),
+		PersistentPreRunE: skipClient,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				fmt.Println(s.config.Mode)
+				return nil
+			}
+			config := s.config
+			config.Mode = args[0]
+			return s.reconnect(config)
+		},
+	}
+
+	var name, value string
+	useContextCmd := &cobra.Command{
+		Use:               "use-context --name NAME",
+		Short:             "Switch to the settings of a config file context",
+		Long:              "Switch the shell to a context of the config file; unlike config use-context, the file is not changed",
+		Args:              bindArgs("name"),
+		PersistentPreRunE: skipClient,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := client.LoadConfig(configPath, name)
+			if err != nil {
+				return err
+			}
+			if err := s.reconnect(*config); err != nil {
+				return err
+			}
+			contextName = name
+			return nil
+		},
+	}
+	useContextCmd.Flags().StringVar(&name, "name", "", "Context name")
+
+	setCmd := &cobra.Command{
+		Use:               "set --name NAME --value VALUE",
+		Short:             "Set a $variable",
+		Args:              bindArgs("name", "value"),
+		PersistentPreRunE: skipClient,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !shell.IsName(name) {
+				return fmt.Errorf("invalid variable name %q: use letters, digits and _", name)
+			}
+			s.vars[name] = value
+			return nil
+		},
+	}
+	setCmd.Flags().StringVar(&name, "name", "", "Variable name")
+	setCmd.Flags().StringVar(&value, "value", "", "Variable value")
+
+	varsCmd := &cobra.Command{
+		Use:               "vars",
+		Short:             "List the $variables",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: skipClient,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range sortedKeys(s.vars) {
+				fmt.Printf("%s=%s\n", name, s.vars[name])
+			}
+			return nil
+		},
+	}
+
+	historyCmd := &cobra.Command{
+		Use:               "history",
+		Short:             "List the lines entered",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: skipClient,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for i, line := range s.editor.History() {
+				fmt.Printf("%5d  %s\n", i+1, line)
+			}
+			return nil
+		},
+	}
+
+	exitCmd := &cobra.Command{
+		Use:               "exit",
+		Aliases:           []string{"quit"},
+		Short:             "Leave the shell",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: skipClient,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s.done = true
+			return nil
+		},
+	}
+
+	return []*cobra.Command{modeCmd, useContextCmd, setCmd, varsCmd, historyCmd, exitCmd}
+}
+
+// remember keeps the ID of a created resource as a $last_ variable
+func (s *shellSession) remember(v any) {
+	switch v := v.(type) {
+	case *model.User:
+		s.vars["last_user"] = v.ID
+	case *model.Product:
+		s.vars["last_product"] = v.ID
+	case *model.Order:
+		s.vars["last_order"] = v.ID
+	case *model.Tenant:
+		s.vars["last_tenant"] = v.ID
+	}
+}
+
+func (s *shellSession) lookup(name string) (string, bool) {
+	value, ok := s.vars[name]
+	return value, ok
+}
+
+// complete returns the subcommands, flags or $variables starting with the
+// word before the cursor
+func (s *shellSession) complete(head string) []string {
+	words := strings.Fields(head)
+	word := ""
+	if head != "" && !unicode.IsSpace(rune(head[len(head)-1])) {
+		word, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	var candidates []string
+	switch {
+	case strings.HasPrefix(word, "$"):
+		for _, name := range sortedKeys(s.vars) {
+			candidates = append(candidates, "$"+name)
+		}
+	case strings.HasPrefix(word, "-"):
+		cmd, _, err := s.newRootCmd().Find(words)
+		if err != nil {
+			return nil
+		}
+		for _, flags := range []*pflag.FlagSet{cmd.LocalFlags(), cmd.InheritedFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if !f.Hidden {
+					candidates = append(candidates, "--"+f.Name)
+				}
+			})
+		}
+	default:
+		cmd, _, err := s.newRootCmd().Find(words)
+		if err != nil {
+			return nil
+		}
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() && sub.Name() != "shell" {
+				candidates = append(candidates, sub.Name())
+			}
+		}
+		if cmd == cmd.Root() {
+			candidates = append(candidates, "help")
+		}
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func (s *shellSession) prompt() string {
+	if contextName != "" {
+		return fmt.Sprintf("%s/%s> ", contextName, s.config.Mode)
+	}
+	return s.config.Mode + "> "
+}
+
+// address describes the servers of the session's mode
+func (s *shellSession) address() string {
+	switch s.config.Mode {
+	case "grpc":
+		return s.config.GRPCAddr
+	case "rest":
+		return s.config.RESTAddr
+	default:
+		return s.config.GRPCAddr + ", " + s.config.RESTAddr
+	}
+}
+
+func (s *shellSession) loadHistory() {
+	data, err := os.ReadFile(s.historyPath)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		// Files written before secrets were left out may still hold some
+		if !shell.HasSecret(line) {
+			s.editor.AddHistory(line)
+		}
+	}
+}
+
+// saveHistory keeps the latest lines in a file only the user can read
+func (s *shellSession) saveHistory() {
+	if s.historyPath == "" {
+		return
+	}
+	history := s.editor.History()
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	if err := os.MkdirAll(filepath.Dir(s.historyPath), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(s.historyPath, []byte(strings.Join(history, "\n")+"\n"), 0o600)
+}
+
+// skipClient is the pre-run of commands working without a client
+func skipClient(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -19,6 +19,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -26,39 +28,207 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	authpb "go-grpc-rest-demo/api/gen/go/auth/v1"
+	categorypb "go-grpc-rest-demo/api/gen/go/category/v1"
+	orderpb "go-grpc-rest-demo/api/gen/go/order/v1"
 	productpb "go-grpc-rest-demo/api/gen/go/product/v1"
+	tenantpb "go-grpc-rest-demo/api/gen/go/tenant/v1"
 	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
 	_ "go-grpc-rest-demo/docs" // Import docs for swagger
+	"go-grpc-rest-demo/internal/server/auth"
 	grpcserver "go-grpc-rest-demo/internal/server/grpc"
+	"go-grpc-rest-demo/internal/server/idempotency"
+	"go-grpc-rest-demo/internal/server/identity"
+	"go-grpc-rest-demo/internal/server/notify"
 	"go-grpc-rest-demo/internal/server/rest"
 	"go-grpc-rest-demo/internal/server/service"
+	"go-grpc-rest-demo/internal/server/verification"
 )
 
 const (
 	restPort = ":8080"
 	grpcPort = ":9090"
+
+	// reservationReapInterval is how often expired stock reservations are released
+	reservationReapInterval = 30 * time.Second
+
+	// shutdownTimeout is how long the servers may take to finish requests in
+	// flight once asked to stop
+	shutdownTimeout = 5 * time.Second
+
+	// defaultVerificationTokenTTL is how long an email verification token is valid
+	defaultVerificationTokenTTL = 24 * time.Hour
+
+	// defaultAccessTokenTTL and defaultRefreshTokenTTL are how long login tokens are valid
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 7 * 24 * time.Hour
 )
 
+// loadIdempotencyTTL reads how long idempotent responses are kept from
+// IDEMPOTENCY_TTL, such as "1h" or "30m"
+func loadIdempotencyTTL() time.Duration {
+	return loadDuration("IDEMPOTENCY_TTL", idempotency.DefaultTTL)
+}
+
+// loadUserPolicy adjusts the default username and email policy from
+// USERNAME_MIN_LENGTH, USERNAME_MAX_LENGTH, USERNAME_ASCII_ONLY and the
+// comma-separated RESERVED_USERNAMES and ALLOWED_EMAIL_DOMAINS
+func loadUserPolicy() *identity.Policy {
+	policy := identity.DefaultPolicy()
+	for name, target := range map[string]*int{
+		"USERNAME_MIN_LENGTH": &policy.UsernameMinLength,
+		"USERNAME_MAX_LENGTH": &policy.UsernameMaxLength,
+	} {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				log.Printf("Invalid %s %q, using %d", name, value, *target)
+				continue
+			}
+			*target = n
+		}
+	}
+	if value := os.Getenv("USERNAME_ASCII_ONLY"); value != "" {
+		asciiOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Invalid USERNAME_ASCII_ONLY %q, using %t", value, policy.ASCIIOnly)
+		} else {
+			policy.ASCIIOnly = asciiOnly
+		}
+	}
+	if value, ok := os.LookupEnv("RESERVED_USERNAMES"); ok {
+		policy.ReservedUsernames = splitList(value)
+	}
+	policy.AllowedEmailDomains = splitList(os.Getenv("ALLOWED_EMAIL_DOMAINS"))
+	return policy
+}
+
+// loadDuration reads a positive duration such as "1h" or "30m" from the
+// environment variable name, falling back to def
+func loadDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %s", name, value, def)
+		return def
+	}
+	return d
+}
+
+// loadEmailVerification reads the email verification settings. It returns
+// false unless EMAIL_VERIFICATION is true. Messages go to NOTIFY_SINK ("log"
+// or "file:<path>"); tokens are signed with VERIFICATION_SECRET, expire after
+// VERIFICATION_TOKEN_TTL and may be resent every VERIFICATION_RESEND_INTERVAL.
+func loadEmailVerification() (service.EmailVerification, bool) {
+	value := os.Getenv("EMAIL_VERIFICATION")
+	if value == "" {
+		return service.EmailVerification{}, false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid EMAIL_VERIFICATION %q, verification disabled", value)
+		return service.EmailVerification{}, false
+	}
+	if !enabled {
+		return service.EmailVerification{}, false
+	}
+
+	sender, err := notify.NewSender(os.Getenv("NOTIFY_SINK"))
+	if err != nil {
+		log.Printf("%v, using log", err)
+		sender = notify.LogSender{}
+	}
+	secret := os.Getenv("VERIFICATION_SECRET")
+	if secret == "" {
+		log.Println("VERIFICATION_SECRET is not set; verification tokens will not survive a restart")
+	}
+	return service.EmailVerification{
+		Sender:         sender,
+		Signer:         verification.NewSigner([]byte(secret), loadDuration("VERIFICATION_TOKEN_TTL", defaultVerificationTokenTTL)),
+		ResendInterval: loadDuration("VERIFICATION_RESEND_INTERVAL", time.Minute),
+	}, true
+}
+
+// loadPasswordConfig adjusts the default password policy and login lockout
+// from PASSWORD_MIN_LENGTH, PASSWORD_MIN_CHAR_CLASSES,
+// LOGIN_MAX_FAILED_ATTEMPTS and LOGIN_LOCKOUT_DURATION
+func loadPasswordConfig() service.PasswordConfig {
+	config := service.DefaultPasswordConfig()
+	config.LockoutDuration = loadDuration("LOGIN_LOCKOUT_DURATION", config.LockoutDuration)
+	for name, target := range map[string]*int{
+		"PASSWORD_MIN_LENGTH":       &config.Policy.MinLength,
+		"PASSWORD_MIN_CHAR_CLASSES": &config.Policy.MinCharClasses,
+		"LOGIN_MAX_FAILED_ATTEMPTS": &config.MaxFailedAttempts,
+	} {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				log.Printf("Invalid %s %q, using %d", name, value, *target)
+				continue
+			}
+			*target = n
+		}
+	}
+	return config
+}
+
+// loadTokenSigner creates the access token signer from AUTH_TOKEN_SECRET and
+// AUTH_ACCESS_TOKEN_TTL
+func loadTokenSigner() *auth.TokenSigner {
+	secret := os.Getenv("AUTH_TOKEN_SECRET")
+	if secret == "" {
+		log.Println("AUTH_TOKEN_SECRET is not set; access tokens will not survive a restart")
+	}
+	return auth.NewTokenSigner([]byte(secret), loadDuration("AUTH_ACCESS_TOKEN_TTL", defaultAccessTokenTTL))
+}
+
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func main() {
-	userService := service.NewUserService()
-	productService := service.NewProductService()
+	userService := service.NewUserServiceWithPolicy(loadUserPolicy())
+	if config, ok := loadEmailVerification(); ok {
+		userService.EnableEmailVerification(config)
+	}
+	userService.ConfigurePasswords(loadPasswordConfig())
+	authService := service.NewAuthService(userService, loadTokenSigner(), loadDuration("AUTH_REFRESH_TOKEN_TTL", defaultRefreshTokenTTL))
+	categoryService := service.NewCategoryService()
+	productService := service.NewProductService(categoryService)
+	orderService := service.NewOrderService(userService, productService)
+	tenantService := service.NewTenantService(userService, productService, orderService)
+	ttl := loadIdempotencyTTL()
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	var wg sync.WaitGroup
-	wg.Add(2)
+	wg.Add(3)
 
 	go func() {
 		defer wg.Done()
-		if err := runREST(ctx, userService, productService); err != nil {
+		productService.RunReservationReaper(ctx, reservationReapInterval)
+	}()
+
+	go func() {
+		defer wg.Done()
+		if err := runREST(ctx, userService, productService, orderService, categoryService, authService, tenantService, ttl); err != nil {
 			log.Printf("REST server error: %v", err)
 		}
 	}()
 
 	go func() {
 		defer wg.Done()
-		if err := runGRPC(ctx, userService, productService); err != nil {
+		if err := runGRPC(ctx, userService, productService, orderService, categoryService, authService, tenantService, ttl); err != nil {
 			log.Printf("gRPC server error: %v", err)
 		}
 	}()
@@ -66,14 +236,17 @@ func main() {
 	log.Println("Servers started. REST: :8080, gRPC: :9090")
 	<-ctx.Done()
 	log.Println("Shutting down...")
+	// Watches never end on their own, so end them for the servers to stop
+	userService.StopWatches()
+	productService.StopWatches()
 	wg.Wait()
 	log.Println("Shutdown complete")
 }
 
-func runREST(ctx context.Context, userService *service.UserService, productService *service.ProductService) error {
+func runREST(ctx context.Context, userService *service.UserService, productService *service.ProductService, orderService *service.OrderService, categoryService *service.CategoryService, authService *service.AuthService, tenantService *service.TenantService, idempotencyTTL time.Duration) error {
 	srv := &http.Server{
 		Addr:    restPort,
-		Handler: rest.SetupRouter(userService, productService),
+		Handler: rest.SetupRouter(userService, productService, orderService, categoryService, authService, tenantService, idempotencyTTL),
 	}
 
 	go func() {
@@ -83,15 +256,25 @@ func runREST(ctx context.Context, userService *service.UserService, productServi
 	}()
 
 	<-ctx.Done()
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 	return srv.Shutdown(shutdownCtx)
 }
 
-func runGRPC(ctx context.Context, userService *service.UserService, productService *service.ProductService) error {
-	grpcServer := grpc.NewServer()
+func runGRPC(ctx context.Context, userService *service.UserService, productService *service.ProductService, orderService *service.OrderService, categoryService *service.CategoryService, authService *service.AuthService, tenantService *service.TenantService, idempotencyTTL time.Duration) error {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcserver.TenantUnaryInterceptor(tenantService, authService),
+			grpcserver.IdempotencyInterceptor(idempotencyTTL),
+		),
+		grpc.StreamInterceptor(grpcserver.TenantStreamInterceptor(tenantService, authService)),
+	)
 	userpb.RegisterUserServiceServer(grpcServer, grpcserver.NewUserServer(userService))
 	productpb.RegisterProductServiceServer(grpcServer, grpcserver.NewProductServer(productService))
+	orderpb.RegisterOrderServiceServer(grpcServer, grpcserver.NewOrderServer(orderService))
+	categorypb.RegisterCategoryServiceServer(grpcServer, grpcserver.NewCategoryServer(categoryService, productService))
+	authpb.RegisterAuthServiceServer(grpcServer, grpcserver.NewAuthServer(authService))
+	tenantpb.RegisterTenantServiceServer(grpcServer, grpcserver.NewTenantServer(tenantService))
 	reflection.Register(grpcServer)
 
 	lis, err := net.Listen("tcp", grpcPort)
@@ -106,6 +289,16 @@ func runGRPC(ctx context.Context, userService *service.UserService, productServi
 	}()
 
 	<-ctx.Done()
-	grpcServer.GracefulStop()
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		log.Println("gRPC graceful stop timed out, closing open connections")
+		grpcServer.Stop()
+	}
 	return nil
 }
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"io"
+
+	"go-grpc-rest-demo/internal/server/model"
+)
+
+// Page is one page of a list or search
+type Page[T any] struct {
+	Items      []T   `json:"items"`
+	TotalCount int32 `json:"total_count"`
+	Page       int32 `json:"page"`
+	PageSize   int32 `json:"page_size"`
+}
+
+func newPage[T any](items []T, totalCount, page, pageSize int32, err error) (*Page[T], error) {
+	if err != nil {
+		return nil, err
+	}
+	return &Page[T]{Items: items, TotalCount: totalCount, Page: page, PageSize: pageSize}, nil
+}
+
+// UserAPI manages users over the configured transport
+type UserAPI struct {
+	t transport
+}
+
+func (a *UserAPI) Create(ctx context.Context, username, email, fullName string) (*model.User, error) {
+	return a.t.CreateUser(ctx, username, email, fullName)
+}
+
+func (a *UserAPI) Get(ctx context.Context, id string) (*model.User, error) {
+	return a.t.GetUser(ctx, id)
+}
+
+// Update changes the fields that are not nil
+func (a *UserAPI) Update(ctx context.Context, id string, username, email, fullName *string, isActive *bool) (*model.User, error) {
+	return a.t.UpdateUser(ctx, id, username, email, fullName, isActive)
+}
+
+func (a *UserAPI) Delete(ctx context.Context, id string) error {
+	return a.t.DeleteUser(ctx, id)
+}
+
+func (a *UserAPI) List(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) (*Page[model.User], error) {
+	return newPage(a.t.ListUsers(ctx, page, pageSize, orderBy, filter, timeRange))
+}
+
+func (a *UserAPI) CheckPolicy(ctx context.Context, repair bool) (*model.UserPolicyReport, error) {
+	return a.t.CheckUserPolicy(ctx, repair)
+}
+
+func (a *UserAPI) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	return a.t.VerifyEmail(ctx, token)
+}
+
+func (a *UserAPI) ResendVerification(ctx context.Context, id string) error {
+	return a.t.ResendVerification(ctx, id)
+}
+
+func (a *UserAPI) SetPassword(ctx context.Context, id, password, setupToken string) error {
+	return a.t.SetPassword(ctx, id, password, setupToken)
+}
+
+func (a *UserAPI) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	return a.t.ChangePassword(ctx, id, currentPassword, newPassword)
+}
+
+// Export writes the encoded stream of the matching users to w
+func (a *UserAPI) Export(ctx context.Context, format, orderBy string, filter *string, timeRange model.TimeRange, w io.Writer) (int64, error) {
+	return a.t.ExportUsers(ctx, format, orderBy, filter, timeRange, w)
+}
+
+// ProductAPI manages products over the configured transport
+type ProductAPI struct {
+	t transport
+}
+
+func (a *ProductAPI) Create(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*model.Product, error) {
+	return a.t.CreateProduct(ctx, name, description, category, price, quantity)
+}
+
+func (a *ProductAPI) Get(ctx context.Context, id string) (*model.Product, error) {
+	return a.t.GetProduct(ctx, id)
+}
+
+// Update changes the fields that are not nil
+func (a *ProductAPI) Update(ctx context.Context, id string, name, description, category *string, price *model.Money, quantity *int32) (*model.Product, error) {
+	return a.t.UpdateProduct(ctx, id, name, description, category, price, quantity)
+}
+
+func (a *ProductAPI) Delete(ctx context.Context, id string) error {
+	return a.t.DeleteProduct(ctx, id)
+}
+
+func (a *ProductAPI) Search(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) (*Page[model.Product], error) {
+	return newPage(a.t.SearchProducts(ctx, query, category, filter, minPrice, maxPrice, timeRange, orderBy, page, pageSize))
+}
+
+func (a *ProductAPI) Import(ctx context.Context, format string, data io.Reader) (*model.ImportProductsSummary, error) {
+	return a.t.ImportProducts(ctx, format, data)
+}
+
+// Export writes the encoded stream of the matching products to w
+func (a *ProductAPI) Export(ctx context.Context, format string, query, category, categoryID, filter *string, includeDescendants bool, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, w io.Writer) (int64, error) {
+	return a.t.ExportProducts(ctx, format, query, category, categoryID, filter, includeDescendants, minPrice, maxPrice, timeRange, orderBy, w)
+}
+
+// OrderAPI manages orders over the configured transport
+type OrderAPI struct {
+	t transport
+}
+
+func (a *OrderAPI) Create(ctx context.Context, userID string, items []model.CreateOrderItem) (*model.Order, error) {
+	return a.t.CreateOrder(ctx, userID, items)
+}
+
+func (a *OrderAPI) Get(ctx context.Context, id string) (*model.Order, error) {
+	return a.t.GetOrder(ctx, id)
+}
+
+func (a *OrderAPI) List(ctx context.Context, userID string, page, pageSize int32) (*Page[model.Order], error) {
+	return newPage(a.t.ListOrders(ctx, userID, page, pageSize))
+}
+
+func (a *OrderAPI) Cancel(ctx context.Context, id string) (*model.Order, error) {
+	return a.t.CancelOrder(ctx, id)
+}
+
+// AuthAPI logs users in over the configured transport
+type AuthAPI struct {
+	t transport
+}
+
+func (a *AuthAPI) Login(ctx context.Context, username, password string) (*model.AuthTokens, error) {
+	return a.t.Login(ctx, username, password)
+}
+
+func (a *AuthAPI) Refresh(ctx context.Context, refreshToken string) (*model.AuthTokens, error) {
+	return a.t.RefreshToken(ctx, refreshToken)
+}
+
+// TenantAPI manages tenants over the configured transport
+type TenantAPI struct {
+	t transport
+}
+
+func (a *TenantAPI) Create(ctx context.Context, id, displayName string) (*model.Tenant, error) {
+	return a.t.CreateTenant(ctx, id, displayName)
+}
+
+func (a *TenantAPI) Get(ctx context.Context, id string) (*model.Tenant, error) {
+	return a.t.GetTenant(ctx, id)
+}
+
+func (a *TenantAPI) List(ctx context.Context) ([]model.Tenant, error) {
+	return a.t.ListTenants(ctx)
+}
+
+func (a *TenantAPI) Delete(ctx context.Context, id string) error {
+	return a.t.DeleteTenant(ctx, id)
+}
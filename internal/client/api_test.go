@@ -0,0 +1,270 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	orderpb "go-grpc-rest-demo/api/gen/go/order/v1"
+	productpb "go-grpc-rest-demo/api/gen/go/product/v1"
+	tenantpb "go-grpc-rest-demo/api/gen/go/tenant/v1"
+	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
+	"go-grpc-rest-demo/internal/client/output"
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/errors"
+	grpcserver "go-grpc-rest-demo/internal/server/grpc"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/rest"
+	"go-grpc-rest-demo/internal/server/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+)
+
+// APITestSuite runs the client API against both transports of one server
+type APITestSuite struct {
+	suite.Suite
+	grpcAddr, restAddr string
+}
+
+func (suite *APITestSuite) SetupTest() {
+	userService := service.NewUserService()
+	authService := service.NewAuthService(userService, auth.NewTokenSigner([]byte("secret"), time.Minute), time.Hour)
+	categoryService := service.NewCategoryService()
+	productService := service.NewProductService(categoryService)
+	orderService := service.NewOrderService(userService, productService)
+	tenantService := service.NewTenantService(userService, productService, orderService)
+
+	gin.SetMode(gin.TestMode)
+	restServer := httptest.NewServer(rest.SetupRouter(userService, productService, orderService, categoryService, authService, tenantService, time.Hour))
+	suite.T().Cleanup(restServer.Close)
+	suite.restAddr = restServer.URL
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcserver.TenantUnaryInterceptor(tenantService, authService),
+			grpcserver.IdempotencyInterceptor(time.Hour),
+		),
+	)
+	userpb.RegisterUserServiceServer(grpcServer, grpcserver.NewUserServer(userService))
+	productpb.RegisterProductServiceServer(grpcServer, grpcserver.NewProductServer(productService))
+	orderpb.RegisterOrderServiceServer(grpcServer, grpcserver.NewOrderServer(orderService))
+	tenantpb.RegisterTenantServiceServer(grpcServer, grpcserver.NewTenantServer(tenantService))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	go func() { _ = grpcServer.Serve(lis) }()
+	suite.T().Cleanup(grpcServer.Stop)
+	suite.grpcAddr = lis.Addr().String()
+}
+
+func (suite *APITestSuite) newClient(mode string) Client {
+	config := DefaultConfig()
+	config.Mode = mode
+	config.GRPCAddr = suite.grpcAddr
+	config.RESTAddr = suite.restAddr
+	config.Retry.MaxAttempts = 1
+	c, err := NewClient(config)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func (suite *APITestSuite) TestTransportsReturnSameTypes() {
+	grpcClient, restClient := suite.newClient("grpc"), suite.newClient("rest")
+	ctx := context.Background()
+
+	created, err := grpcClient.Users().Create(ctx, "alice", "alice@example.com", "Alice")
+	suite.Require().NoError(err)
+
+	viaGRPC, err := grpcClient.Users().Get(ctx, created.ID)
+	suite.Require().NoError(err)
+	viaREST, err := restClient.Users().Get(ctx, created.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), viaREST, viaGRPC)
+	assert.Equal(suite.T(), "alice", viaGRPC.Username)
+
+	product, err := restClient.Products().Create(ctx, "Hammer", "A hammer", "Tools", model.Money{CurrencyCode: "USD", Units: 12, Nanos: 500_000_000}, 5)
+	suite.Require().NoError(err)
+	order, err := grpcClient.Orders().Create(ctx, created.ID, []model.CreateOrderItem{{ProductID: product.ID, Quantity: 2}})
+	suite.Require().NoError(err)
+
+	for _, c := range []Client{grpcClient, restClient} {
+		orders, err := c.Orders().List(ctx, created.ID, 1, 10)
+		suite.Require().NoError(err)
+		assert.Equal(suite.T(), int32(1), orders.TotalCount)
+		assert.Equal(suite.T(), order.TotalPriceMoney, orders.Items[0].TotalPriceMoney)
+		assert.Equal(suite.T(), model.OrderStatusPlaced, orders.Items[0].Status)
+
+		products, err := c.Products().Search(ctx, nil, nil, nil, nil, nil, model.TimeRange{}, "", 1, 10)
+		suite.Require().NoError(err)
+		assert.Equal(suite.T(), product.PriceMoney, products.Items[0].PriceMoney)
+	}
+}
+
+func (suite *APITestSuite) TestTransportsReturnSameErrors() {
+	ctx := context.Background()
+	for _, mode := range []string{"grpc", "rest"} {
+		c := suite.newClient(mode)
+
+		_, err := c.Users().Get(ctx, "missing")
+		appErr, ok := err.(*errors.AppError)
+		suite.Require().True(ok, "%s: %T", mode, err)
+		assert.Equal(suite.T(), errors.ErrCodeNotFound, appErr.Code, mode)
+
+		_, err = c.Users().Create(ctx, "", "not-an-email", "")
+		appErr, ok = err.(*errors.AppError)
+		suite.Require().True(ok, "%s: %T", mode, err)
+		assert.Equal(suite.T(), errors.ErrCodeValidationFailed, appErr.Code, mode)
+		assert.Len(suite.T(), appErr.Violations, 3, mode)
+
+		err = c.Tenants().Delete(ctx, "default")
+		appErr, ok = err.(*errors.AppError)
+		suite.Require().True(ok, "%s: %T", mode, err)
+		assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, appErr.Code, mode)
+	}
+}
+
+func (suite *APITestSuite) TestUpdateAndDeleteProduct() {
+	ctx := context.Background()
+	for _, mode := range []string{"grpc", "rest"} {
+		c := suite.newClient(mode)
+		product, err := c.Products().Create(ctx, "Saw", "A saw", "Tools", model.Money{CurrencyCode: "USD", Units: 20}, 4)
+		suite.Require().NoError(err)
+
+		name, quantity := "Hand saw", int32(9)
+		price := model.Money{CurrencyCode: "USD", Units: 25, Nanos: 500_000_000}
+		updated, err := c.Products().Update(ctx, product.ID, &name, nil, nil, &price, &quantity)
+		suite.Require().NoError(err, mode)
+		assert.Equal(suite.T(), name, updated.Name, mode)
+		assert.Equal(suite.T(), "A saw", updated.Description, mode)
+		assert.Equal(suite.T(), price, updated.PriceMoney, mode)
+		assert.Equal(suite.T(), quantity, updated.Quantity, mode)
+
+		suite.Require().NoError(c.Products().Delete(ctx, product.ID), mode)
+		_, err = c.Products().Get(ctx, product.ID)
+		assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code, mode)
+	}
+}
+
+func (suite *APITestSuite) TestOutputIsTheSameForBothTransports() {
+	grpcClient, restClient := suite.newClient("grpc"), suite.newClient("rest")
+	ctx := context.Background()
+
+	user, err := grpcClient.Users().Create(ctx, "carol", "carol@example.com", "Carol")
+	suite.Require().NoError(err)
+	product, err := grpcClient.Products().Create(ctx, "Drill", "A drill", "Tools", model.Money{CurrencyCode: "EUR", Units: 89, Nanos: 990_000_000}, 3)
+	suite.Require().NoError(err)
+	order, err := grpcClient.Orders().Create(ctx, user.ID, []model.CreateOrderItem{{ProductID: product.ID, Quantity: 1}})
+	suite.Require().NoError(err)
+
+	results := func(c Client) []any {
+		users, err := c.Users().List(ctx, 1, 10, "", nil, model.TimeRange{})
+		suite.Require().NoError(err)
+		p, err := c.Products().Get(ctx, product.ID)
+		suite.Require().NoError(err)
+		o, err := c.Orders().Get(ctx, order.ID)
+		suite.Require().NoError(err)
+		return []any{output.List{Resource: "users", Items: users.Items, TotalCount: users.TotalCount, Page: users.Page, PageSize: users.PageSize}, p, o}
+	}
+	viaGRPC, viaREST := results(grpcClient), results(restClient)
+
+	for _, spec := range []string{"json", "yaml", "table", "wide", "csv", "go-template={{.}}", "jsonpath={.*}"} {
+		printer, err := output.NewPrinter(spec)
+		suite.Require().NoError(err)
+		for i := range viaGRPC {
+			var fromGRPC, fromREST bytes.Buffer
+			suite.Require().NoError(printer.Print(&fromGRPC, viaGRPC[i]), spec)
+			suite.Require().NoError(printer.Print(&fromREST, viaREST[i]), spec)
+			assert.Equal(suite.T(), fromGRPC.String(), fromREST.String(), spec)
+		}
+	}
+}
+
+func (suite *APITestSuite) TestTokenIsSentByBothTransports() {
+	for _, mode := range []string{"grpc", "rest"} {
+		config := DefaultConfig()
+		config.Mode = mode
+		config.GRPCAddr = suite.grpcAddr
+		config.RESTAddr = suite.restAddr
+		config.Token = "not-a-token"
+		c, err := NewClient(config)
+		suite.Require().NoError(err)
+
+		_, err = c.Users().List(context.Background(), 1, 10, "", nil, model.TimeRange{})
+		assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code, mode)
+		_ = c.Close()
+	}
+}
+
+func (suite *APITestSuite) TestCompareTransports() {
+	c := suite.newClient("both")
+	ctx := context.Background()
+	user, err := c.Users().Create(ctx, "dave", "dave@example.com", "Dave")
+	suite.Require().NoError(err)
+
+	report, err := c.Compare(ctx, "get user", func(ctx context.Context, c Client) (any, error) {
+		return c.Users().Get(ctx, user.ID)
+	})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), report.Differences)
+	assert.Equal(suite.T(), user.ID, report.GRPC.Value.(*model.User).ID)
+	assert.Positive(suite.T(), report.GRPC.Latency)
+	assert.Positive(suite.T(), report.REST.Latency)
+
+	report, err = c.Compare(ctx, "get user", func(ctx context.Context, c Client) (any, error) {
+		return c.Users().Get(ctx, "missing")
+	})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), report.Differences, "the same error is no difference")
+
+	// Drift is simulated by telling the transports apart through the
+	// deprecated methods, which only one of them supports
+	report, err = c.Compare(ctx, "get user", func(ctx context.Context, c Client) (any, error) {
+		got, err := c.Users().Get(ctx, user.ID)
+		if err == nil {
+			if _, restErr := c.GetUserREST(ctx, user.ID); restErr == nil {
+				got.Email = "other@example.com"
+				got.FullName = ""
+			}
+		}
+		return got, err
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []Difference{
+		{Path: "email", GRPC: `"dave@example.com"`, REST: `"other@example.com"`},
+		{Path: "full_name", GRPC: `"Dave"`, REST: `""`},
+	}, report.Differences)
+
+	report, err = c.Compare(ctx, "list users", func(ctx context.Context, c Client) (any, error) {
+		if _, restErr := c.GetUserREST(ctx, user.ID); restErr == nil {
+			return nil, errors.NewNotFoundError("user", user.ID)
+		}
+		return c.Users().List(ctx, 1, 10, "", nil, model.TimeRange{})
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(report.Differences, 1)
+	assert.Equal(suite.T(), "error", report.Differences[0].Path)
+	assert.Empty(suite.T(), report.Differences[0].GRPC)
+	assert.Contains(suite.T(), report.Differences[0].REST, "NOT_FOUND")
+
+	_, err = suite.newClient("grpc").Compare(ctx, "get user", func(ctx context.Context, c Client) (any, error) {
+		return nil, nil
+	})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *APITestSuite) TestUnsupportedMode() {
+	config := DefaultConfig()
+	config.Mode = "carrier-pigeon"
+	_, err := NewClient(config)
+	assert.EqualError(suite.T(), err, "unsupported client mode: carrier-pigeon")
+}
+
+func TestAPITestSuite(t *testing.T) {
+	suite.Run(t, new(APITestSuite))
+}
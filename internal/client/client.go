@@ -3,40 +3,160 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 
+	authpb "go-grpc-rest-demo/api/gen/go/auth/v1"
+	orderpb "go-grpc-rest-demo/api/gen/go/order/v1"
 	productpb "go-grpc-rest-demo/api/gen/go/product/v1"
+	tenantpb "go-grpc-rest-demo/api/gen/go/tenant/v1"
 	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
 	"go-grpc-rest-demo/internal/server/model"
 )
 
-// Client interface defines the methods for both gRPC and REST clients
+// Client talks to the server over the transport picked by Config.Mode. Its
+// APIs return the model types, and report server errors as *errors.AppError,
+// whichever transport carries the calls.
 type Client interface {
 	Close() error
 
-	// User methods - return different types based on client type
+	Users() *UserAPI
+	Products() *ProductAPI
+	Orders() *OrderAPI
+	Auth() *AuthAPI
+	Tenants() *TenantAPI
+
+	// Compare runs a read call over both transports of a client made in
+	// "both" mode and reports how the results differ
+	Compare(ctx context.Context, operation string, call func(ctx context.Context, c Client) (any, error)) (*ParityReport, error)
+
+	// The methods below predate the APIs and are kept for one release. The
+	// GRPC and REST variants return different types based on client type.
+
+	// User methods
+
+	// Deprecated: use Users().Create.
 	CreateUserGRPC(ctx context.Context, username, email, fullName string) (*userpb.User, error)
+	// Deprecated: use Users().Create.
 	CreateUserREST(ctx context.Context, username, email, fullName string) (*model.User, error)
 
+	// Deprecated: use Users().Get.
 	GetUserGRPC(ctx context.Context, id string) (*userpb.User, error)
+	// Deprecated: use Users().Get.
 	GetUserREST(ctx context.Context, id string) (*model.User, error)
 
+	// Deprecated: use Users().Update.
 	UpdateUserGRPC(ctx context.Context, id string, username, email, fullName *string, isActive *bool) (*userpb.User, error)
+	// Deprecated: use Users().Update.
 	UpdateUserREST(ctx context.Context, id string, username, email, fullName *string, isActive *bool) (*model.User, error)
 
+	// Deprecated: use Users().Delete.
 	DeleteUser(ctx context.Context, id string) error
 
-	ListUsersGRPC(ctx context.Context, page, pageSize int32, sortBy, filter *string) ([]*userpb.User, int32, int32, int32, error)
-	ListUsersREST(ctx context.Context, page, pageSize int32, sortBy, filter *string) ([]model.User, int32, int32, int32, error)
+	// Deprecated: use Users().List.
+	ListUsersGRPC(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) ([]*userpb.User, int32, int32, int32, error)
+	// Deprecated: use Users().List.
+	ListUsersREST(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) ([]model.User, int32, int32, int32, error)
+
+	// Deprecated: use Users().CheckPolicy.
+	CheckUserPolicyGRPC(ctx context.Context, repair bool) (*userpb.CheckUserPolicyResponse, error)
+	// Deprecated: use Users().CheckPolicy.
+	CheckUserPolicyREST(ctx context.Context, repair bool) (*model.UserPolicyReport, error)
+
+	// Deprecated: use Users().VerifyEmail.
+	VerifyEmailGRPC(ctx context.Context, token string) (*userpb.User, error)
+	// Deprecated: use Users().VerifyEmail.
+	VerifyEmailREST(ctx context.Context, token string) (*model.User, error)
+
+	// Deprecated: use Users().ResendVerification.
+	ResendVerification(ctx context.Context, id string) error
+
+	// Deprecated: use Users().SetPassword.
+	SetPassword(ctx context.Context, id, password, setupToken string) error
+	// Deprecated: use Users().ChangePassword.
+	ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error
+
+	// Auth methods
+
+	// Deprecated: use Auth().Login.
+	LoginGRPC(ctx context.Context, username, password string) (*authpb.AuthTokens, error)
+	// Deprecated: use Auth().Login.
+	LoginREST(ctx context.Context, username, password string) (*model.AuthTokens, error)
+
+	// Deprecated: use Auth().Refresh.
+	RefreshTokenGRPC(ctx context.Context, refreshToken string) (*authpb.AuthTokens, error)
+	// Deprecated: use Auth().Refresh.
+	RefreshTokenREST(ctx context.Context, refreshToken string) (*model.AuthTokens, error)
+
+	// Tenant methods
+
+	// Deprecated: use Tenants().Create.
+	CreateTenantGRPC(ctx context.Context, id, displayName string) (*tenantpb.Tenant, error)
+	// Deprecated: use Tenants().Create.
+	CreateTenantREST(ctx context.Context, id, displayName string) (*model.Tenant, error)
+
+	// Deprecated: use Tenants().Get.
+	GetTenantGRPC(ctx context.Context, id string) (*tenantpb.Tenant, error)
+	// Deprecated: use Tenants().Get.
+	GetTenantREST(ctx context.Context, id string) (*model.Tenant, error)
+
+	// Deprecated: use Tenants().List.
+	ListTenantsGRPC(ctx context.Context) ([]*tenantpb.Tenant, error)
+	// Deprecated: use Tenants().List.
+	ListTenantsREST(ctx context.Context) ([]model.Tenant, error)
+
+	// Deprecated: use Tenants().Delete.
+	DeleteTenant(ctx context.Context, id string) error
 
 	// Product methods
-	CreateProductGRPC(ctx context.Context, name, description, category string, price float64, quantity int32) (*productpb.Product, error)
-	CreateProductREST(ctx context.Context, name, description, category string, price float64, quantity int32) (*model.Product, error)
 
+	// Deprecated: use Products().Create.
+	CreateProductGRPC(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*productpb.Product, error)
+	// Deprecated: use Products().Create.
+	CreateProductREST(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*model.Product, error)
+
+	// Deprecated: use Products().Get.
 	GetProductGRPC(ctx context.Context, id string) (*productpb.Product, error)
+	// Deprecated: use Products().Get.
 	GetProductREST(ctx context.Context, id string) (*model.Product, error)
 
-	SearchProductsGRPC(ctx context.Context, query, category *string, minPrice, maxPrice *float64, page, pageSize int32) ([]*productpb.Product, int32, int32, int32, error)
-	SearchProductsREST(ctx context.Context, query, category *string, minPrice, maxPrice *float64, page, pageSize int32) ([]model.Product, int32, int32, int32, error)
+	// Deprecated: use Products().Search.
+	SearchProductsGRPC(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) ([]*productpb.Product, int32, int32, int32, error)
+	// Deprecated: use Products().Search.
+	SearchProductsREST(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) ([]model.Product, int32, int32, int32, error)
+
+	// Deprecated: use Products().Import.
+	ImportProductsGRPC(ctx context.Context, format string, data io.Reader) (*productpb.ImportProductsResponse, error)
+	// Deprecated: use Products().Import.
+	ImportProductsREST(ctx context.Context, format string, data io.Reader) (*model.ImportProductsSummary, error)
+
+	// Order methods
+
+	// Deprecated: use Orders().Create.
+	CreateOrderGRPC(ctx context.Context, userID string, items []model.CreateOrderItem) (*orderpb.Order, error)
+	// Deprecated: use Orders().Create.
+	CreateOrderREST(ctx context.Context, userID string, items []model.CreateOrderItem) (*model.Order, error)
+
+	// Deprecated: use Orders().Get.
+	GetOrderGRPC(ctx context.Context, id string) (*orderpb.Order, error)
+	// Deprecated: use Orders().Get.
+	GetOrderREST(ctx context.Context, id string) (*model.Order, error)
+
+	// Deprecated: use Orders().List.
+	ListOrdersGRPC(ctx context.Context, userID string, page, pageSize int32) ([]*orderpb.Order, int32, int32, int32, error)
+	// Deprecated: use Orders().List.
+	ListOrdersREST(ctx context.Context, userID string, page, pageSize int32) ([]model.Order, int32, int32, int32, error)
+
+	// Deprecated: use Orders().Cancel.
+	CancelOrderGRPC(ctx context.Context, id string) (*orderpb.Order, error)
+	// Deprecated: use Orders().Cancel.
+	CancelOrderREST(ctx context.Context, id string) (*model.Order, error)
+
+	// Export methods write the same encoded stream for both transports
+
+	// Deprecated: use Users().Export.
+	ExportUsers(ctx context.Context, format, orderBy string, filter *string, timeRange model.TimeRange, w io.Writer) (int64, error)
+	// Deprecated: use Products().Export.
+	ExportProducts(ctx context.Context, format string, query, category, categoryID, filter *string, includeDescendants bool, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, w io.Writer) (int64, error)
 }
 
 // UnifiedClient wraps both gRPC and REST clients
@@ -44,6 +164,9 @@ type UnifiedClient struct {
 	grpcClient *GRPCClient
 	restClient *RESTClient
 	config     *Config
+
+	// transport carries the API calls: gRPC in "grpc" and "both" modes
+	transport transport
 }
 
 // NewClient creates a new unified client based on configuration
@@ -52,28 +175,46 @@ func NewClient(config *Config) (Client, error) {
 	var restClient *RESTClient
 	var err error
 
-	// Always try to create both clients, but only require the one specified in mode
+	// "both" mode needs both clients to compare them
 	if config.Mode == "grpc" || config.Mode == "both" {
 		grpcClient, err = NewGRPCClient(config)
-		if err != nil && config.Mode == "grpc" {
+		if err != nil {
 			return nil, fmt.Errorf("failed to create gRPC client: %v", err)
 		}
 	}
 
 	if config.Mode == "rest" || config.Mode == "both" {
 		restClient, err = NewRESTClient(config)
-		if err != nil && config.Mode == "rest" {
+		if err != nil {
+			if grpcClient != nil {
+				_ = grpcClient.Close()
+			}
 			return nil, fmt.Errorf("failed to create REST client: %v", err)
 		}
 	}
 
-	return &UnifiedClient{
+	c := &UnifiedClient{
 		grpcClient: grpcClient,
 		restClient: restClient,
 		config:     config,
-	}, nil
+	}
+	switch {
+	case grpcClient != nil:
+		c.transport = grpcTransport{c: grpcClient}
+	case restClient != nil:
+		c.transport = restClient
+	default:
+		return nil, fmt.Errorf("unsupported client mode: %s", config.Mode)
+	}
+	return c, nil
 }
 
+func (c *UnifiedClient) Users() *UserAPI       { return &UserAPI{t: c.transport} }
+func (c *UnifiedClient) Products() *ProductAPI { return &ProductAPI{t: c.transport} }
+func (c *UnifiedClient) Orders() *OrderAPI     { return &OrderAPI{t: c.transport} }
+func (c *UnifiedClient) Auth() *AuthAPI        { return &AuthAPI{t: c.transport} }
+func (c *UnifiedClient) Tenants() *TenantAPI   { return &TenantAPI{t: c.transport} }
+
 func (c *UnifiedClient) Close() error {
 	var err error
 	if c.grpcClient != nil {
@@ -111,14 +252,63 @@ func (c *UnifiedClient) UpdateUserGRPC(ctx context.Context, id string, username,
 	return c.grpcClient.UpdateUser(ctx, id, username, email, fullName, isActive)
 }
 
-func (c *UnifiedClient) ListUsersGRPC(ctx context.Context, page, pageSize int32, sortBy, filter *string) ([]*userpb.User, int32, int32, int32, error) {
+func (c *UnifiedClient) ListUsersGRPC(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) ([]*userpb.User, int32, int32, int32, error) {
 	if c.grpcClient == nil {
 		return nil, 0, 0, 0, fmt.Errorf("gRPC client not available")
 	}
-	return c.grpcClient.ListUsers(ctx, page, pageSize, sortBy, filter)
+	return c.grpcClient.ListUsers(ctx, page, pageSize, orderBy, filter, timeRange)
+}
+
+func (c *UnifiedClient) CheckUserPolicyGRPC(ctx context.Context, repair bool) (*userpb.CheckUserPolicyResponse, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.CheckUserPolicy(ctx, repair)
+}
+
+func (c *UnifiedClient) VerifyEmailGRPC(ctx context.Context, token string) (*userpb.User, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.VerifyEmail(ctx, token)
+}
+
+func (c *UnifiedClient) LoginGRPC(ctx context.Context, username, password string) (*authpb.AuthTokens, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.Login(ctx, username, password)
+}
+
+func (c *UnifiedClient) RefreshTokenGRPC(ctx context.Context, refreshToken string) (*authpb.AuthTokens, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.RefreshToken(ctx, refreshToken)
+}
+
+func (c *UnifiedClient) CreateTenantGRPC(ctx context.Context, id, displayName string) (*tenantpb.Tenant, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.CreateTenant(ctx, id, displayName)
 }
 
-func (c *UnifiedClient) CreateProductGRPC(ctx context.Context, name, description, category string, price float64, quantity int32) (*productpb.Product, error) {
+func (c *UnifiedClient) GetTenantGRPC(ctx context.Context, id string) (*tenantpb.Tenant, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.GetTenant(ctx, id)
+}
+
+func (c *UnifiedClient) ListTenantsGRPC(ctx context.Context) ([]*tenantpb.Tenant, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.ListTenants(ctx)
+}
+
+func (c *UnifiedClient) CreateProductGRPC(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*productpb.Product, error) {
 	if c.grpcClient == nil {
 		return nil, fmt.Errorf("gRPC client not available")
 	}
@@ -132,11 +322,46 @@ func (c *UnifiedClient) GetProductGRPC(ctx context.Context, id string) (*product
 	return c.grpcClient.GetProduct(ctx, id)
 }
 
-func (c *UnifiedClient) SearchProductsGRPC(ctx context.Context, query, category *string, minPrice, maxPrice *float64, page, pageSize int32) ([]*productpb.Product, int32, int32, int32, error) {
+func (c *UnifiedClient) SearchProductsGRPC(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) ([]*productpb.Product, int32, int32, int32, error) {
+	if c.grpcClient == nil {
+		return nil, 0, 0, 0, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.SearchProducts(ctx, query, category, filter, minPrice, maxPrice, timeRange, orderBy, page, pageSize)
+}
+
+func (c *UnifiedClient) ImportProductsGRPC(ctx context.Context, format string, data io.Reader) (*productpb.ImportProductsResponse, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.ImportProducts(ctx, format, data)
+}
+
+func (c *UnifiedClient) CreateOrderGRPC(ctx context.Context, userID string, items []model.CreateOrderItem) (*orderpb.Order, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.CreateOrder(ctx, userID, items)
+}
+
+func (c *UnifiedClient) GetOrderGRPC(ctx context.Context, id string) (*orderpb.Order, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.GetOrder(ctx, id)
+}
+
+func (c *UnifiedClient) ListOrdersGRPC(ctx context.Context, userID string, page, pageSize int32) ([]*orderpb.Order, int32, int32, int32, error) {
 	if c.grpcClient == nil {
 		return nil, 0, 0, 0, fmt.Errorf("gRPC client not available")
 	}
-	return c.grpcClient.SearchProducts(ctx, query, category, minPrice, maxPrice, page, pageSize)
+	return c.grpcClient.ListOrders(ctx, userID, page, pageSize)
+}
+
+func (c *UnifiedClient) CancelOrderGRPC(ctx context.Context, id string) (*orderpb.Order, error) {
+	if c.grpcClient == nil {
+		return nil, fmt.Errorf("gRPC client not available")
+	}
+	return c.grpcClient.CancelOrder(ctx, id)
 }
 
 // REST methods
@@ -161,14 +386,63 @@ func (c *UnifiedClient) UpdateUserREST(ctx context.Context, id string, username,
 	return c.restClient.UpdateUser(ctx, id, username, email, fullName, isActive)
 }
 
-func (c *UnifiedClient) ListUsersREST(ctx context.Context, page, pageSize int32, sortBy, filter *string) ([]model.User, int32, int32, int32, error) {
+func (c *UnifiedClient) ListUsersREST(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) ([]model.User, int32, int32, int32, error) {
 	if c.restClient == nil {
 		return nil, 0, 0, 0, fmt.Errorf("REST client not available")
 	}
-	return c.restClient.ListUsers(ctx, page, pageSize, sortBy, filter)
+	return c.restClient.ListUsers(ctx, page, pageSize, orderBy, filter, timeRange)
 }
 
-func (c *UnifiedClient) CreateProductREST(ctx context.Context, name, description, category string, price float64, quantity int32) (*model.Product, error) {
+func (c *UnifiedClient) CheckUserPolicyREST(ctx context.Context, repair bool) (*model.UserPolicyReport, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.CheckUserPolicy(ctx, repair)
+}
+
+func (c *UnifiedClient) VerifyEmailREST(ctx context.Context, token string) (*model.User, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.VerifyEmail(ctx, token)
+}
+
+func (c *UnifiedClient) LoginREST(ctx context.Context, username, password string) (*model.AuthTokens, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.Login(ctx, username, password)
+}
+
+func (c *UnifiedClient) RefreshTokenREST(ctx context.Context, refreshToken string) (*model.AuthTokens, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.RefreshToken(ctx, refreshToken)
+}
+
+func (c *UnifiedClient) CreateTenantREST(ctx context.Context, id, displayName string) (*model.Tenant, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.CreateTenant(ctx, id, displayName)
+}
+
+func (c *UnifiedClient) GetTenantREST(ctx context.Context, id string) (*model.Tenant, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.GetTenant(ctx, id)
+}
+
+func (c *UnifiedClient) ListTenantsREST(ctx context.Context) ([]model.Tenant, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.ListTenants(ctx)
+}
+
+func (c *UnifiedClient) CreateProductREST(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*model.Product, error) {
 	if c.restClient == nil {
 		return nil, fmt.Errorf("REST client not available")
 	}
@@ -182,11 +456,46 @@ func (c *UnifiedClient) GetProductREST(ctx context.Context, id string) (*model.P
 	return c.restClient.GetProduct(ctx, id)
 }
 
-func (c *UnifiedClient) SearchProductsREST(ctx context.Context, query, category *string, minPrice, maxPrice *float64, page, pageSize int32) ([]model.Product, int32, int32, int32, error) {
+func (c *UnifiedClient) SearchProductsREST(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) ([]model.Product, int32, int32, int32, error) {
 	if c.restClient == nil {
 		return nil, 0, 0, 0, fmt.Errorf("REST client not available")
 	}
-	return c.restClient.SearchProducts(ctx, query, category, minPrice, maxPrice, page, pageSize)
+	return c.restClient.SearchProducts(ctx, query, category, filter, minPrice, maxPrice, timeRange, orderBy, page, pageSize)
+}
+
+func (c *UnifiedClient) ImportProductsREST(ctx context.Context, format string, data io.Reader) (*model.ImportProductsSummary, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.ImportProducts(ctx, format, data)
+}
+
+func (c *UnifiedClient) CreateOrderREST(ctx context.Context, userID string, items []model.CreateOrderItem) (*model.Order, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.CreateOrder(ctx, userID, items)
+}
+
+func (c *UnifiedClient) GetOrderREST(ctx context.Context, id string) (*model.Order, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.GetOrder(ctx, id)
+}
+
+func (c *UnifiedClient) ListOrdersREST(ctx context.Context, userID string, page, pageSize int32) ([]model.Order, int32, int32, int32, error) {
+	if c.restClient == nil {
+		return nil, 0, 0, 0, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.ListOrders(ctx, userID, page, pageSize)
+}
+
+func (c *UnifiedClient) CancelOrderREST(ctx context.Context, id string) (*model.Order, error) {
+	if c.restClient == nil {
+		return nil, fmt.Errorf("REST client not available")
+	}
+	return c.restClient.CancelOrder(ctx, id)
 }
 
 // Shared methods (work for both)
@@ -198,3 +507,57 @@ func (c *UnifiedClient) DeleteUser(ctx context.Context, id string) error {
 	}
 	return fmt.Errorf("no client available for mode: %s", c.config.Mode)
 }
+
+func (c *UnifiedClient) ResendVerification(ctx context.Context, id string) error {
+	if c.config.Mode == "grpc" && c.grpcClient != nil {
+		return c.grpcClient.ResendVerification(ctx, id)
+	} else if c.config.Mode == "rest" && c.restClient != nil {
+		return c.restClient.ResendVerification(ctx, id)
+	}
+	return fmt.Errorf("no client available for mode: %s", c.config.Mode)
+}
+
+func (c *UnifiedClient) SetPassword(ctx context.Context, id, password, setupToken string) error {
+	if c.config.Mode == "grpc" && c.grpcClient != nil {
+		return c.grpcClient.SetPassword(ctx, id, password, setupToken)
+	} else if c.config.Mode == "rest" && c.restClient != nil {
+		return c.restClient.SetPassword(ctx, id, password, setupToken)
+	}
+	return fmt.Errorf("no client available for mode: %s", c.config.Mode)
+}
+
+func (c *UnifiedClient) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	if c.config.Mode == "grpc" && c.grpcClient != nil {
+		return c.grpcClient.ChangePassword(ctx, id, currentPassword, newPassword)
+	} else if c.config.Mode == "rest" && c.restClient != nil {
+		return c.restClient.ChangePassword(ctx, id, currentPassword, newPassword)
+	}
+	return fmt.Errorf("no client available for mode: %s", c.config.Mode)
+}
+
+func (c *UnifiedClient) DeleteTenant(ctx context.Context, id string) error {
+	if c.config.Mode == "grpc" && c.grpcClient != nil {
+		return c.grpcClient.DeleteTenant(ctx, id)
+	} else if c.config.Mode == "rest" && c.restClient != nil {
+		return c.restClient.DeleteTenant(ctx, id)
+	}
+	return fmt.Errorf("no client available for mode: %s", c.config.Mode)
+}
+
+func (c *UnifiedClient) ExportUsers(ctx context.Context, format, orderBy string, filter *string, timeRange model.TimeRange, w io.Writer) (int64, error) {
+	if c.config.Mode == "grpc" && c.grpcClient != nil {
+		return c.grpcClient.ExportUsers(ctx, format, orderBy, filter, timeRange, w)
+	} else if c.config.Mode == "rest" && c.restClient != nil {
+		return c.restClient.ExportUsers(ctx, format, orderBy, filter, timeRange, w)
+	}
+	return 0, fmt.Errorf("no client available for mode: %s", c.config.Mode)
+}
+
+func (c *UnifiedClient) ExportProducts(ctx context.Context, format string, query, category, categoryID, filter *string, includeDescendants bool, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, w io.Writer) (int64, error) {
+	if c.config.Mode == "grpc" && c.grpcClient != nil {
+		return c.grpcClient.ExportProducts(ctx, format, query, category, categoryID, filter, includeDescendants, minPrice, maxPrice, timeRange, orderBy, w)
+	} else if c.config.Mode == "rest" && c.restClient != nil {
+		return c.restClient.ExportProducts(ctx, format, query, category, categoryID, filter, includeDescendants, minPrice, maxPrice, timeRange, orderBy, w)
+	}
+	return 0, fmt.Errorf("no client available for mode: %s", c.config.Mode)
+}
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"fmt"
+	"os"
 	"time"
 )
 
@@ -16,11 +18,41 @@ type Config struct {
 	// Timeout for requests
 	Timeout time.Duration
 
-	// Output format: "json" or "table"
+	// Output format: "json", "yaml", "table", "wide", "csv", or
+	// "go-template=..." and "jsonpath=..." with an expression
 	OutputFormat string
 
 	// Enable verbose logging
 	Verbose bool
+
+	// Tenant the requests act for; the server's default tenant when empty
+	Tenant string
+
+	// Token is an access token from "auth login", sent as a bearer token
+	Token string
+
+	// TLS secures the gRPC connection and https REST addresses
+	TLS TLSConfig
+
+	// Retry controls retries of calls failing with transient errors
+	Retry RetryPolicy
+}
+
+// TLSConfig holds the TLS settings of the client
+type TLSConfig struct {
+	// Enabled makes the gRPC connection use TLS; REST uses TLS for https
+	// addresses either way
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// CAFile is a PEM bundle to verify the server with instead of the
+	// system roots
+	CAFile string `yaml:"ca-file,omitempty"`
+
+	// ServerName overrides the name the server certificate is checked against
+	ServerName string `yaml:"server-name,omitempty"`
+
+	// InsecureSkipVerify accepts any server certificate; for local testing only
+	InsecureSkipVerify bool `yaml:"insecure-skip-verify,omitempty"`
 }
 
 // DefaultConfig returns default client configuration
@@ -32,5 +64,13 @@ func DefaultConfig() *Config {
 		Timeout:      30 * time.Second,
 		OutputFormat: "json",
 		Verbose:      false,
+		Retry:        DefaultRetryPolicy(),
+	}
+}
+
+// logf writes a diagnostic line to stderr when verbose output is enabled
+func (c *Config) logf(format string, args ...any) {
+	if c.Verbose {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
 	}
 }
@@ -0,0 +1,294 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix starts the environment variables overriding the config file
+const envPrefix = "GRPC_REST_DEMO_"
+
+// ConfigFile is the client configuration file. It holds named contexts, one
+// per server the client talks to, and the context used when none is named.
+type ConfigFile struct {
+	CurrentContext string              `yaml:"current-context,omitempty"`
+	Contexts       map[string]*Context `yaml:"contexts,omitempty"`
+}
+
+// Context holds the settings of one server; empty fields keep the defaults
+type Context struct {
+	GRPCAddr string `yaml:"grpc-addr,omitempty"`
+	RESTAddr string `yaml:"rest-addr,omitempty"`
+	Mode     string `yaml:"mode,omitempty"`
+	// Timeout is a duration such as "10s"
+	Timeout string     `yaml:"timeout,omitempty"`
+	Output  string     `yaml:"output,omitempty"`
+	Tenant  string     `yaml:"tenant,omitempty"`
+	Token   string     `yaml:"token,omitempty"`
+	TLS     *TLSConfig `yaml:"tls,omitempty"`
+}
+
+// ContextKeys are the settings "config set" accepts, named like the flags
+var ContextKeys = []string{
+	"grpc-addr", "rest-addr", "mode", "timeout", "output", "tenant", "token",
+	"tls", "tls-ca-file", "tls-server-name", "tls-insecure-skip-verify",
+}
+
+// ConfigPath returns path, or else $GRPC_REST_DEMO_CONFIG, or else
+// config.yaml in the user's config directory, such as
+// ~/.config/grpc-rest-demo/config.yaml
+func ConfigPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if path := os.Getenv(envPrefix + "CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the config directory: %v", err)
+	}
+	return filepath.Join(dir, "grpc-rest-demo", "config.yaml"), nil
+}
+
+// LoadConfigFile reads a config file; a missing file is an empty one
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	file := &ConfigFile{Contexts: map[string]*Context{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	if file.Contexts == nil {
+		file.Contexts = map[string]*Context{}
+	}
+	return file, nil
+}
+
+// Save writes the config file. It may hold tokens, so only the user can
+// read it.
+func (f *ConfigFile) Save(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+	return nil
+}
+
+// ContextNames returns the names of the contexts in order
+func (f *ConfigFile) ContextNames() []string {
+	names := make([]string, 0, len(f.Contexts))
+	for name := range f.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ContextName returns name, or else $GRPC_REST_DEMO_CONTEXT, or else the
+// current context, which is empty when the file has none
+func (f *ConfigFile) ContextName(name string) string {
+	if name != "" {
+		return name
+	}
+	if name := os.Getenv(envPrefix + "CONTEXT"); name != "" {
+		return name
+	}
+	return f.CurrentContext
+}
+
+// UseContext makes the named context the current one
+func (f *ConfigFile) UseContext(name string) error {
+	if _, ok := f.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+	f.CurrentContext = name
+	return nil
+}
+
+// Set changes one setting of the named context, creating the context if
+// needed. The first context created becomes the current one.
+func (f *ConfigFile) Set(name, key, value string) error {
+	if name == "" {
+		return fmt.Errorf("no context to set %s in; name one with --context", key)
+	}
+	ctx := f.Contexts[name]
+	if ctx == nil {
+		ctx = &Context{}
+	}
+
+	tls := func() *TLSConfig {
+		if ctx.TLS == nil {
+			ctx.TLS = &TLSConfig{}
+		}
+		return ctx.TLS
+	}
+	var err error
+	switch key {
+	case "grpc-addr":
+		ctx.GRPCAddr = value
+	case "rest-addr":
+		ctx.RESTAddr = value
+	case "mode":
+		ctx.Mode = value
+	case "timeout":
+		if _, err = parseTimeout(value); err == nil {
+			ctx.Timeout = value
+		}
+	case "output":
+		ctx.Output = value
+	case "tenant":
+		ctx.Tenant = value
+	case "token":
+		ctx.Token = value
+	case "tls":
+		tls().Enabled, err = parseBool(key, value)
+	case "tls-ca-file":
+		tls().CAFile = value
+	case "tls-server-name":
+		tls().ServerName = value
+	case "tls-insecure-skip-verify":
+		tls().InsecureSkipVerify, err = parseBool(key, value)
+	default:
+		return fmt.Errorf("unknown setting %q; settings are %v", key, ContextKeys)
+	}
+	if err != nil {
+		return err
+	}
+
+	f.Contexts[name] = ctx
+	if f.CurrentContext == "" {
+		f.CurrentContext = name
+	}
+	return nil
+}
+
+// LoadConfig returns the default configuration overridden by a context of
+// the config file at path and then by GRPC_REST_DEMO_* environment
+// variables. The context is picked by ContextName; without one, only the
+// environment applies.
+func LoadConfig(path, name string) (*Config, error) {
+	path, err := ConfigPath(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := DefaultConfig()
+	name = file.ContextName(name)
+	if name != "" {
+		ctx, ok := file.Contexts[name]
+		if !ok {
+			return nil, fmt.Errorf("context %q not found in %s", name, path)
+		}
+		if err := ctx.apply(config); err != nil {
+			return nil, fmt.Errorf("invalid context %q: %v", name, err)
+		}
+	}
+	if err := applyEnv(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// apply overrides config with the settings the context has
+func (ctx *Context) apply(config *Config) error {
+	for target, value := range map[*string]string{
+		&config.GRPCAddr:     ctx.GRPCAddr,
+		&config.RESTAddr:     ctx.RESTAddr,
+		&config.Mode:         ctx.Mode,
+		&config.OutputFormat: ctx.Output,
+		&config.Tenant:       ctx.Tenant,
+		&config.Token:        ctx.Token,
+	} {
+		if value != "" {
+			*target = value
+		}
+	}
+	if ctx.Timeout != "" {
+		timeout, err := parseTimeout(ctx.Timeout)
+		if err != nil {
+			return err
+		}
+		config.Timeout = timeout
+	}
+	if ctx.TLS != nil {
+		config.TLS = *ctx.TLS
+	}
+	return nil
+}
+
+// applyEnv overrides config with the GRPC_REST_DEMO_* variables that are set,
+// such as GRPC_REST_DEMO_GRPC_ADDR or GRPC_REST_DEMO_TLS_CA_FILE
+func applyEnv(config *Config) error {
+	for name, target := range map[string]*string{
+		"GRPC_ADDR":       &config.GRPCAddr,
+		"REST_ADDR":       &config.RESTAddr,
+		"MODE":            &config.Mode,
+		"OUTPUT":          &config.OutputFormat,
+		"TENANT":          &config.Tenant,
+		"TOKEN":           &config.Token,
+		"TLS_CA_FILE":     &config.TLS.CAFile,
+		"TLS_SERVER_NAME": &config.TLS.ServerName,
+	} {
+		if value := os.Getenv(envPrefix + name); value != "" {
+			*target = value
+		}
+	}
+	for name, target := range map[string]*bool{
+		"TLS":                      &config.TLS.Enabled,
+		"TLS_INSECURE_SKIP_VERIFY": &config.TLS.InsecureSkipVerify,
+	} {
+		if value := os.Getenv(envPrefix + name); value != "" {
+			enabled, err := parseBool(envPrefix+name, value)
+			if err != nil {
+				return err
+			}
+			*target = enabled
+		}
+	}
+	if value := os.Getenv(envPrefix + "TIMEOUT"); value != "" {
+		timeout, err := parseTimeout(value)
+		if err != nil {
+			return fmt.Errorf("invalid %sTIMEOUT: %v", envPrefix, err)
+		}
+		config.Timeout = timeout
+	}
+	return nil
+}
+
+func parseTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("timeout %q is not a positive duration such as 10s", value)
+	}
+	return timeout, nil
+}
+
+func parseBool(name, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, not %q", name, value)
+	}
+	return b, nil
+}
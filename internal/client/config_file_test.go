@@ -0,0 +1,126 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigFileTestSuite struct {
+	suite.Suite
+	path string
+}
+
+func (suite *ConfigFileTestSuite) SetupTest() {
+	suite.path = filepath.Join(suite.T().TempDir(), "grpc-rest-demo", "config.yaml")
+	for _, name := range []string{"CONFIG", "CONTEXT", "GRPC_ADDR", "MODE", "TIMEOUT", "TLS"} {
+		suite.T().Setenv(envPrefix+name, "")
+	}
+}
+
+// save sets context, key and value triples in order
+func (suite *ConfigFileTestSuite) save(settings ...[3]string) {
+	file, err := LoadConfigFile(suite.path)
+	suite.Require().NoError(err)
+	for _, setting := range settings {
+		suite.Require().NoError(file.Set(setting[0], setting[1], setting[2]))
+	}
+	suite.Require().NoError(file.Save(suite.path))
+}
+
+func (suite *ConfigFileTestSuite) TestSaveAndLoad() {
+	file, err := LoadConfigFile(suite.path)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), file.ContextNames())
+
+	suite.Require().NoError(file.Set("staging", "grpc-addr", "staging:9090"))
+	suite.Require().NoError(file.Set("staging", "tls", "true"))
+	suite.Require().NoError(file.Set("local", "mode", "rest"))
+	suite.Require().NoError(file.Save(suite.path))
+
+	info, err := os.Stat(suite.path)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), os.FileMode(0o600), info.Mode().Perm())
+
+	file, err = LoadConfigFile(suite.path)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []string{"local", "staging"}, file.ContextNames())
+	assert.Equal(suite.T(), "staging", file.CurrentContext, "the first context becomes current")
+	assert.Equal(suite.T(), &Context{GRPCAddr: "staging:9090", TLS: &TLSConfig{Enabled: true}}, file.Contexts["staging"])
+
+	suite.Require().NoError(file.UseContext("local"))
+	assert.Equal(suite.T(), "local", file.CurrentContext)
+	assert.Error(suite.T(), file.UseContext("missing"))
+}
+
+func (suite *ConfigFileTestSuite) TestSetRejectsInvalidValues() {
+	file, err := LoadConfigFile(suite.path)
+	suite.Require().NoError(err)
+
+	assert.Error(suite.T(), file.Set("", "mode", "rest"))
+	assert.Error(suite.T(), file.Set("local", "colour", "blue"))
+	assert.Error(suite.T(), file.Set("local", "timeout", "soon"))
+	assert.Error(suite.T(), file.Set("local", "tls", "maybe"))
+	assert.Empty(suite.T(), file.Contexts)
+}
+
+func (suite *ConfigFileTestSuite) TestPrecedence() {
+	suite.save(
+		[3]string{"staging", "grpc-addr", "staging:9090"},
+		[3]string{"staging", "timeout", "5s"},
+		[3]string{"ci", "mode", "rest"},
+	)
+
+	config, err := LoadConfig(suite.path, "")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "staging:9090", config.GRPCAddr)
+	assert.Equal(suite.T(), 5*time.Second, config.Timeout)
+	assert.Equal(suite.T(), DefaultConfig().RESTAddr, config.RESTAddr)
+	assert.Equal(suite.T(), "grpc", config.Mode)
+
+	config, err = LoadConfig(suite.path, "ci")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "rest", config.Mode)
+	assert.Equal(suite.T(), DefaultConfig().GRPCAddr, config.GRPCAddr)
+
+	suite.T().Setenv(envPrefix+"CONTEXT", "ci")
+	suite.T().Setenv(envPrefix+"GRPC_ADDR", "env:9090")
+	suite.T().Setenv(envPrefix+"TLS", "true")
+	config, err = LoadConfig(suite.path, "")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "rest", config.Mode)
+	assert.Equal(suite.T(), "env:9090", config.GRPCAddr)
+	assert.True(suite.T(), config.TLS.Enabled)
+
+	suite.T().Setenv(envPrefix+"CONFIG", suite.path)
+	config, err = LoadConfig("", "staging")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "env:9090", config.GRPCAddr)
+	assert.Equal(suite.T(), 5*time.Second, config.Timeout)
+}
+
+func (suite *ConfigFileTestSuite) TestLoadErrors() {
+	config, err := LoadConfig(suite.path, "")
+	suite.Require().NoError(err, "a missing file is an empty one")
+	assert.Equal(suite.T(), DefaultConfig(), config)
+
+	_, err = LoadConfig(suite.path, "missing")
+	assert.Error(suite.T(), err)
+
+	suite.T().Setenv(envPrefix+"TIMEOUT", "-1s")
+	_, err = LoadConfig(suite.path, "")
+	assert.Error(suite.T(), err)
+
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(suite.path), 0o700))
+	suite.Require().NoError(os.WriteFile(suite.path, []byte("contexts: [1"), 0o600))
+	_, err = LoadConfigFile(suite.path)
+	assert.Error(suite.T(), err)
+}
+
+func TestConfigFileTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigFileTestSuite))
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// authorizationHeader carries the access token of REST requests
+const authorizationHeader = "Authorization"
+
+// tlsConfig builds the TLS settings shared by both transports
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         c.TLS.ServerName,
+		InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+	}
+	if c.TLS.CAFile != "" {
+		pem, err := os.ReadFile(c.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.TLS.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// transportCredentials secures the gRPC connection when TLS is enabled
+func (c *Config) transportCredentials() (credentials.TransportCredentials, error) {
+	if !c.TLS.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	config, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// httpTransport applies the TLS settings to https REST addresses
+func (c *Config) httpTransport() (http.RoundTripper, error) {
+	config, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+// tokenCredentials sends the configured access token with every gRPC call
+type tokenCredentials struct {
+	config *Config
+}
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	if t.config.Token == "" {
+		return nil, nil
+	}
+	return map[string]string{"authorization": "Bearer " + t.config.Token}, nil
+}
+
+// RequireTransportSecurity is false so that tokens also reach local
+// servers without TLS
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The client API reports server errors as *errors.AppError whichever
+// transport carried the call, so callers branch on Code the same way for
+// both. Errors that never reached the server are returned unchanged.
+
+var codesFromGRPC = map[codes.Code]errors.ErrorCode{
+	codes.InvalidArgument:    errors.ErrCodeInvalidRequest,
+	codes.NotFound:           errors.ErrCodeNotFound,
+	codes.AlreadyExists:      errors.ErrCodeAlreadyExists,
+	codes.Unauthenticated:    errors.ErrCodeUnauthorized,
+	codes.PermissionDenied:   errors.ErrCodeForbidden,
+	codes.FailedPrecondition: errors.ErrCodeFailedPrecondition,
+	codes.ResourceExhausted:  errors.ErrCodeResourceExhausted,
+	codes.Unavailable:        errors.ErrCodeServiceDown,
+}
+
+var codesFromHTTP = map[int]errors.ErrorCode{
+	http.StatusBadRequest:         errors.ErrCodeInvalidRequest,
+	http.StatusNotFound:           errors.ErrCodeNotFound,
+	http.StatusConflict:           errors.ErrCodeAlreadyExists,
+	http.StatusUnauthorized:       errors.ErrCodeUnauthorized,
+	http.StatusForbidden:          errors.ErrCodeForbidden,
+	http.StatusTooManyRequests:    errors.ErrCodeResourceExhausted,
+	http.StatusServiceUnavailable: errors.ErrCodeServiceDown,
+}
+
+// errorFromGRPC converts a gRPC status error, including the field
+// violations of its google.rpc.BadRequest detail
+func errorFromGRPC(err error) error {
+	st, ok := status.FromError(err)
+	if err == nil || !ok {
+		return err
+	}
+
+	appErr := &errors.AppError{Message: st.Message(), GRPCCode: st.Code()}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.FieldViolations {
+				appErr.Violations = append(appErr.Violations, errors.FieldViolation{Field: v.Field, Description: v.Description})
+			}
+		}
+	}
+	appErr.Code = errorCode(codesFromGRPC[st.Code()], appErr.Violations)
+	return appErr
+}
+
+// errorFromHTTP converts an error response. The server names the error code
+// in the body; the status code is the fallback for bodies without one.
+func errorFromHTTP(statusCode int, body []byte) *errors.AppError {
+	appErr := &errors.AppError{HTTPStatus: statusCode}
+	if err := json.Unmarshal(body, appErr); err != nil || appErr.Message == "" {
+		appErr.Message = strings.TrimSpace(string(body))
+		if appErr.Message == "" {
+			appErr.Message = http.StatusText(statusCode)
+		}
+	}
+	if appErr.Code == "" {
+		appErr.Code = errorCode(codesFromHTTP[statusCode], appErr.Violations)
+	}
+	return appErr
+}
+
+func errorCode(code errors.ErrorCode, violations []errors.FieldViolation) errors.ErrorCode {
+	switch {
+	case code == "":
+		return errors.ErrCodeInternal
+	case code == errors.ErrCodeInvalidRequest && len(violations) > 0:
+		return errors.ErrCodeValidationFailed
+	default:
+		return code
+	}
+}
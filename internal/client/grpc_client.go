@@ -3,12 +3,18 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 
+	authpb "go-grpc-rest-demo/api/gen/go/auth/v1"
+	exportpb "go-grpc-rest-demo/api/gen/go/export/v1"
+	moneypb "go-grpc-rest-demo/api/gen/go/money/v1"
+	orderpb "go-grpc-rest-demo/api/gen/go/order/v1"
 	productpb "go-grpc-rest-demo/api/gen/go/product/v1"
+	tenantpb "go-grpc-rest-demo/api/gen/go/tenant/v1"
 	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
+	"go-grpc-rest-demo/internal/server/model"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // GRPCClient wraps gRPC service clients
@@ -16,13 +22,31 @@ type GRPCClient struct {
 	conn          *grpc.ClientConn
 	userClient    userpb.UserServiceClient
 	productClient productpb.ProductServiceClient
+	orderClient   orderpb.OrderServiceClient
+	authClient    authpb.AuthServiceClient
+	tenantClient  tenantpb.TenantServiceClient
 	config        *Config
 }
 
 // NewGRPCClient creates a new gRPC client
 func NewGRPCClient(config *Config) (*GRPCClient, error) {
+	creds, err := config.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := grpc.NewClient(config.GRPCAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithPerRPCCredentials(tokenCredentials{config: config}),
+		grpc.WithDefaultServiceConfig(config.Retry.serviceConfig()),
+		grpc.WithChainUnaryInterceptor(
+			tenantUnaryInterceptor(config),
+			idempotencyInterceptor(),
+			hedgingInterceptor(config),
+			attemptUnaryInterceptor(),
+		),
+		grpc.WithChainStreamInterceptor(tenantStreamInterceptor(config), attemptStreamInterceptor()),
+		grpc.WithStatsHandler(&attemptLogger{config: config}),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to gRPC server at %s: %v", config.GRPCAddr, err)
@@ -32,6 +56,9 @@ func NewGRPCClient(config *Config) (*GRPCClient, error) {
 		conn:          conn,
 		userClient:    userpb.NewUserServiceClient(conn),
 		productClient: productpb.NewProductServiceClient(conn),
+		orderClient:   orderpb.NewOrderServiceClient(conn),
+		authClient:    authpb.NewAuthServiceClient(conn),
+		tenantClient:  tenantpb.NewTenantServiceClient(conn),
 		config:        config,
 	}, nil
 }
@@ -105,16 +132,17 @@ func (c *GRPCClient) DeleteUser(ctx context.Context, id string) error {
 	return err
 }
 
-func (c *GRPCClient) ListUsers(ctx context.Context, page, pageSize int32, sortBy, filter *string) ([]*userpb.User, int32, int32, int32, error) {
+func (c *GRPCClient) ListUsers(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) ([]*userpb.User, int32, int32, int32, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
 
 	req := &userpb.ListUsersRequest{
 		Page:     page,
 		PageSize: pageSize,
-		SortBy:   sortBy,
 		Filter:   filter,
+		OrderBy:  orderBy,
 	}
+	req.CreatedAfter, req.CreatedBefore, req.UpdatedAfter, req.UpdatedBefore = timeRangeToPB(timeRange)
 
 	resp, err := c.userClient.ListUsers(ctx, req)
 	if err != nil {
@@ -124,16 +152,136 @@ func (c *GRPCClient) ListUsers(ctx context.Context, page, pageSize int32, sortBy
 	return resp.Users, resp.TotalCount, resp.Page, resp.PageSize, nil
 }
 
+func (c *GRPCClient) CheckUserPolicy(ctx context.Context, repair bool) (*userpb.CheckUserPolicyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	return c.userClient.CheckUserPolicy(ctx, &userpb.CheckUserPolicyRequest{Repair: repair})
+}
+
+func (c *GRPCClient) VerifyEmail(ctx context.Context, token string) (*userpb.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.userClient.VerifyEmail(ctx, &userpb.VerifyEmailRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.User, nil
+}
+
+func (c *GRPCClient) ResendVerification(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	_, err := c.userClient.ResendVerification(ctx, &userpb.ResendVerificationRequest{Id: id})
+	return err
+}
+
+func (c *GRPCClient) SetPassword(ctx context.Context, id, password, setupToken string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	_, err := c.userClient.SetPassword(ctx, &userpb.SetPasswordRequest{Id: id, Password: password, SetupToken: setupToken})
+	return err
+}
+
+func (c *GRPCClient) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	req := &userpb.ChangePasswordRequest{
+		Id:              id,
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	}
+	_, err := c.userClient.ChangePassword(ctx, req)
+	return err
+}
+
+// Auth service methods
+
+func (c *GRPCClient) Login(ctx context.Context, username, password string) (*authpb.AuthTokens, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.authClient.Login(ctx, &authpb.LoginRequest{Username: username, Password: password})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Tokens, nil
+}
+
+func (c *GRPCClient) RefreshToken(ctx context.Context, refreshToken string) (*authpb.AuthTokens, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.authClient.RefreshToken(ctx, &authpb.RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Tokens, nil
+}
+
+// Tenant service methods
+
+func (c *GRPCClient) CreateTenant(ctx context.Context, id, displayName string) (*tenantpb.Tenant, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.tenantClient.CreateTenant(ctx, &tenantpb.CreateTenantRequest{Id: id, DisplayName: displayName})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Tenant, nil
+}
+
+func (c *GRPCClient) GetTenant(ctx context.Context, id string) (*tenantpb.Tenant, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.tenantClient.GetTenant(ctx, &tenantpb.GetTenantRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Tenant, nil
+}
+
+func (c *GRPCClient) ListTenants(ctx context.Context) ([]*tenantpb.Tenant, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.tenantClient.ListTenants(ctx, &tenantpb.ListTenantsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Tenants, nil
+}
+
+func (c *GRPCClient) DeleteTenant(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	_, err := c.tenantClient.DeleteTenant(ctx, &tenantpb.DeleteTenantRequest{Id: id})
+	return err
+}
+
 // Product service methods
 
-func (c *GRPCClient) CreateProduct(ctx context.Context, name, description, category string, price float64, quantity int32) (*productpb.Product, error) {
+func (c *GRPCClient) CreateProduct(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*productpb.Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
 
 	req := &productpb.CreateProductRequest{
 		Name:        name,
 		Description: description,
-		Price:       price,
+		PriceMoney:  &moneypb.Money{CurrencyCode: price.CurrencyCode, Units: price.Units, Nanos: price.Nanos},
 		Quantity:    quantity,
 		Category:    category,
 	}
@@ -160,7 +308,39 @@ func (c *GRPCClient) GetProduct(ctx context.Context, id string) (*productpb.Prod
 	return resp.Product, nil
 }
 
-func (c *GRPCClient) SearchProducts(ctx context.Context, query, category *string, minPrice, maxPrice *float64, page, pageSize int32) ([]*productpb.Product, int32, int32, int32, error) {
+// UpdateProduct changes the fields that are not nil
+func (c *GRPCClient) UpdateProduct(ctx context.Context, id string, name, description, category *string, price *model.Money, quantity *int32) (*productpb.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	req := &productpb.UpdateProductRequest{
+		Id:          id,
+		Name:        name,
+		Description: description,
+		Quantity:    quantity,
+		Category:    category,
+	}
+	if price != nil {
+		req.PriceMoney = &moneypb.Money{CurrencyCode: price.CurrencyCode, Units: price.Units, Nanos: price.Nanos}
+	}
+
+	resp, err := c.productClient.UpdateProduct(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Product, nil
+}
+
+func (c *GRPCClient) DeleteProduct(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	_, err := c.productClient.DeleteProduct(ctx, &productpb.DeleteProductRequest{Id: id})
+	return err
+}
+
+func (c *GRPCClient) SearchProducts(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) ([]*productpb.Product, int32, int32, int32, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
 
@@ -171,7 +351,10 @@ func (c *GRPCClient) SearchProducts(ctx context.Context, query, category *string
 		MaxPrice: maxPrice,
 		Page:     page,
 		PageSize: pageSize,
+		OrderBy:  orderBy,
+		Filter:   filter,
 	}
+	req.CreatedAfter, req.CreatedBefore, req.UpdatedAfter, req.UpdatedBefore = timeRangeToPB(timeRange)
 
 	resp, err := c.productClient.SearchProducts(ctx, req)
 	if err != nil {
@@ -180,3 +363,199 @@ func (c *GRPCClient) SearchProducts(ctx context.Context, query, category *string
 
 	return resp.Products, resp.TotalCount, resp.Page, resp.PageSize, nil
 }
+
+// importChunkSize is the amount of file data sent per ImportProducts message
+const importChunkSize = 32 * 1024
+
+var importFormats = map[string]productpb.ImportFormat{
+	model.ImportFormatCSV:    productpb.ImportFormat_IMPORT_FORMAT_CSV,
+	model.ImportFormatNDJSON: productpb.ImportFormat_IMPORT_FORMAT_NDJSON,
+}
+
+func (c *GRPCClient) ImportProducts(ctx context.Context, format string, data io.Reader) (*productpb.ImportProductsResponse, error) {
+	pbFormat, ok := importFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	stream, err := c.productClient.ImportProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, importChunkSize)
+	req := &productpb.ImportProductsRequest{Format: pbFormat}
+	for {
+		n, readErr := data.Read(buf)
+		if n > 0 {
+			req.Data = buf[:n]
+			if err := stream.Send(req); err != nil {
+				// The server ended the stream; its status is returned by CloseAndRecv
+				break
+			}
+			req = &productpb.ImportProductsRequest{}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read import data: %v", readErr)
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+var exportFormats = map[string]exportpb.ExportFormat{
+	model.ExportFormatNDJSON:   exportpb.ExportFormat_EXPORT_FORMAT_NDJSON,
+	model.ExportFormatCSV:      exportpb.ExportFormat_EXPORT_FORMAT_CSV,
+	model.ExportFormatProtobuf: exportpb.ExportFormat_EXPORT_FORMAT_PROTOBUF,
+}
+
+// exportChunk is a message of an export stream
+type exportChunk interface {
+	GetData() []byte
+}
+
+// receiveExport writes the data of every message of an export stream into w
+func receiveExport[T exportChunk](recv func() (T, error), w io.Writer) (int64, error) {
+	var written int64
+	for {
+		msg, err := recv()
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(msg.GetData())
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+func (c *GRPCClient) ExportUsers(ctx context.Context, format, orderBy string, filter *string, timeRange model.TimeRange, w io.Writer) (int64, error) {
+	pbFormat, ok := exportFormats[format]
+	if !ok {
+		return 0, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	req := &userpb.ExportUsersRequest{
+		Format:  pbFormat,
+		Filter:  filter,
+		OrderBy: orderBy,
+	}
+	req.CreatedAfter, req.CreatedBefore, req.UpdatedAfter, req.UpdatedBefore = timeRangeToPB(timeRange)
+
+	stream, err := c.userClient.ExportUsers(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return receiveExport(stream.Recv, w)
+}
+
+func (c *GRPCClient) ExportProducts(ctx context.Context, format string, query, category, categoryID, filter *string, includeDescendants bool, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, w io.Writer) (int64, error) {
+	pbFormat, ok := exportFormats[format]
+	if !ok {
+		return 0, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	req := &productpb.ExportProductsRequest{
+		Format:             pbFormat,
+		Query:              query,
+		Category:           category,
+		MinPrice:           minPrice,
+		MaxPrice:           maxPrice,
+		CategoryId:         categoryID,
+		IncludeDescendants: includeDescendants,
+		OrderBy:            orderBy,
+		Filter:             filter,
+	}
+	req.CreatedAfter, req.CreatedBefore, req.UpdatedAfter, req.UpdatedBefore = timeRangeToPB(timeRange)
+
+	stream, err := c.productClient.ExportProducts(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return receiveExport(stream.Recv, w)
+}
+
+// Order service methods
+
+func (c *GRPCClient) CreateOrder(ctx context.Context, userID string, items []model.CreateOrderItem) (*orderpb.Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	req := &orderpb.CreateOrderRequest{
+		UserId: userID,
+		Items:  make([]*orderpb.CreateOrderItem, len(items)),
+	}
+	for i, item := range items {
+		req.Items[i] = &orderpb.CreateOrderItem{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	resp, err := c.orderClient.CreateOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Order, nil
+}
+
+func (c *GRPCClient) GetOrder(ctx context.Context, id string) (*orderpb.Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.orderClient.GetOrder(ctx, &orderpb.GetOrderRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Order, nil
+}
+
+func (c *GRPCClient) ListOrders(ctx context.Context, userID string, page, pageSize int32) ([]*orderpb.Order, int32, int32, int32, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	req := &orderpb.ListOrdersRequest{
+		UserId:   userID,
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	resp, err := c.orderClient.ListOrders(ctx, req)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	return resp.Orders, resp.TotalCount, resp.Page, resp.PageSize, nil
+}
+
+func (c *GRPCClient) CancelOrder(ctx context.Context, id string) (*orderpb.Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.orderClient.CancelOrder(ctx, &orderpb.CancelOrderRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Order, nil
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// idempotencyKeyHeader is sent with REST POST, PUT and DELETE requests
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotencyKeyMetadata is sent with mutating gRPC calls
+	idempotencyKeyMetadata = "idempotency-key"
+)
+
+// readOnlyPrefixes mark gRPC methods that do not need an idempotency key
+var readOnlyPrefixes = []string{"Get", "List", "Search", "BatchGet", "Watch", "Export"}
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx whose mutating call sends key
+// instead of a generated one. A key names a single operation: the server
+// replays the first response to repeats of it and rejects the key with any
+// other request, so give each operation a context of its own.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// idempotencyKey returns the key ctx carries, or a new random one so that
+// every retry of one call shares a key the server can replay the first
+// response for.
+func idempotencyKey(ctx context.Context) string {
+	if key, ok := ctx.Value(idempotencyKeyContextKey{}).(string); ok && key != "" {
+		return key
+	}
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// needsIdempotencyKey reports whether a REST method has side effects
+func needsIdempotencyKey(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodDelete
+}
+
+// idempotencyInterceptor attaches an idempotency key to mutating gRPC calls.
+// The key is added once before the call, so transparent retries reuse it.
+func idempotencyInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if isMutatingMethod(method) {
+			if md, _ := metadata.FromOutgoingContext(ctx); len(md.Get(idempotencyKeyMetadata)) == 0 {
+				ctx = metadata.AppendToOutgoingContext(ctx, idempotencyKeyMetadata, idempotencyKey(ctx))
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func isMutatingMethod(fullMethod string) bool {
+	name := path.Base(fullMethod)
+	for _, prefix := range readOnlyPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
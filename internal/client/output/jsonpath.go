@@ -0,0 +1,287 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a template in the kubectl JSONPath dialect: text with
+// expressions in braces, such as
+//
+//	{range .users[*]}{.id}{"\t"}{.email}{"\n"}{end}
+//
+// Paths select fields with .name or ['name'], elements with [n] (negative
+// counts from the end) and all elements or values with [*]. Several
+// results of one expression are separated by spaces.
+type jsonPath struct {
+	nodes []pathNode
+}
+
+// pathNode is literal text, an expression, or a range over an expression.
+// Text nodes have nil steps.
+type pathNode struct {
+	text  string
+	steps []pathStep
+	// body is set for ranges, which run it once per selected value
+	body    []pathNode
+	isRange bool
+}
+
+type pathStep struct {
+	field string
+	index int
+	// all selects every element of a list or value of an object
+	all     bool
+	isIndex bool
+}
+
+func parseJSONPath(expr string) (*jsonPath, error) {
+	stack := [][]pathNode{nil}
+	var ranges []pathNode
+
+	for expr != "" {
+		open := strings.IndexByte(expr, '{')
+		if open < 0 {
+			stack[len(stack)-1] = append(stack[len(stack)-1], pathNode{text: expr})
+			break
+		}
+		if open > 0 {
+			stack[len(stack)-1] = append(stack[len(stack)-1], pathNode{text: expr[:open]})
+		}
+		end := closingBrace(expr, open)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed expression %q", expr[open:])
+		}
+		action := strings.TrimSpace(expr[open+1 : end])
+		expr = expr[end+1:]
+
+		switch {
+		case action == "end":
+			if len(ranges) == 0 {
+				return nil, fmt.Errorf("{end} without {range}")
+			}
+			node := ranges[len(ranges)-1]
+			node.body = stack[len(stack)-1]
+			ranges, stack = ranges[:len(ranges)-1], stack[:len(stack)-1]
+			stack[len(stack)-1] = append(stack[len(stack)-1], node)
+		case strings.HasPrefix(action, "range "):
+			steps, err := parseSteps(strings.TrimSpace(strings.TrimPrefix(action, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, pathNode{steps: steps, isRange: true})
+			stack = append(stack, nil)
+		case strings.HasPrefix(action, `"`):
+			text, err := strconv.Unquote(action)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", action)
+			}
+			stack[len(stack)-1] = append(stack[len(stack)-1], pathNode{text: text})
+		default:
+			steps, err := parseSteps(action)
+			if err != nil {
+				return nil, err
+			}
+			stack[len(stack)-1] = append(stack[len(stack)-1], pathNode{steps: steps})
+		}
+	}
+
+	if len(ranges) > 0 {
+		return nil, fmt.Errorf("{range} without {end}")
+	}
+	return &jsonPath{nodes: stack[0]}, nil
+}
+
+// closingBrace finds the brace closing the expression opened at open,
+// skipping braces inside quoted strings
+func closingBrace(expr string, open int) int {
+	inString := false
+	for i := open + 1; i < len(expr); i++ {
+		switch {
+		case inString && expr[i] == '\\':
+			i++
+		case expr[i] == '"':
+			inString = !inString
+		case !inString && expr[i] == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseSteps(path string) ([]pathStep, error) {
+	rest := strings.TrimPrefix(path, "$")
+	if rest == "" || (rest[0] != '.' && rest[0] != '[') {
+		return nil, fmt.Errorf("path %q must start with . or [", path)
+	}
+
+	// Not nil even for ".", which tells expressions from text nodes
+	steps := []pathStep{}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			n := strings.IndexAny(rest, ".[")
+			if n < 0 {
+				n = len(rest)
+			}
+			switch name := rest[:n]; name {
+			case "":
+				// A bare "." selects the current value
+			case "*":
+				steps = append(steps, pathStep{all: true})
+			default:
+				steps = append(steps, pathStep{field: name})
+			}
+			rest = rest[n:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			step, err := parseSubscript(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("%v in path %q", err, path)
+			}
+			steps = append(steps, step)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %q", rest[0], path)
+		}
+	}
+	return steps, nil
+}
+
+func parseSubscript(s string) (pathStep, error) {
+	if s == "*" {
+		return pathStep{all: true}, nil
+	}
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return pathStep{field: s[1 : len(s)-1]}, nil
+	}
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return pathStep{}, fmt.Errorf("invalid subscript [%s]", s)
+	}
+	return pathStep{index: index, isIndex: true}, nil
+}
+
+func (p *jsonPath) execute(w io.Writer, data any) error {
+	return executeNodes(w, p.nodes, data)
+}
+
+func executeNodes(w io.Writer, nodes []pathNode, data any) error {
+	for _, node := range nodes {
+		if node.steps == nil && !node.isRange {
+			if _, err := io.WriteString(w, node.text); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values, err := evalSteps(node.steps, data)
+		if err != nil {
+			return err
+		}
+		if node.isRange {
+			for _, value := range values {
+				if err := executeNodes(w, node.body, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		texts := make([]string, len(values))
+		for i, value := range values {
+			texts[i] = formatValue(value)
+		}
+		if _, err := io.WriteString(w, strings.Join(texts, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evalSteps(steps []pathStep, data any) ([]any, error) {
+	values := []any{data}
+	for _, step := range steps {
+		var next []any
+		for _, value := range values {
+			selected, err := evalStep(step, value)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, selected...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+func evalStep(step pathStep, value any) ([]any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		switch {
+		case step.all:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			values := make([]any, len(keys))
+			for i, key := range keys {
+				values[i] = v[key]
+			}
+			return values, nil
+		case step.isIndex:
+			return nil, fmt.Errorf("cannot index an object with [%d]", step.index)
+		}
+		field, ok := v[step.field]
+		if !ok {
+			return nil, fmt.Errorf("%s is not found", step.field)
+		}
+		return []any{field}, nil
+	case []any:
+		switch {
+		case step.all:
+			return v, nil
+		case step.isIndex:
+			index := step.index
+			if index < 0 {
+				index += len(v)
+			}
+			if index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("index [%d] is out of range for %d elements", step.index, len(v))
+			}
+			return []any{v[index]}, nil
+		}
+		return nil, fmt.Errorf("cannot select %s from a list; use [*] first", step.field)
+	default:
+		return nil, fmt.Errorf("cannot select from %s", formatValue(value))
+	}
+}
+
+// formatValue prints scalars bare and objects or lists as compact JSON
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
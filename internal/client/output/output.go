@@ -0,0 +1,188 @@
+// Package output renders CLI results as JSON, YAML, tables, CSV, Go
+// templates or JSONPath expressions. Every format works on the model types,
+// so a result prints the same whichever transport fetched it.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats. go-template and jsonpath take an expression after "=",
+// as in "jsonpath={.users[*].email}".
+const (
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatTable      = "table"
+	FormatWide       = "wide"
+	FormatCSV        = "csv"
+	FormatGoTemplate = "go-template"
+	FormatJSONPath   = "jsonpath"
+)
+
+// Printer writes results in one output format
+type Printer struct {
+	format   string
+	template *template.Template
+	path     *jsonPath
+}
+
+// NewPrinter parses an --output value such as "table" or "go-template={{.id}}"
+func NewPrinter(spec string) (*Printer, error) {
+	format, expr, hasExpr := strings.Cut(spec, "=")
+	p := &Printer{format: format}
+
+	switch format {
+	case FormatJSON, FormatYAML, FormatTable, FormatWide, FormatCSV:
+		if hasExpr {
+			return nil, fmt.Errorf("output format %s takes no expression", format)
+		}
+	case FormatGoTemplate:
+		if expr == "" {
+			return nil, fmt.Errorf("output format go-template needs a template, as in go-template={{.id}}")
+		}
+		tmpl, err := template.New("output").Option("missingkey=error").Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %v", err)
+		}
+		p.template = tmpl
+	case FormatJSONPath:
+		if expr == "" {
+			return nil, fmt.Errorf("output format jsonpath needs an expression, as in jsonpath={.id}")
+		}
+		path, err := parseJSONPath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath: %v", err)
+		}
+		p.path = path
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (json, yaml, table, wide, csv, go-template=..., jsonpath=...)", spec)
+	}
+	return p, nil
+}
+
+// List is one page of a list result. Structured formats show the items under
+// Resource next to the pagination fields; tables end with a page footer.
+type List struct {
+	Resource   string
+	Items      any
+	TotalCount int32
+	Page       int32
+	PageSize   int32
+}
+
+func (l List) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		l.Resource:    l.Items,
+		"total_count": l.TotalCount,
+		"page":        l.Page,
+		"page_size":   l.PageSize,
+	})
+}
+
+// Print writes v, a model value, a slice of them or a List, to w
+func (p *Printer) Print(w io.Writer, v any) error {
+	switch p.format {
+	case FormatTable, FormatWide:
+		t, err := tableFor(v, p.format == FormatWide)
+		if err != nil {
+			return err
+		}
+		return t.write(w)
+	case FormatCSV:
+		t, err := tableFor(v, true)
+		if err != nil {
+			return err
+		}
+		return t.writeCSV(csv.NewWriter(w))
+	case FormatJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %v", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	// The remaining formats address fields by their JSON names
+	data, err := generic(v)
+	if err != nil {
+		return err
+	}
+	switch p.format {
+	case FormatYAML:
+		out, err := yaml.Marshal(yamlNumbers(data))
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %v", err)
+		}
+		_, err = w.Write(out)
+		return err
+	case FormatGoTemplate:
+		var buf bytes.Buffer
+		if err := p.template.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to execute go-template: %v", err)
+		}
+		return writeLine(w, buf.Bytes())
+	default:
+		var buf bytes.Buffer
+		if err := p.path.execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to execute jsonpath: %v", err)
+		}
+		return writeLine(w, buf.Bytes())
+	}
+}
+
+// generic converts v to the maps, slices and scalars of its JSON form.
+// Numbers stay json.Number so that large integers print exactly.
+func generic(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %v", err)
+	}
+	return out, nil
+}
+
+// yamlNumbers turns json.Number values into numbers, which YAML would
+// otherwise quote as strings
+func yamlNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = yamlNumbers(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = yamlNumbers(value)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// writeLine writes expression output, ending it with a newline for shells
+func writeLine(w io.Writer, out []byte) error {
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	_, err := w.Write(out)
+	return err
+}
@@ -0,0 +1,128 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputTestSuite struct {
+	suite.Suite
+	users []model.User
+}
+
+func (suite *OutputTestSuite) SetupTest() {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	suite.users = []model.User{
+		{ID: "1", TenantID: "default", Username: "alice", Email: "alice@example.com", FullName: "Alice, Jr.", IsActive: true, CreatedAt: created, UpdatedAt: created},
+		{ID: "2", TenantID: "default", Username: "bob", Email: "bob@example.com", FullName: "Bob", CreatedAt: created, UpdatedAt: created},
+	}
+}
+
+func (suite *OutputTestSuite) print(spec string, v any) string {
+	printer, err := NewPrinter(spec)
+	suite.Require().NoError(err)
+	var buf bytes.Buffer
+	suite.Require().NoError(printer.Print(&buf, v))
+	return buf.String()
+}
+
+func (suite *OutputTestSuite) page() List {
+	return List{Resource: "users", Items: suite.users, TotalCount: 5, Page: 1, PageSize: 2}
+}
+
+func (suite *OutputTestSuite) TestTable() {
+	assert.Equal(suite.T(), ""+
+		"ID   USERNAME   EMAIL               FULL NAME    ACTIVE   CREATED\n"+
+		"1    alice      alice@example.com   Alice, Jr.   true     2026-01-02T03:04:05Z\n"+
+		"2    bob        bob@example.com     Bob          false    2026-01-02T03:04:05Z\n"+
+		"\n"+
+		"Page 1 of 3, 5 users in total\n",
+		suite.print(FormatTable, suite.page()))
+
+	wide := suite.print(FormatWide, &suite.users[0])
+	assert.Contains(suite.T(), wide, "VERIFIED   TENANT    CREATED                UPDATED")
+	assert.NotContains(suite.T(), wide, "Page")
+
+	report := &model.UserPolicyReport{Checked: 3}
+	assert.Equal(suite.T(), "3 users checked, 0 repaired\n", suite.print(FormatTable, report))
+}
+
+func (suite *OutputTestSuite) TestTableFallsBackToFields() {
+	out := suite.print(FormatTable, map[string]any{"checked": 3, "name": "x"})
+	assert.Equal(suite.T(), "FIELD     VALUE\nchecked   3\nname      x\n", out)
+
+	printer, _ := NewPrinter(FormatTable)
+	assert.Error(suite.T(), printer.Print(&bytes.Buffer{}, []string{"a"}))
+}
+
+func (suite *OutputTestSuite) TestCSV() {
+	assert.Equal(suite.T(), ""+
+		"ID,USERNAME,EMAIL,FULL NAME,ACTIVE,VERIFIED,TENANT,CREATED,UPDATED\n"+
+		"1,alice,alice@example.com,\"Alice, Jr.\",true,false,default,2026-01-02T03:04:05Z,2026-01-02T03:04:05Z\n"+
+		"2,bob,bob@example.com,Bob,false,false,default,2026-01-02T03:04:05Z,2026-01-02T03:04:05Z\n",
+		suite.print(FormatCSV, suite.page()))
+}
+
+func (suite *OutputTestSuite) TestStructuredFormats() {
+	assert.Contains(suite.T(), suite.print(FormatJSON, suite.page()), `"users": [`)
+
+	yaml := suite.print(FormatYAML, suite.page())
+	assert.Contains(suite.T(), yaml, "total_count: 5\n")
+	assert.Contains(suite.T(), yaml, "users:\n    - created_at: \"2026-01-02T03:04:05Z\"\n")
+
+	product := &model.Product{ID: "7", Name: "Saw", PriceMoney: model.Money{CurrencyCode: "USD", Units: 19, Nanos: 500_000_000}}
+	assert.Contains(suite.T(), suite.print(FormatYAML, product), "price_money:\n    amount: \"19.5\"\n    currency_code: USD\n")
+}
+
+func (suite *OutputTestSuite) TestGoTemplate() {
+	out := suite.print(`go-template={{range .users}}{{.username}} {{.is_active}}{{"\n"}}{{end}}`, suite.page())
+	assert.Equal(suite.T(), "alice true\nbob false\n", out)
+	assert.Equal(suite.T(), "5\n", suite.print("go-template={{.total_count}}", suite.page()))
+
+	printer, err := NewPrinter("go-template={{.missing}}")
+	suite.Require().NoError(err)
+	assert.Error(suite.T(), printer.Print(&bytes.Buffer{}, &suite.users[0]))
+}
+
+func (suite *OutputTestSuite) TestJSONPath() {
+	cases := map[string]string{
+		"jsonpath={.users[*].username}":                                     "alice bob\n",
+		"jsonpath={.users[-1].email}":                                       "bob@example.com\n",
+		"jsonpath=total: {$.total_count}":                                   "total: 5\n",
+		`jsonpath={.users[0]['full_name']}`:                                 "Alice, Jr.\n",
+		`jsonpath={range .users[*]}{.id}{"\t"}{.is_active}{"\n"}{end}`:      "1\ttrue\n2\tfalse\n",
+		`jsonpath={"{"}{.page}{"}"}`:                                        "{1}\n",
+		`jsonpath={range .users[*]}[{.username}]{end}`:                      "[alice][bob]\n",
+		`jsonpath={.users[*].username}{" has "}{.users[0].tenant_id}`:       "alice bob has default\n",
+		`jsonpath={range .users[*]}{.created_at}{"\n"}{end}`:                "2026-01-02T03:04:05Z\n2026-01-02T03:04:05Z\n",
+		`jsonpath={range .users[*]}{.username}{","}{end}{.total_count}`:     "alice,bob,5\n",
+		`jsonpath={.users[0].is_active} {.users[1].is_active} {.page_size}`: "true false 2\n",
+	}
+	for spec, want := range cases {
+		assert.Equal(suite.T(), want, suite.print(spec, suite.page()), spec)
+	}
+	assert.Contains(suite.T(), suite.print(`jsonpath={.users[1]}`, suite.page()), `"username":"bob"`)
+
+	printer, _ := NewPrinter("jsonpath={.users[5].id}")
+	assert.EqualError(suite.T(), printer.Print(&bytes.Buffer{}, suite.page()), "failed to execute jsonpath: index [5] is out of range for 2 elements")
+	printer, _ = NewPrinter("jsonpath={.users.id}")
+	assert.Error(suite.T(), printer.Print(&bytes.Buffer{}, suite.page()))
+}
+
+func (suite *OutputTestSuite) TestInvalidFormats() {
+	for _, spec := range []string{"xml", "table=x", "go-template", "go-template={{.id", "jsonpath=", "jsonpath={.id", "jsonpath={end}",
+		"jsonpath={range .users[*]}", "jsonpath={users}", "jsonpath={.users[x]}"} {
+		_, err := NewPrinter(spec)
+		assert.Error(suite.T(), err, spec)
+	}
+}
+
+func TestOutputTestSuite(t *testing.T) {
+	suite.Run(t, new(OutputTestSuite))
+}
@@ -0,0 +1,244 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/model"
+)
+
+// A table is the rows of a result under its column headers
+type table struct {
+	headers []string
+	rows    [][]string
+	footer  string
+}
+
+// column is one table column of a resource
+type column[T any] struct {
+	header string
+	// wide columns are only shown by the wide and csv formats
+	wide  bool
+	value func(T) string
+}
+
+func newTable[T any](columns []column[T], items []T, wide bool) *table {
+	t := &table{}
+	for _, c := range columns {
+		if wide || !c.wide {
+			t.headers = append(t.headers, c.header)
+		}
+	}
+	for _, item := range items {
+		row := make([]string, 0, len(t.headers))
+		for _, c := range columns {
+			if wide || !c.wide {
+				row = append(row, c.value(item))
+			}
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t
+}
+
+var userColumns = []column[model.User]{
+	{header: "ID", value: func(u model.User) string { return u.ID }},
+	{header: "USERNAME", value: func(u model.User) string { return u.Username }},
+	{header: "EMAIL", value: func(u model.User) string { return u.Email }},
+	{header: "FULL NAME", value: func(u model.User) string { return u.FullName }},
+	{header: "ACTIVE", value: func(u model.User) string { return strconv.FormatBool(u.IsActive) }},
+	{header: "VERIFIED", wide: true, value: func(u model.User) string { return strconv.FormatBool(u.EmailVerified) }},
+	{header: "TENANT", wide: true, value: func(u model.User) string { return u.TenantID }},
+	{header: "CREATED", value: func(u model.User) string { return formatTime(u.CreatedAt) }},
+	{header: "UPDATED", wide: true, value: func(u model.User) string { return formatTime(u.UpdatedAt) }},
+}
+
+var productColumns = []column[model.Product]{
+	{header: "ID", value: func(p model.Product) string { return p.ID }},
+	{header: "NAME", value: func(p model.Product) string { return p.Name }},
+	{header: "DESCRIPTION", wide: true, value: func(p model.Product) string { return p.Description }},
+	{header: "CATEGORY", value: func(p model.Product) string { return p.Category }},
+	{header: "CATEGORY ID", wide: true, value: func(p model.Product) string { return p.CategoryID }},
+	{header: "PRICE", value: func(p model.Product) string { return p.PriceMoney.String() }},
+	{header: "QUANTITY", value: func(p model.Product) string { return strconv.Itoa(int(p.Quantity)) }},
+	{header: "TENANT", wide: true, value: func(p model.Product) string { return p.TenantID }},
+	{header: "CREATED", wide: true, value: func(p model.Product) string { return formatTime(p.CreatedAt) }},
+	{header: "UPDATED", wide: true, value: func(p model.Product) string { return formatTime(p.UpdatedAt) }},
+}
+
+var orderColumns = []column[model.Order]{
+	{header: "ID", value: func(o model.Order) string { return o.ID }},
+	{header: "USER", value: func(o model.Order) string { return o.UserID }},
+	{header: "STATUS", value: func(o model.Order) string { return string(o.Status) }},
+	{header: "ITEMS", value: func(o model.Order) string { return strconv.Itoa(len(o.Items)) }},
+	{header: "PRODUCTS", wide: true, value: orderProducts},
+	{header: "TOTAL", value: func(o model.Order) string { return o.TotalPriceMoney.String() }},
+	{header: "TENANT", wide: true, value: func(o model.Order) string { return o.TenantID }},
+	{header: "CREATED", value: func(o model.Order) string { return formatTime(o.CreatedAt) }},
+	{header: "UPDATED", wide: true, value: func(o model.Order) string { return formatTime(o.UpdatedAt) }},
+}
+
+var tenantColumns = []column[model.Tenant]{
+	{header: "ID", value: func(t model.Tenant) string { return t.ID }},
+	{header: "DISPLAY NAME", value: func(t model.Tenant) string { return t.DisplayName }},
+	{header: "CREATED", value: func(t model.Tenant) string { return formatTime(t.CreatedAt) }},
+}
+
+var authTokensColumns = []column[model.AuthTokens]{
+	{header: "USER", value: func(a model.AuthTokens) string {
+		if a.User == nil {
+			return ""
+		}
+		return a.User.Username
+	}},
+	{header: "TOKEN TYPE", value: func(a model.AuthTokens) string { return a.TokenType }},
+	{header: "ACCESS EXPIRES", value: func(a model.AuthTokens) string { return formatTime(a.AccessTokenExpiresAt) }},
+	{header: "REFRESH EXPIRES", value: func(a model.AuthTokens) string { return formatTime(a.RefreshTokenExpiresAt) }},
+	{header: "ACCESS TOKEN", wide: true, value: func(a model.AuthTokens) string { return a.AccessToken }},
+	{header: "REFRESH TOKEN", wide: true, value: func(a model.AuthTokens) string { return a.RefreshToken }},
+}
+
+var policyViolationColumns = []column[model.UserPolicyViolation]{
+	{header: "USER", value: func(v model.UserPolicyViolation) string { return v.UserID }},
+	{header: "FIELD", value: func(v model.UserPolicyViolation) string { return v.Field }},
+	{header: "VALUE", value: func(v model.UserPolicyViolation) string { return v.Value }},
+	{header: "DESCRIPTION", value: func(v model.UserPolicyViolation) string { return v.Description }},
+	{header: "NORMALIZED", wide: true, value: func(v model.UserPolicyViolation) string { return v.NormalizedValue }},
+	{header: "REPAIRED", value: func(v model.UserPolicyViolation) string { return strconv.FormatBool(v.Repaired) }},
+}
+
+var importFailureColumns = []column[model.ImportFailure]{
+	{header: "LINE", value: func(f model.ImportFailure) string { return strconv.Itoa(int(f.Line)) }},
+	{header: "MESSAGE", value: func(f model.ImportFailure) string { return f.Message }},
+}
+
+// tableFor lays out a result. Types without their own columns are shown as
+// a field and value per row.
+func tableFor(v any, wide bool) (*table, error) {
+	switch v := v.(type) {
+	case List:
+		t, err := tableFor(v.Items, wide)
+		if err != nil {
+			return nil, err
+		}
+		t.footer = pageFooter(v)
+		return t, nil
+	case *model.User:
+		return newTable(userColumns, []model.User{*v}, wide), nil
+	case []model.User:
+		return newTable(userColumns, v, wide), nil
+	case *model.Product:
+		return newTable(productColumns, []model.Product{*v}, wide), nil
+	case []model.Product:
+		return newTable(productColumns, v, wide), nil
+	case *model.Order:
+		return newTable(orderColumns, []model.Order{*v}, wide), nil
+	case []model.Order:
+		return newTable(orderColumns, v, wide), nil
+	case *model.Tenant:
+		return newTable(tenantColumns, []model.Tenant{*v}, wide), nil
+	case []model.Tenant:
+		return newTable(tenantColumns, v, wide), nil
+	case *model.AuthTokens:
+		return newTable(authTokensColumns, []model.AuthTokens{*v}, wide), nil
+	case *model.UserPolicyReport:
+		t := newTable(policyViolationColumns, v.Violations, wide)
+		t.footer = fmt.Sprintf("%d users checked, %d repaired", v.Checked, v.Repaired)
+		return t, nil
+	case *model.ImportProductsSummary:
+		t := newTable(importFailureColumns, v.Failures, wide)
+		t.footer = fmt.Sprintf("%d created, %d updated, %d failed", v.Created, v.Updated, v.Failed)
+		return t, nil
+	default:
+		return fieldTable(v)
+	}
+}
+
+func fieldTable(v any) (*table, error) {
+	data, err := generic(v)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%T cannot be shown as a table", v)
+	}
+
+	t := &table{headers: []string{"FIELD", "VALUE"}}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.rows = append(t.rows, []string{name, formatValue(fields[name])})
+	}
+	return t, nil
+}
+
+// pageFooter tells where a page lies among all matches
+func pageFooter(l List) string {
+	pages := int32(1)
+	if l.PageSize > 0 && l.TotalCount > l.PageSize {
+		pages = (l.TotalCount + l.PageSize - 1) / l.PageSize
+	}
+	return fmt.Sprintf("Page %d of %d, %d %s in total", l.Page, pages, l.TotalCount, l.Resource)
+}
+
+func (t *table) write(w io.Writer) error {
+	// A footer alone says enough about an empty result
+	if len(t.rows) == 0 && t.footer != "" {
+		_, err := fmt.Fprintln(w, t.footer)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if t.footer != "" {
+		_, err := fmt.Fprintf(w, "\n%s\n", t.footer)
+		return err
+	}
+	return nil
+}
+
+func (t *table) writeCSV(w *csv.Writer) error {
+	if err := w.Write(t.headers); err != nil {
+		return err
+	}
+	if err := w.WriteAll(t.rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+func orderProducts(o model.Order) string {
+	products := make([]string, len(o.Items))
+	for i, item := range o.Items {
+		products[i] = fmt.Sprintf("%s:%d", item.ProductID, item.Quantity)
+	}
+	return strings.Join(products, ",")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// ParityReport compares one call made over gRPC and REST at once
+type ParityReport struct {
+	Operation string
+	GRPC      TransportResult
+	REST      TransportResult
+
+	// Differences lists the fields whose values differ, in path order
+	Differences []Difference
+}
+
+// TransportResult is the outcome of a call over one transport
+type TransportResult struct {
+	Value   any
+	Err     error
+	Latency time.Duration
+}
+
+// Difference is a field with different values over the two transports.
+// Values are JSON, or empty when the field is missing.
+type Difference struct {
+	Path string
+	GRPC string
+	REST string
+}
+
+// Compare runs a read-only call over gRPC and REST concurrently, handing
+// call a client bound to each transport, and reports the field-level
+// differences of the results. Errors are compared as the *errors.AppError
+// they carry. It needs a client made in "both" mode.
+func (c *UnifiedClient) Compare(ctx context.Context, operation string, call func(ctx context.Context, c Client) (any, error)) (*ParityReport, error) {
+	if c.grpcClient == nil || c.restClient == nil {
+		return nil, fmt.Errorf("comparing transports needs mode both, not %s", c.config.Mode)
+	}
+
+	report := &ParityReport{Operation: operation}
+	runs := []struct {
+		result *TransportResult
+		client Client
+	}{
+		{&report.GRPC, &UnifiedClient{grpcClient: c.grpcClient, config: c.config, transport: grpcTransport{c: c.grpcClient}}},
+		{&report.REST, &UnifiedClient{restClient: c.restClient, config: c.config, transport: c.restClient}},
+	}
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		wg.Go(func() {
+			start := time.Now()
+			run.result.Value, run.result.Err = call(ctx, run.client)
+			run.result.Latency = time.Since(start)
+		})
+	}
+	wg.Wait()
+
+	// A call failing over one transport only differs as a whole
+	if (report.GRPC.Err == nil) != (report.REST.Err == nil) {
+		report.Differences = []Difference{{Path: "error", GRPC: report.GRPC.errorText(), REST: report.REST.errorText()}}
+		return report, nil
+	}
+
+	grpcValue, err := report.GRPC.normalized()
+	if err != nil {
+		return nil, err
+	}
+	restValue, err := report.REST.normalized()
+	if err != nil {
+		return nil, err
+	}
+	report.Differences = diffValues("", grpcValue, restValue, nil)
+	return report, nil
+}
+
+// normalized returns the JSON form of the result, or of its error under
+// "error", so that both transports are compared field by field
+func (r TransportResult) normalized() (any, error) {
+	v := r.Value
+	if r.Err != nil {
+		v = map[string]any{"error": errors.AsAppError(r.Err)}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %v", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %v", err)
+	}
+	return out, nil
+}
+
+// errorText is the JSON of the error, or empty without one
+func (r TransportResult) errorText() string {
+	if r.Err == nil {
+		return ""
+	}
+	return jsonText(errors.AsAppError(r.Err))
+}
+
+// diffValues appends the differences between a and b at path to diffs
+func diffValues(path string, a, b any, diffs []Difference) []Difference {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			keys := make([]string, 0, len(a)+len(b))
+			for key := range a {
+				keys = append(keys, key)
+			}
+			for key := range b {
+				if _, ok := a[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fieldPath := key
+				if path != "" {
+					fieldPath = path + "." + key
+				}
+				diffs = diffField(fieldPath, a, b, key, diffs)
+			}
+			return diffs
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				elemPath := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(a):
+					diffs = append(diffs, Difference{Path: elemPath, REST: jsonText(b[i])})
+				case i >= len(b):
+					diffs = append(diffs, Difference{Path: elemPath, GRPC: jsonText(a[i])})
+				default:
+					diffs = diffValues(elemPath, a[i], b[i], diffs)
+				}
+			}
+			return diffs
+		}
+	}
+
+	if aText, bText := jsonText(a), jsonText(b); aText != bText {
+		diffs = append(diffs, Difference{Path: path, GRPC: aText, REST: bText})
+	}
+	return diffs
+}
+
+// diffField compares the key of two objects, either of which may lack it
+func diffField(path string, a, b map[string]any, key string, diffs []Difference) []Difference {
+	aValue, aOK := a[key]
+	bValue, bOK := b[key]
+	switch {
+	case !aOK:
+		return append(diffs, Difference{Path: path, REST: jsonText(bValue)})
+	case !bOK:
+		return append(diffs, Difference{Path: path, GRPC: jsonText(aValue)})
+	default:
+		return diffValues(path, aValue, bValue, diffs)
+	}
+}
+
+func jsonText(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
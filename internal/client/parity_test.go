@@ -0,0 +1,41 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ParityTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ParityTestSuite) decode(text string) any {
+	var v any
+	suite.Require().NoError(json.Unmarshal([]byte(text), &v))
+	return v
+}
+
+func (suite *ParityTestSuite) TestDiffValues() {
+	grpc := suite.decode(`{"users": [{"id": "1", "tags": ["a"]}, {"id": "2"}], "page": 1, "only_grpc": true}`)
+	rest := suite.decode(`{"users": [{"id": "1", "tags": ["b", "c"]}], "page": 2, "only_rest": null}`)
+
+	assert.Equal(suite.T(), []Difference{
+		{Path: "only_grpc", GRPC: "true"},
+		{Path: "only_rest", REST: "null"},
+		{Path: "page", GRPC: "1", REST: "2"},
+		{Path: "users[0].tags[0]", GRPC: `"a"`, REST: `"b"`},
+		{Path: "users[0].tags[1]", REST: `"c"`},
+		{Path: "users[1]", GRPC: `{"id":"2"}`},
+	}, diffValues("", grpc, rest, nil))
+
+	assert.Empty(suite.T(), diffValues("", grpc, grpc, nil))
+	assert.Equal(suite.T(), []Difference{{Path: "users", GRPC: "[]", REST: "{}"}},
+		diffValues("users", []any{}, map[string]any{}, nil))
+}
+
+func TestParityTestSuite(t *testing.T) {
+	suite.Run(t, new(ParityTestSuite))
+}
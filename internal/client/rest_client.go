@@ -8,7 +8,9 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
+	"time"
 
 	"go-grpc-rest-demo/internal/server/model"
 )
@@ -22,9 +24,15 @@ type RESTClient struct {
 
 // NewRESTClient creates a new REST client
 func NewRESTClient(config *Config) (*RESTClient, error) {
+	transport, err := config.httpTransport()
+	if err != nil {
+		return nil, err
+	}
+
 	return &RESTClient{
 		client: &http.Client{
-			Timeout: config.Timeout,
+			Transport: transport,
+			Timeout:   config.Timeout,
 		},
 		baseURL: config.RESTAddr,
 		config:  config,
@@ -40,41 +48,122 @@ func (c *RESTClient) Close() error {
 
 func (c *RESTClient) doRequest(ctx context.Context, method, path string, body any, result any) error {
 	var reqBody io.Reader
+	var contentType string
 	if body != nil {
 		jsonData, err := json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %v", err)
 		}
 		reqBody = bytes.NewBuffer(jsonData)
+		contentType = "application/json"
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	return c.send(ctx, method, path, contentType, reqBody, result)
+}
+
+// send performs a request with a raw body and decodes the JSON response into result
+func (c *RESTClient) send(ctx context.Context, method, path, contentType string, body io.Reader, result any) error {
+	resp, err := c.do(ctx, method, path, contentType, body)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %v", err)
+		}
+		timesToUTC(reflect.ValueOf(result))
 	}
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+	return nil
+}
+
+// do performs a request and returns the response of a successful call;
+// the caller must close its body
+func (c *RESTClient) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if needsIdempotencyKey(method) {
+		req.Header.Set(idempotencyKeyHeader, idempotencyKey(ctx))
+	}
+	if c.config.Tenant != "" {
+		req.Header.Set(tenantHeader, c.config.Tenant)
+	}
+	if c.config.Token != "" {
+		req.Header.Set(authorizationHeader, "Bearer "+c.config.Token)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doWithRetry(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to make request: %v", err)
+		return nil, fmt.Errorf("failed to make request: %v", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer func() { _ = resp.Body.Close() }()
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, errorFromHTTP(resp.StatusCode, bodyBytes)
 	}
 
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %v", err)
+	return resp, nil
+}
+
+// doWithRetry sends req, and sends it again while it fails with a network
+// error or a retryable status and the retry policy allows another attempt
+func (c *RESTClient) doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	policy := c.config.Retry
+	for attempt := 1; ; attempt++ {
+		resp, err := c.client.Do(req)
+
+		var reason string
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case policy.retryableStatus(resp.StatusCode):
+			reason = resp.Status
+		}
+		if reason == "" || attempt >= policy.MaxAttempts || !retryable(req) || ctx.Err() != nil {
+			return resp, err
 		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		delay := policy.backoff(attempt)
+		c.config.logf("%s %s: attempt %d in %s (previous attempt failed: %s)", req.Method, req.URL.Path, attempt+1, delay, reason)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// download streams the body of a GET response into w
+func (c *RESTClient) download(ctx context.Context, path string, w io.Writer) (int64, error) {
+	resp, err := c.do(ctx, "GET", path, "", nil)
+	if err != nil {
+		return 0, err
 	}
+	defer func() { _ = resp.Body.Close() }()
 
-	return nil
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("failed to read response: %v", err)
+	}
+	return n, nil
 }
 
 // User service methods
@@ -135,16 +224,17 @@ func (c *RESTClient) DeleteUser(ctx context.Context, id string) error {
 	return c.doRequest(ctx, "DELETE", "/api/v1/users/"+id, nil, nil)
 }
 
-func (c *RESTClient) ListUsers(ctx context.Context, page, pageSize int32, sortBy, filter *string) ([]model.User, int32, int32, int32, error) {
+func (c *RESTClient) ListUsers(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) ([]model.User, int32, int32, int32, error) {
 	params := url.Values{}
 	params.Set("page", strconv.Itoa(int(page)))
 	params.Set("page_size", strconv.Itoa(int(pageSize)))
-	if sortBy != nil {
-		params.Set("sort_by", *sortBy)
+	if orderBy != "" {
+		params.Set("order_by", orderBy)
 	}
 	if filter != nil {
 		params.Set("filter", *filter)
 	}
+	setTimeRangeParams(params, timeRange)
 
 	var result struct {
 		Users      []model.User `json:"users"`
@@ -161,13 +251,124 @@ func (c *RESTClient) ListUsers(ctx context.Context, page, pageSize int32, sortBy
 	return result.Users, result.TotalCount, result.Page, result.PageSize, nil
 }
 
+func (c *RESTClient) CheckUserPolicy(ctx context.Context, repair bool) (*model.UserPolicyReport, error) {
+	var result struct {
+		Policy *model.UserPolicyReport `json:"policy"`
+	}
+
+	path := "/api/v1/users:checkPolicy?repair=" + strconv.FormatBool(repair)
+	if err := c.doRequest(ctx, "POST", path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Policy, nil
+}
+
+func (c *RESTClient) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	var result struct {
+		User *model.User `json:"user"`
+	}
+
+	req := &model.VerifyEmailRequest{Token: token}
+	if err := c.doRequest(ctx, "POST", "/api/v1/users:verifyEmail", req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.User, nil
+}
+
+func (c *RESTClient) ResendVerification(ctx context.Context, id string) error {
+	return c.doRequest(ctx, "POST", "/api/v1/users/"+id+"/resendVerification", nil, nil)
+}
+
+func (c *RESTClient) SetPassword(ctx context.Context, id, password, setupToken string) error {
+	req := &model.SetPasswordRequest{Password: password, SetupToken: setupToken}
+	return c.doRequest(ctx, "PUT", "/api/v1/users/"+id+"/password", req, nil)
+}
+
+func (c *RESTClient) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	req := &model.ChangePasswordRequest{CurrentPassword: currentPassword, NewPassword: newPassword}
+	return c.doRequest(ctx, "POST", "/api/v1/users/"+id+"/changePassword", req, nil)
+}
+
+// Auth service methods
+
+func (c *RESTClient) Login(ctx context.Context, username, password string) (*model.AuthTokens, error) {
+	var result struct {
+		Tokens *model.AuthTokens `json:"tokens"`
+	}
+
+	req := &model.LoginRequest{Username: username, Password: password}
+	if err := c.doRequest(ctx, "POST", "/api/v1/auth/login", req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Tokens, nil
+}
+
+func (c *RESTClient) RefreshToken(ctx context.Context, refreshToken string) (*model.AuthTokens, error) {
+	var result struct {
+		Tokens *model.AuthTokens `json:"tokens"`
+	}
+
+	req := &model.RefreshTokenRequest{RefreshToken: refreshToken}
+	if err := c.doRequest(ctx, "POST", "/api/v1/auth/refresh", req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Tokens, nil
+}
+
+// Tenant service methods
+
+func (c *RESTClient) CreateTenant(ctx context.Context, id, displayName string) (*model.Tenant, error) {
+	var result struct {
+		Tenant *model.Tenant `json:"tenant"`
+	}
+
+	req := &model.CreateTenantRequest{ID: id, DisplayName: displayName}
+	if err := c.doRequest(ctx, "POST", "/api/v1/tenants", req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Tenant, nil
+}
+
+func (c *RESTClient) GetTenant(ctx context.Context, id string) (*model.Tenant, error) {
+	var result struct {
+		Tenant *model.Tenant `json:"tenant"`
+	}
+
+	if err := c.doRequest(ctx, "GET", "/api/v1/tenants/"+id, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Tenant, nil
+}
+
+func (c *RESTClient) ListTenants(ctx context.Context) ([]model.Tenant, error) {
+	var result struct {
+		Tenants []model.Tenant `json:"tenants"`
+	}
+
+	if err := c.doRequest(ctx, "GET", "/api/v1/tenants", nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Tenants, nil
+}
+
+func (c *RESTClient) DeleteTenant(ctx context.Context, id string) error {
+	return c.doRequest(ctx, "DELETE", "/api/v1/tenants/"+id, nil, nil)
+}
+
 // Product service methods
 
-func (c *RESTClient) CreateProduct(ctx context.Context, name, description, category string, price float64, quantity int32) (*model.Product, error) {
+func (c *RESTClient) CreateProduct(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*model.Product, error) {
 	req := &model.CreateProductRequest{
 		Name:        name,
 		Description: description,
-		Price:       price,
+		PriceMoney:  &price,
 		Quantity:    quantity,
 		Category:    category,
 	}
@@ -197,7 +398,33 @@ func (c *RESTClient) GetProduct(ctx context.Context, id string) (*model.Product,
 	return result.Product, nil
 }
 
-func (c *RESTClient) SearchProducts(ctx context.Context, query, category *string, minPrice, maxPrice *float64, page, pageSize int32) ([]model.Product, int32, int32, int32, error) {
+// UpdateProduct changes the fields that are not nil
+func (c *RESTClient) UpdateProduct(ctx context.Context, id string, name, description, category *string, price *model.Money, quantity *int32) (*model.Product, error) {
+	req := &model.UpdateProductRequest{
+		Name:        name,
+		Description: description,
+		PriceMoney:  price,
+		Quantity:    quantity,
+		Category:    category,
+	}
+
+	var result struct {
+		Product *model.Product `json:"product"`
+	}
+
+	err := c.doRequest(ctx, "PUT", "/api/v1/products/"+id, req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Product, nil
+}
+
+func (c *RESTClient) DeleteProduct(ctx context.Context, id string) error {
+	return c.doRequest(ctx, "DELETE", "/api/v1/products/"+id, nil, nil)
+}
+
+func (c *RESTClient) SearchProducts(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) ([]model.Product, int32, int32, int32, error) {
 	params := url.Values{}
 	params.Set("page", strconv.Itoa(int(page)))
 	params.Set("page_size", strconv.Itoa(int(pageSize)))
@@ -207,12 +434,19 @@ func (c *RESTClient) SearchProducts(ctx context.Context, query, category *string
 	if category != nil {
 		params.Set("category", *category)
 	}
+	if filter != nil {
+		params.Set("filter", *filter)
+	}
 	if minPrice != nil {
 		params.Set("min_price", strconv.FormatFloat(*minPrice, 'f', 2, 64))
 	}
 	if maxPrice != nil {
 		params.Set("max_price", strconv.FormatFloat(*maxPrice, 'f', 2, 64))
 	}
+	setTimeRangeParams(params, timeRange)
+	if orderBy != "" {
+		params.Set("order_by", orderBy)
+	}
 
 	var result struct {
 		Products   []model.Product `json:"products"`
@@ -228,3 +462,140 @@ func (c *RESTClient) SearchProducts(ctx context.Context, query, category *string
 
 	return result.Products, result.TotalCount, result.Page, result.PageSize, nil
 }
+
+// importContentTypes maps import formats to the media type sent to the server
+var importContentTypes = map[string]string{
+	model.ImportFormatCSV:    "text/csv",
+	model.ImportFormatNDJSON: "application/x-ndjson",
+}
+
+func (c *RESTClient) ImportProducts(ctx context.Context, format string, data io.Reader) (*model.ImportProductsSummary, error) {
+	contentType, ok := importContentTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+
+	var result struct {
+		Summary *model.ImportProductsSummary `json:"summary"`
+	}
+
+	err := c.send(ctx, "POST", "/api/v1/products:import?format="+url.QueryEscape(format), contentType, data, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Summary, nil
+}
+
+func (c *RESTClient) ExportUsers(ctx context.Context, format, orderBy string, filter *string, timeRange model.TimeRange, w io.Writer) (int64, error) {
+	params := url.Values{}
+	params.Set("format", format)
+	if orderBy != "" {
+		params.Set("order_by", orderBy)
+	}
+	if filter != nil {
+		params.Set("filter", *filter)
+	}
+	setTimeRangeParams(params, timeRange)
+
+	return c.download(ctx, "/api/v1/users:export?"+params.Encode(), w)
+}
+
+func (c *RESTClient) ExportProducts(ctx context.Context, format string, query, category, categoryID, filter *string, includeDescendants bool, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, w io.Writer) (int64, error) {
+	params := url.Values{}
+	params.Set("format", format)
+	if query != nil {
+		params.Set("query", *query)
+	}
+	if category != nil {
+		params.Set("category", *category)
+	}
+	if minPrice != nil {
+		params.Set("min_price", strconv.FormatFloat(*minPrice, 'f', -1, 64))
+	}
+	if maxPrice != nil {
+		params.Set("max_price", strconv.FormatFloat(*maxPrice, 'f', -1, 64))
+	}
+	if categoryID != nil {
+		params.Set("category_id", *categoryID)
+		if includeDescendants {
+			params.Set("include_descendants", "true")
+		}
+	}
+	if filter != nil {
+		params.Set("filter", *filter)
+	}
+	if orderBy != "" {
+		params.Set("order_by", orderBy)
+	}
+	setTimeRangeParams(params, timeRange)
+
+	return c.download(ctx, "/api/v1/products:export?"+params.Encode(), w)
+}
+
+// Order service methods
+
+func (c *RESTClient) CreateOrder(ctx context.Context, userID string, items []model.CreateOrderItem) (*model.Order, error) {
+	req := &model.CreateOrderRequest{
+		UserID: userID,
+		Items:  items,
+	}
+
+	var result struct {
+		Order *model.Order `json:"order"`
+	}
+
+	err := c.doRequest(ctx, "POST", "/api/v1/orders", req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Order, nil
+}
+
+func (c *RESTClient) GetOrder(ctx context.Context, id string) (*model.Order, error) {
+	var result struct {
+		Order *model.Order `json:"order"`
+	}
+
+	err := c.doRequest(ctx, "GET", "/api/v1/orders/"+url.PathEscape(id), nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Order, nil
+}
+
+func (c *RESTClient) ListOrders(ctx context.Context, userID string, page, pageSize int32) ([]model.Order, int32, int32, int32, error) {
+	params := url.Values{}
+	params.Set("user_id", userID)
+	params.Set("page", strconv.Itoa(int(page)))
+	params.Set("page_size", strconv.Itoa(int(pageSize)))
+
+	var result struct {
+		Orders     []model.Order `json:"orders"`
+		TotalCount int32         `json:"total_count"`
+		Page       int32         `json:"page"`
+		PageSize   int32         `json:"page_size"`
+	}
+
+	err := c.doRequest(ctx, "GET", "/api/v1/orders?"+params.Encode(), nil, &result)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	return result.Orders, result.TotalCount, result.Page, result.PageSize, nil
+}
+
+func (c *RESTClient) CancelOrder(ctx context.Context, id string) (*model.Order, error) {
+	var result struct {
+		Order *model.Order `json:"order"`
+	}
+
+	err := c.doRequest(ctx, "POST", "/api/v1/orders/"+url.PathEscape(id)+"/cancel", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Order, nil
+}
@@ -0,0 +1,317 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	authpb "go-grpc-rest-demo/api/gen/go/auth/v1"
+	categorypb "go-grpc-rest-demo/api/gen/go/category/v1"
+	orderpb "go-grpc-rest-demo/api/gen/go/order/v1"
+	productpb "go-grpc-rest-demo/api/gen/go/product/v1"
+	tenantpb "go-grpc-rest-demo/api/gen/go/tenant/v1"
+	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// RetryPolicy controls how calls that failed with a transient error are
+// retried. A call is only retried when it has no side effects or carries an
+// idempotency key the server replays the first response for.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt; 1 disables retries. gRPC caps
+	// it at 5.
+	MaxAttempts int
+
+	// The delay before retry n is InitialBackoff * BackoffMultiplier^(n-1),
+	// capped at MaxBackoff and randomized by up to Jitter (0 to 1) of itself
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	Jitter            float64
+
+	// RetryableCodes are the gRPC codes worth another attempt
+	RetryableCodes []codes.Code
+	// RetryableStatuses are the HTTP statuses worth another attempt; network
+	// errors are always retried
+	RetryableStatuses []int
+
+	// HedgingDelay, when positive, sends another attempt of read-only gRPC
+	// calls each time it passes without a response, up to MaxAttempts at once
+	HedgingDelay time.Duration
+}
+
+// DefaultRetryPolicy returns a policy retrying unavailable servers three times
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       4,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        2 * time.Second,
+		BackoffMultiplier: 2,
+		Jitter:            0.2,
+		RetryableCodes:    []codes.Code{codes.Unavailable},
+		RetryableStatuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+// backoff returns the delay before the given retry, counting from 1
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.BackoffMultiplier, float64(retry-1))
+	d = min(d, float64(p.MaxBackoff))
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+func (p RetryPolicy) retryableCode(code codes.Code) bool {
+	return slices.Contains(p.RetryableCodes, code)
+}
+
+func (p RetryPolicy) retryableStatus(statusCode int) bool {
+	return slices.Contains(p.RetryableStatuses, statusCode)
+}
+
+// retryable reports whether a REST request may be sent again
+func retryable(req *http.Request) bool {
+	if needsIdempotencyKey(req.Method) && req.Header.Get(idempotencyKeyHeader) == "" {
+		return false
+	}
+	// Streamed bodies cannot be read twice
+	return req.Body == nil || req.GetBody != nil
+}
+
+// clientServices are the gRPC services the client calls
+var clientServices = []grpc.ServiceDesc{
+	userpb.UserService_ServiceDesc,
+	productpb.ProductService_ServiceDesc,
+	orderpb.OrderService_ServiceDesc,
+	authpb.AuthService_ServiceDesc,
+	tenantpb.TenantService_ServiceDesc,
+	categorypb.CategoryService_ServiceDesc,
+}
+
+// serviceConfig returns the gRPC service config applying the policy. Methods
+// get a retry policy, which is safe for mutating unary calls because
+// idempotencyInterceptor gives them an idempotency key. That interceptor is
+// unary-only, so mutating client-streaming methods such as ImportProducts
+// carry no key and get a config without a retry policy, which overrides
+// their service's. With a hedging delay, read-only unary methods get a
+// hedging policy instead; grpc-go does not implement hedging yet, so
+// hedgingInterceptor carries it out.
+func (p RetryPolicy) serviceConfig() string {
+	if p.MaxAttempts < 2 {
+		return `{}`
+	}
+
+	retryableCodes := make([]string, len(p.RetryableCodes))
+	for i, code := range p.RetryableCodes {
+		retryableCodes[i] = strings.ToUpper(toSnakeCase(code.String()))
+	}
+
+	type name struct {
+		Service string `json:"service"`
+		Method  string `json:"method,omitempty"`
+	}
+	type methodConfig struct {
+		Name          []name         `json:"name"`
+		RetryPolicy   map[string]any `json:"retryPolicy,omitempty"`
+		HedgingPolicy map[string]any `json:"hedgingPolicy,omitempty"`
+	}
+
+	retry := methodConfig{RetryPolicy: map[string]any{
+		"maxAttempts":          p.MaxAttempts,
+		"initialBackoff":       durationJSON(p.InitialBackoff),
+		"maxBackoff":           durationJSON(p.MaxBackoff),
+		"backoffMultiplier":    p.BackoffMultiplier,
+		"retryableStatusCodes": retryableCodes,
+	}}
+	hedging := methodConfig{HedgingPolicy: map[string]any{
+		"maxAttempts":         p.MaxAttempts,
+		"hedgingDelay":        durationJSON(p.HedgingDelay),
+		"nonFatalStatusCodes": retryableCodes,
+	}}
+	var noRetry methodConfig
+	for _, service := range clientServices {
+		retry.Name = append(retry.Name, name{Service: service.ServiceName})
+		for _, stream := range service.Streams {
+			if stream.ClientStreams && isMutatingMethod(stream.StreamName) {
+				noRetry.Name = append(noRetry.Name, name{Service: service.ServiceName, Method: stream.StreamName})
+			}
+		}
+		if p.HedgingDelay <= 0 {
+			continue
+		}
+		for _, method := range service.Methods {
+			if !isMutatingMethod(method.MethodName) {
+				hedging.Name = append(hedging.Name, name{Service: service.ServiceName, Method: method.MethodName})
+			}
+		}
+	}
+
+	configs := []methodConfig{retry}
+	if len(noRetry.Name) > 0 {
+		configs = append(configs, noRetry)
+	}
+	if len(hedging.Name) > 0 {
+		configs = append(configs, hedging)
+	}
+	b, _ := json.Marshal(map[string]any{"methodConfig": configs})
+	return string(b)
+}
+
+// hedgingInterceptor carries out the hedging policy of read-only unary
+// calls. Each time the hedging delay passes without a response, or an
+// attempt fails with a retryable code, another attempt is sent; the first
+// response or non-retryable error wins and the other attempts are cancelled.
+func hedgingInterceptor(config *Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy := config.Retry
+		if policy.HedgingDelay <= 0 || policy.MaxAttempts < 2 || isMutatingMethod(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			reply proto.Message
+			err   error
+		}
+		results := make(chan result, policy.MaxAttempts)
+		attempts := 0
+		send := func(reason string) {
+			attempts++
+			if attempts > 1 {
+				config.logf("%s: sending hedged attempt %d (%s)", method, attempts, reason)
+			}
+			r := reply.(proto.Message).ProtoReflect().New().Interface()
+			go func() {
+				results <- result{reply: r, err: invoker(ctx, method, req, r, cc, opts...)}
+			}()
+		}
+
+		send("")
+		timer := time.NewTimer(policy.HedgingDelay)
+		defer timer.Stop()
+
+		var lastErr error
+		for pending := 1; pending > 0; {
+			select {
+			case <-timer.C:
+				if attempts < policy.MaxAttempts {
+					send(fmt.Sprintf("no response after %s", policy.HedgingDelay))
+					pending++
+					timer.Reset(policy.HedgingDelay)
+				}
+			case r := <-results:
+				pending--
+				if r.err == nil {
+					proto.Merge(reply.(proto.Message), r.reply)
+					return nil
+				}
+				if !policy.retryableCode(status.Code(r.err)) {
+					return r.err
+				}
+				lastErr = r.err
+				if attempts < policy.MaxAttempts {
+					send(fmt.Sprintf("attempt failed: %v", r.err))
+					pending++
+					timer.Reset(policy.HedgingDelay)
+				}
+			}
+		}
+		return lastErr
+	}
+}
+
+// attemptLogger logs every gRPC attempt after the first along with the error
+// that caused it. The retries themselves happen inside grpc-go, so attempts
+// are observed through a stats handler; attemptUnaryInterceptor and
+// attemptStreamInterceptor give each call the state the handler counts in.
+type attemptLogger struct {
+	config *Config
+}
+
+type attemptsKey struct{}
+
+// callAttempts counts the attempts of one call
+type callAttempts struct {
+	count   int
+	lastErr error
+}
+
+func attemptUnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(context.WithValue(ctx, attemptsKey{}, &callAttempts{}), method, req, reply, cc, opts...)
+	}
+}
+
+func attemptStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(context.WithValue(ctx, attemptsKey{}, &callAttempts{}), desc, cc, method, opts...)
+	}
+}
+
+type attemptMethodKey struct{}
+
+func (l *attemptLogger) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, attemptMethodKey{}, info.FullMethodName)
+}
+
+func (l *attemptLogger) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if !s.IsClient() {
+		return
+	}
+	attempts, ok := ctx.Value(attemptsKey{}).(*callAttempts)
+	if !ok {
+		return
+	}
+	switch s := s.(type) {
+	case *stats.Begin:
+		attempts.count++
+		if attempts.count > 1 {
+			reason := fmt.Sprintf("previous attempt failed: %v", attempts.lastErr)
+			if s.IsTransparentRetryAttempt {
+				reason = "previous attempt never reached the server"
+			}
+			l.config.logf("%s: attempt %d (%s)", ctx.Value(attemptMethodKey{}), attempts.count, reason)
+		}
+	case *stats.End:
+		attempts.lastErr = s.Error
+	}
+}
+
+func (l *attemptLogger) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (l *attemptLogger) HandleConn(ctx context.Context, s stats.ConnStats) {}
+
+// durationJSON formats d the way service configs expect, e.g. "0.1s"
+func durationJSON(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
+
+// toSnakeCase turns a code name such as ResourceExhausted into
+// resource_exhausted
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
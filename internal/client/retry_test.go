@@ -0,0 +1,301 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	productpb "go-grpc-rest-demo/api/gen/go/product/v1"
+	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type RetryTestSuite struct {
+	suite.Suite
+	config *Config
+}
+
+func (suite *RetryTestSuite) SetupTest() {
+	suite.config = DefaultConfig()
+	suite.config.Timeout = 5 * time.Second
+	suite.config.Retry.InitialBackoff = time.Millisecond
+	suite.config.Retry.MaxBackoff = 5 * time.Millisecond
+}
+
+func (suite *RetryTestSuite) TestBackoffGrowsUpToMax() {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, BackoffMultiplier: 2}
+
+	assert.Equal(suite.T(), 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(suite.T(), 400*time.Millisecond, policy.backoff(3))
+	assert.Equal(suite.T(), time.Second, policy.backoff(10))
+
+	policy.Jitter = 0.5
+	for range 100 {
+		d := policy.backoff(2)
+		assert.GreaterOrEqual(suite.T(), d, 100*time.Millisecond)
+		assert.LessOrEqual(suite.T(), d, 300*time.Millisecond)
+	}
+}
+
+func (suite *RetryTestSuite) TestRESTRetriesUnavailableWithSameKey() {
+	var mu sync.Mutex
+	var keys, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		bodies = append(bodies, string(body))
+		if len(keys) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"user": map[string]any{"id": "1", "username": "alice"}})
+	}))
+	defer server.Close()
+	suite.config.RESTAddr = server.URL
+
+	c, _ := NewRESTClient(suite.config)
+	user, err := c.CreateUser(context.Background(), "alice", "alice@example.com", "Alice")
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "alice", user.Username)
+	assert.Len(suite.T(), keys, 3)
+	assert.NotEmpty(suite.T(), keys[0])
+	assert.Equal(suite.T(), keys[0], keys[2])
+	assert.Equal(suite.T(), bodies[0], bodies[2])
+}
+
+func (suite *RetryTestSuite) TestIdempotencyKeyCoversOneOperation() {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"user": map[string]any{"id": "1"}})
+	}))
+	defer server.Close()
+	suite.config.RESTAddr = server.URL
+	c, _ := NewRESTClient(suite.config)
+
+	for _, name := range []string{"alice", "bob"} {
+		_, err := c.CreateUser(context.Background(), name, name+"@example.com", name)
+		suite.Require().NoError(err)
+	}
+	_, err := c.CreateUser(WithIdempotencyKey(context.Background(), "key-1"), "carol", "carol@example.com", "Carol")
+	suite.Require().NoError(err)
+
+	suite.Require().Len(keys, 3)
+	assert.NotEqual(suite.T(), keys[0], keys[1], "each operation gets a key of its own")
+	assert.Equal(suite.T(), "key-1", keys[2])
+}
+
+func (suite *RetryTestSuite) TestRESTDoesNotRetry() {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/api/v1/users/1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	suite.config.RESTAddr = server.URL
+	c, _ := NewRESTClient(suite.config)
+
+	// Client errors are not transient
+	_, err := c.GetUser(context.Background(), "1")
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 1, calls)
+
+	// Streamed bodies cannot be sent twice
+	calls = 0
+	_, err = c.ImportProducts(context.Background(), "csv", io.MultiReader(strings.NewReader("name\n")))
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 1, calls)
+
+	// Retries are disabled with one attempt
+	calls = 0
+	suite.config.Retry.MaxAttempts = 1
+	_, err = c.GetUser(context.Background(), "2")
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 1, calls)
+}
+
+// flakyUserServer fails GetUser with Unavailable until it was called
+// failures times, and remembers the idempotency keys of CreateUser calls
+type flakyUserServer struct {
+	userpb.UnimplementedUserServiceServer
+	mu       sync.Mutex
+	failures int
+	calls    int
+	keys     []string
+}
+
+func (s *flakyUserServer) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.GetUserResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+	return &userpb.GetUserResponse{User: &userpb.User{Id: req.Id}}, nil
+}
+
+func (s *flakyUserServer) CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*userpb.CreateUserResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, md.Get(idempotencyKeyMetadata)...)
+	if len(s.keys) <= s.failures {
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+	return &userpb.CreateUserResponse{User: &userpb.User{Id: "1", Username: req.Username}}, nil
+}
+
+func (suite *RetryTestSuite) startGRPC(server *flakyUserServer) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	s := grpc.NewServer()
+	userpb.RegisterUserServiceServer(s, server)
+	go func() { _ = s.Serve(lis) }()
+	suite.T().Cleanup(s.Stop)
+	suite.config.GRPCAddr = lis.Addr().String()
+}
+
+func (suite *RetryTestSuite) TestGRPCRetriesFromServiceConfig() {
+	server := &flakyUserServer{failures: 2}
+	suite.startGRPC(server)
+
+	c, err := NewGRPCClient(suite.config)
+	suite.Require().NoError(err)
+	defer func() { _ = c.Close() }()
+
+	user, err := c.GetUser(context.Background(), "42")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "42", user.Id)
+	assert.Equal(suite.T(), 3, server.calls)
+
+	// Retries of mutating calls repeat the idempotency key
+	server.calls, server.failures = 0, 1
+	_, err = c.CreateUser(context.Background(), "alice", "alice@example.com", "Alice")
+	suite.Require().NoError(err)
+	suite.Require().Len(server.keys, 2)
+	assert.Equal(suite.T(), server.keys[0], server.keys[1])
+}
+
+// unavailableImportServer fails every ImportProducts call with Unavailable
+type unavailableImportServer struct {
+	productpb.UnimplementedProductServiceServer
+	calls atomic.Int32
+}
+
+func (s *unavailableImportServer) ImportProducts(stream productpb.ProductService_ImportProductsServer) error {
+	s.calls.Add(1)
+	return status.Error(codes.Unavailable, "try again")
+}
+
+func (suite *RetryTestSuite) TestGRPCDoesNotRetryMutatingStreams() {
+	assert.Contains(suite.T(), suite.config.Retry.serviceConfig(), `{"name":[{"service":"api.v1.ProductService","method":"ImportProducts"}]}`)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	server := &unavailableImportServer{}
+	s := grpc.NewServer()
+	productpb.RegisterProductServiceServer(s, server)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	suite.config.GRPCAddr = lis.Addr().String()
+
+	c, err := NewGRPCClient(suite.config)
+	suite.Require().NoError(err)
+	defer func() { _ = c.Close() }()
+
+	_, err = c.ImportProducts(context.Background(), "csv", strings.NewReader("name,price\nPen,1\n"))
+	assert.Equal(suite.T(), codes.Unavailable, status.Code(err))
+	assert.Equal(suite.T(), int32(1), server.calls.Load())
+}
+
+func (suite *RetryTestSuite) TestGRPCGivesUpAfterMaxAttempts() {
+	server := &flakyUserServer{failures: 10}
+	suite.startGRPC(server)
+	suite.config.Retry.MaxAttempts = 2
+
+	c, err := NewGRPCClient(suite.config)
+	suite.Require().NoError(err)
+	defer func() { _ = c.Close() }()
+
+	_, err = c.GetUser(context.Background(), "42")
+	assert.Equal(suite.T(), codes.Unavailable, status.Code(err))
+	assert.Equal(suite.T(), 2, server.calls)
+}
+
+func (suite *RetryTestSuite) TestServiceConfigWithHedgingIsValid() {
+	suite.config.Retry.HedgingDelay = 50 * time.Millisecond
+	config := suite.config.Retry.serviceConfig()
+
+	assert.Contains(suite.T(), config, `"retryableStatusCodes":["UNAVAILABLE"]`)
+	assert.Contains(suite.T(), config, `{"service":"api.v1.UserService","method":"GetUser"}`)
+	assert.NotContains(suite.T(), config, `"method":"CreateUser"`)
+
+	c, err := NewGRPCClient(suite.config)
+	suite.Require().NoError(err)
+	_ = c.Close()
+}
+
+func (suite *RetryTestSuite) TestHedgingUsesFirstResponse() {
+	suite.config.Retry.HedgingDelay = 10 * time.Millisecond
+	interceptor := hedgingInterceptor(suite.config)
+
+	var mu sync.Mutex
+	attempts := 0
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n == 1 {
+			// The first attempt hangs until the hedged one wins
+			<-ctx.Done()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		reply.(*userpb.GetUserResponse).User = &userpb.User{Id: "42"}
+		return nil
+	}
+
+	reply := &userpb.GetUserResponse{}
+	err := interceptor(context.Background(), "/api.v1.UserService/GetUser", &userpb.GetUserRequest{Id: "42"}, reply, nil, invoker)
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "42", reply.User.Id)
+	assert.Equal(suite.T(), 2, attempts)
+
+	// Non-retryable errors end the call at once
+	attempts = 0
+	failing := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempts++
+		return status.Error(codes.NotFound, "no such user")
+	}
+	err = interceptor(context.Background(), "/api.v1.UserService/GetUser", &userpb.GetUserRequest{}, &userpb.GetUserResponse{}, nil, failing)
+	assert.Equal(suite.T(), codes.NotFound, status.Code(err))
+	assert.Equal(suite.T(), 1, attempts)
+}
+
+func TestRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryTestSuite))
+}
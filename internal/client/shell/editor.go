@@ -0,0 +1,271 @@
+// Package shell holds the pieces of the interactive CLI shell that do not
+// depend on its commands: a line editor with history and tab completion,
+// and the parsing of command lines with variables and JSON payloads.
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine when Ctrl-C discards the line
+var ErrInterrupted = errors.New("interrupted")
+
+// LineEditor reads lines with cursor movement, history and tab completion
+// when its input is a terminal, and plain lines without a prompt otherwise.
+type LineEditor struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// fd is the terminal to put into raw mode while editing, or -1
+	fd          int
+	interactive bool
+
+	// Complete returns the candidates for the word before the cursor, given
+	// the line up to the cursor
+	Complete func(head string) []string
+
+	history []string
+}
+
+// NewLineEditor reads from in, which is edited interactively when it is a
+// terminal, and echoes to out
+func NewLineEditor(in *os.File, out io.Writer) *LineEditor {
+	fd := int(in.Fd())
+	return &LineEditor{in: bufio.NewReader(in), out: out, fd: fd, interactive: isTerminal(fd)}
+}
+
+// newEditor edits lines from in without touching any terminal mode
+func newEditor(in io.Reader, out io.Writer) *LineEditor {
+	return &LineEditor{in: bufio.NewReader(in), out: out, fd: -1, interactive: true}
+}
+
+// Interactive reports whether lines are edited on a terminal
+func (e *LineEditor) Interactive() bool {
+	return e.interactive
+}
+
+// History returns the remembered lines, oldest first
+func (e *LineEditor) History() []string {
+	return append([]string(nil), e.history...)
+}
+
+// AddHistory remembers a line unless it is empty or repeats the last one
+func (e *LineEditor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+}
+
+// ReadLine shows prompt and returns the line entered. It returns io.EOF at
+// the end of input or on Ctrl-D on an empty line, and ErrInterrupted on
+// Ctrl-C.
+func (e *LineEditor) ReadLine(prompt string) (string, error) {
+	if !e.interactive {
+		line, err := e.in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	if e.fd >= 0 {
+		restore, err := makeRaw(e.fd)
+		if err != nil {
+			return "", fmt.Errorf("failed to set up the terminal: %v", err)
+		}
+		defer restore()
+	}
+	return e.edit(prompt)
+}
+
+// lineState is the line being edited and the cursor position in it
+type lineState struct {
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+func (e *LineEditor) edit(prompt string) (string, error) {
+	s := &lineState{prompt: prompt}
+	// historyIndex is len(history) while editing a new line, whose text is
+	// kept in pending while browsing older lines
+	historyIndex := len(e.history)
+	var pending []rune
+	e.refresh(s)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(s.buf), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case 4: // Ctrl-D
+			if len(s.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			s.deleteAt(s.pos)
+		case 1: // Ctrl-A
+			s.pos = 0
+		case 5: // Ctrl-E
+			s.pos = len(s.buf)
+		case 2: // Ctrl-B
+			s.move(-1)
+		case 6: // Ctrl-F
+			s.move(1)
+		case 127, 8: // Backspace
+			if s.pos > 0 {
+				s.pos--
+				s.deleteAt(s.pos)
+			}
+		case 11: // Ctrl-K
+			s.buf = s.buf[:s.pos]
+		case 21: // Ctrl-U
+			s.buf = append([]rune(nil), s.buf[s.pos:]...)
+			s.pos = 0
+		case 23: // Ctrl-W
+			start := s.pos
+			for start > 0 && unicode.IsSpace(s.buf[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(s.buf[start-1]) {
+				start--
+			}
+			s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+			s.pos = start
+		case '\t':
+			e.complete(s)
+		case 27: // Escape sequences of the arrow, Home, End and Delete keys
+			switch e.readEscape() {
+			case "[A", "OA":
+				if historyIndex > 0 {
+					if historyIndex == len(e.history) {
+						pending = s.buf
+					}
+					historyIndex--
+					s.set(e.history[historyIndex])
+				}
+			case "[B", "OB":
+				if historyIndex < len(e.history) {
+					historyIndex++
+					if historyIndex == len(e.history) {
+						s.buf, s.pos = pending, len(pending)
+					} else {
+						s.set(e.history[historyIndex])
+					}
+				}
+			case "[C", "OC":
+				s.move(1)
+			case "[D", "OD":
+				s.move(-1)
+			case "[H", "OH", "[1~", "[7~":
+				s.pos = 0
+			case "[F", "OF", "[4~", "[8~":
+				s.pos = len(s.buf)
+			case "[3~":
+				s.deleteAt(s.pos)
+			}
+		default:
+			if unicode.IsPrint(r) {
+				s.buf = append(s.buf[:s.pos], append([]rune{r}, s.buf[s.pos:]...)...)
+				s.pos++
+			}
+		}
+		e.refresh(s)
+	}
+}
+
+// readEscape reads the rest of an escape sequence such as "[A" or "[3~"
+func (e *LineEditor) readEscape() string {
+	first, _, err := e.in.ReadRune()
+	if err != nil || (first != '[' && first != 'O') {
+		return ""
+	}
+	seq := []rune{first}
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, r)
+		// Parameters are digits and semicolons; anything else ends the sequence
+		if !unicode.IsDigit(r) && r != ';' {
+			return string(seq)
+		}
+	}
+}
+
+// complete replaces the word before the cursor with its only candidate, or
+// with the prefix all candidates share, listing them when that adds nothing
+func (e *LineEditor) complete(s *lineState) {
+	if e.Complete == nil {
+		return
+	}
+	head := string(s.buf[:s.pos])
+	candidates := e.Complete(head)
+	if len(candidates) == 0 {
+		return
+	}
+
+	start := strings.LastIndexFunc(head, unicode.IsSpace) + 1
+	word := head[start:]
+	replacement := candidates[0] + " "
+	if len(candidates) > 1 {
+		replacement = commonPrefix(candidates)
+		if len(replacement) <= len(word) {
+			fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+			return
+		}
+	}
+
+	tail := s.buf[s.pos:]
+	s.buf = append([]rune(head[:start]+replacement), tail...)
+	s.pos = len([]rune(head[:start] + replacement))
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// refresh redraws the line and puts the cursor back in place
+func (e *LineEditor) refresh(s *lineState) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", s.prompt, string(s.buf))
+	if back := len(s.buf) - s.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func (s *lineState) set(line string) {
+	s.buf = []rune(line)
+	s.pos = len(s.buf)
+}
+
+func (s *lineState) move(delta int) {
+	s.pos = min(max(s.pos+delta, 0), len(s.buf))
+}
+
+func (s *lineState) deleteAt(pos int) {
+	if pos < len(s.buf) {
+		s.buf = append(s.buf[:pos], s.buf[pos+1:]...)
+	}
+}
@@ -0,0 +1,164 @@
+package shell
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShellTestSuite struct {
+	suite.Suite
+	vars map[string]string
+}
+
+func (suite *ShellTestSuite) SetupTest() {
+	suite.vars = map[string]string{"last_user": "7", "name": "Ann Lee"}
+}
+
+func (suite *ShellTestSuite) lookup(name string) (string, bool) {
+	value, ok := suite.vars[name]
+	return value, ok
+}
+
+// edit types keys into a new editor and returns the lines it read
+func (suite *ShellTestSuite) edit(e *LineEditor, keys string) []string {
+	e.in.Reset(strings.NewReader(keys))
+	var lines []string
+	for {
+		line, err := e.ReadLine("> ")
+		if err == io.EOF {
+			return lines
+		}
+		if err == ErrInterrupted {
+			continue
+		}
+		suite.Require().NoError(err)
+		lines = append(lines, line)
+		e.AddHistory(line)
+	}
+}
+
+func (suite *ShellTestSuite) TestEditing() {
+	e := newEditor(nil, &bytes.Buffer{})
+	lines := suite.edit(e, ""+
+		"user get\r"+
+		"acd\x1b[D\x1b[Db\x1b[F!\r"+ // arrows move the cursor
+		"hello world\x17there\r"+ // Ctrl-W deletes a word
+		"abc\x01\x04\x05\x7fz\r"+ // Ctrl-A, Ctrl-D deletes, Ctrl-E, Backspace
+		"\x1b[A\x1b[A\x1b[A\r"+ // up three lines of history
+		"new\x1b[A\x1b[Bx\r"+ // down returns to the line being typed
+		"drop\x03"+ // Ctrl-C discards the line
+		"\x04") // Ctrl-D on an empty line ends input
+	assert.Equal(suite.T(), []string{"user get", "abcd!", "hello there", "bz", "abcd!", "newx"}, lines)
+	assert.Equal(suite.T(), []string{"user get", "abcd!", "hello there", "bz", "abcd!", "newx"}, e.History())
+}
+
+func (suite *ShellTestSuite) TestInterrupt() {
+	e := newEditor(strings.NewReader("partial\x03"), &bytes.Buffer{})
+	_, err := e.ReadLine("> ")
+	assert.ErrorIs(suite.T(), err, ErrInterrupted)
+}
+
+func (suite *ShellTestSuite) TestCompletion() {
+	out := &bytes.Buffer{}
+	e := newEditor(nil, out)
+	e.Complete = func(head string) []string {
+		switch head {
+		case "us":
+			return []string{"user"}
+		case "user get --":
+			return []string{"--id", "--idempotency-key"}
+		case "product l":
+			return []string{"list", "login"}
+		}
+		return nil
+	}
+	lines := suite.edit(e, "us\tget --\t\r"+"product l\t\r")
+	assert.Equal(suite.T(), []string{"user get --id", "product l"}, lines)
+	assert.Contains(suite.T(), out.String(), "\r\nlist  login\r\n")
+}
+
+func (suite *ShellTestSuite) TestPlainInput() {
+	e := newEditor(nil, &bytes.Buffer{})
+	e.interactive = false
+	lines := suite.edit(e, "user list\r\nuser get 1")
+	assert.Equal(suite.T(), []string{"user list", "user get 1"}, lines)
+}
+
+func (suite *ShellTestSuite) TestSplitWords() {
+	cases := map[string][]string{
+		`user get 1`:                         {"user", "get", "1"},
+		`  user   get  $last_user `:          {"user", "get", "7"},
+		`user create --full-name "$name"`:    {"user", "create", "--full-name", "Ann Lee"},
+		`echo '$name' "\$name" a\ b ${name}`: {"echo", "$name", "$name", "a b", "Ann Lee"},
+		`x -o 'jsonpath={$.id}' {$.id} $ ''`: {"x", "-o", "jsonpath={$.id}", "{$.id}", "$", ""},
+		`x id=${last_user}0`:                 {"x", "id=70"},
+	}
+	for line, want := range cases {
+		words, err := SplitWords(line, suite.lookup)
+		suite.Require().NoError(err, line)
+		assert.Equal(suite.T(), want, words, line)
+	}
+
+	for _, line := range []string{`user get $missing`, `a 'b`, `a "b`} {
+		_, err := SplitWords(line, suite.lookup)
+		assert.Error(suite.T(), err, line)
+	}
+
+	assert.True(suite.T(), IsName("last_user2"))
+	for _, name := range []string{"", "2x", "a-b"} {
+		assert.False(suite.T(), IsName(name), name)
+	}
+}
+
+func (suite *ShellTestSuite) TestPayload() {
+	command, payload, found := CutPayload(`user create -o 'go-template={{.id}}' {"username":`)
+	suite.Require().True(found)
+	assert.Equal(suite.T(), `user create -o 'go-template={{.id}}' `, command)
+	assert.False(suite.T(), PayloadComplete(payload))
+	payload += "\n" + `"ann", "note": "}", "tags": ["a", "b"]}`
+	assert.True(suite.T(), PayloadComplete(payload))
+
+	_, _, found = CutPayload(`user list -o jsonpath={.users}`)
+	assert.False(suite.T(), found)
+
+	flags, err := PayloadFlags(`{"full_name": "$name", "active": false, "quantity": 3, "item": ["$last_user:2", "9:1"]}`, suite.lookup)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []string{"--active=false", "--full-name=Ann Lee", "--item=7:2", "--item=9:1", "--quantity=3"}, flags)
+
+	for _, payload := range []string{`{"a": {"b": 1}}`, `{"a": null}`, `{"a": 1} x`, `[1]`, `{"a": "$missing"}`} {
+		_, err := PayloadFlags(payload, suite.lookup)
+		assert.Error(suite.T(), err, payload)
+	}
+}
+
+func (suite *ShellTestSuite) TestHasSecret() {
+	for _, line := range []string{
+		`auth login --username ann --password hunter2`,
+		`user set-password --id 7 --password=hunter2`,
+		`user change-password --id 7 --current-password a --new-password b`,
+		`auth refresh --refresh-token $refresh`,
+		`--token abc user list`,
+		`user set-password --id 7 {"password": "hunter2"}`,
+		`user change-password {"id": "7", "current_password": "a", "new_password": "b"}`,
+		`user set-password {"password": {"nested": 1}}`,
+		`auth login --password 'unterminated`,
+	} {
+		assert.True(suite.T(), HasSecret(line), line)
+	}
+	for _, line := range []string{
+		`user list --filter "name:password"`,
+		`user create {"username": "ann", "email": "$email"}`,
+		`user get --id $last_user`,
+	} {
+		assert.False(suite.T(), HasSecret(line), line)
+	}
+}
+
+func TestShellTestSuite(t *testing.T) {
+	suite.Run(t, new(ShellTestSuite))
+}
@@ -0,0 +1,297 @@
+package shell
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Lookup returns the value of a shell variable
+type Lookup func(name string) (string, bool)
+
+// SplitWords splits a command line into words the way a POSIX shell does
+// for the common cases: words are separated by spaces, single quotes keep
+// text as is, and double quotes and backslashes escape spaces. $name and
+// ${name} outside single quotes expand to variables; a $ not followed by a
+// name, as in "{$.id}", stays as is.
+func SplitWords(line string, lookup Lookup) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	runes := []rune(line)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+			continue
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ' in %q", line)
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+		case r == '"':
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '"'; end++ {
+				switch {
+				case runes[end] == '\\' && end+1 < len(runes) && strings.ContainsRune(`"\$`, runes[end+1]):
+					end++
+					word.WriteRune(runes[end])
+				case runes[end] == '$':
+					n, err := expand(runes[end:], lookup, &word)
+					if err != nil {
+						return nil, err
+					}
+					end += n - 1
+				default:
+					word.WriteRune(runes[end])
+				}
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf(`unterminated " in %q`, line)
+			}
+			i = end
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+		case r == '$':
+			n, err := expand(runes[i:], lookup, &word)
+			if err != nil {
+				return nil, err
+			}
+			i += n - 1
+		default:
+			word.WriteRune(r)
+		}
+		inWord = true
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Expand replaces the $name and ${name} variables of s
+func Expand(s string, lookup Lookup) (string, error) {
+	var out strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '$' {
+			out.WriteRune(runes[i])
+			continue
+		}
+		n, err := expand(runes[i:], lookup, &out)
+		if err != nil {
+			return "", err
+		}
+		i += n - 1
+	}
+	return out.String(), nil
+}
+
+// expand writes the variable at the start of runes, which begin with $, and
+// returns how many runes it took
+func expand(runes []rune, lookup Lookup, out *strings.Builder) (int, error) {
+	braced := len(runes) > 1 && runes[1] == '{'
+	start := 1
+	if braced {
+		start = 2
+	}
+	end := start
+	for end < len(runes) && isNameRune(runes[end], end == start) {
+		end++
+	}
+	if end == start || (braced && (end >= len(runes) || runes[end] != '}')) {
+		out.WriteRune('$')
+		return 1, nil
+	}
+
+	name := string(runes[start:end])
+	value, ok := lookup(name)
+	if !ok {
+		return 0, fmt.Errorf("undefined variable $%s", name)
+	}
+	out.WriteString(value)
+	if braced {
+		end++
+	}
+	return end, nil
+}
+
+// IsName reports whether name can be written as $name
+func IsName(name string) bool {
+	for i, r := range name {
+		if !isNameRune(r, i == 0) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+func isNameRune(r rune, first bool) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (!first && r >= '0' && r <= '9')
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// CutPayload splits a line at a JSON object starting a word outside quotes,
+// as in `user create {"username": "ann"}`, into the command and the payload
+func CutPayload(line string) (command, payload string, found bool) {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '{' && (i == 0 || unicode.IsSpace(rune(line[i-1]))):
+			return line[:i], line[i:], true
+		}
+	}
+	return line, "", false
+}
+
+// PayloadComplete reports whether the braces of a payload are balanced, so
+// that no more lines of it need to be read
+func PayloadComplete(payload string) bool {
+	depth := 0
+	inString, escaped := false, false
+	for _, r := range payload {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case r == '"':
+			inString = !inString
+		case inString:
+		case r == '{' || r == '[':
+			depth++
+		case r == '}' || r == ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// PayloadFlags turns a JSON object into command flags: each field becomes
+// the flag of its name with "_" written as "-", lists repeat the flag, and
+// variables in strings are expanded. So {"full_name": "Ann", "item": ["1:2",
+// "3:1"]} gives --full-name Ann --item 1:2 --item 3:1.
+func PayloadFlags(payload string, lookup Lookup) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(payload)))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload: %v", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid JSON payload: unexpected text after the object")
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var flags []string
+	for _, name := range names {
+		flag := "--" + strings.ReplaceAll(name, "_", "-")
+		values, ok := fields[name].([]any)
+		if !ok {
+			values = []any{fields[name]}
+		}
+		for _, value := range values {
+			text, err := payloadValue(value, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of %s: %v", name, err)
+			}
+			flags = append(flags, flag+"="+text)
+		}
+	}
+	return flags, nil
+}
+
+func payloadValue(value any, lookup Lookup) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return Expand(v, lookup)
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case nil:
+		return "", fmt.Errorf("null is not a flag value")
+	default:
+		return "", fmt.Errorf("nested objects and lists are not flag values")
+	}
+}
+
+// secretSuffixes end the names of flags, and payload fields, that take a
+// password or token
+var secretSuffixes = []string{"password", "token"}
+
+// HasSecret reports whether a command line passes a password or token, as
+// in `auth login --password pw` or `user set-password {"password": "pw"}`,
+// so the shell can keep it out of its history. Variables are not expanded;
+// a line that does not parse is checked as plain text.
+func HasSecret(line string) bool {
+	keep := func(name string) (string, bool) { return "", true }
+	command, payload, found := CutPayload(line)
+	words, err := SplitWords(command, keep)
+	if err != nil {
+		return mentionsSecret(line)
+	}
+	if found {
+		flags, err := PayloadFlags(payload, keep)
+		if err != nil {
+			return mentionsSecret(payload) || slices.ContainsFunc(words, isSecretFlag)
+		}
+		words = append(words, flags...)
+	}
+	return slices.ContainsFunc(words, isSecretFlag)
+}
+
+// isSecretFlag reports whether word is a flag such as --password or
+// --refresh-token=value
+func isSecretFlag(word string) bool {
+	name, ok := strings.CutPrefix(word, "--")
+	if !ok {
+		return false
+	}
+	name, _, _ = strings.Cut(name, "=")
+	return slices.ContainsFunc(secretSuffixes, func(suffix string) bool {
+		return strings.HasSuffix(name, suffix)
+	})
+}
+
+func mentionsSecret(text string) bool {
+	text = strings.ToLower(text)
+	return slices.ContainsFunc(secretSuffixes, func(suffix string) bool {
+		return strings.Contains(text, suffix)
+	})
+}
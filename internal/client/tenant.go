@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// tenantHeader names the tenant of REST requests
+	tenantHeader = "X-Tenant-ID"
+	// tenantMetadata names the tenant of gRPC calls
+	tenantMetadata = "x-tenant-id"
+)
+
+// withTenant adds the configured tenant to the outgoing metadata of ctx
+func (c *Config) withTenant(ctx context.Context) context.Context {
+	if c.Tenant == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, tenantMetadata, c.Tenant)
+}
+
+// tenantUnaryInterceptor sends the configured tenant with every unary call
+func tenantUnaryInterceptor(config *Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(config.withTenant(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// tenantStreamInterceptor sends the configured tenant with every streaming call
+func tenantStreamInterceptor(config *Config) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(config.withTenant(ctx), desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/model"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Timestamped is implemented by the User and Product messages returned by
+// the gRPC client.
+type Timestamped interface {
+	GetCreateTime() *timestamppb.Timestamp
+	GetUpdateTime() *timestamppb.Timestamp
+	GetCreatedAt() string
+	GetUpdatedAt() string
+}
+
+// CreateTime returns when a gRPC resource was created. Servers that predate
+// the Timestamp fields only send the deprecated RFC 3339 string, which is
+// parsed instead; the zero time is returned when neither is usable.
+func CreateTime(m Timestamped) time.Time {
+	return messageTime(m.GetCreateTime(), m.GetCreatedAt())
+}
+
+// UpdateTime returns when a gRPC resource was last updated, like CreateTime
+func UpdateTime(m Timestamped) time.Time {
+	return messageTime(m.GetUpdateTime(), m.GetUpdatedAt())
+}
+
+func messageTime(ts *timestamppb.Timestamp, legacy string) time.Time {
+	if ts != nil && ts.IsValid() {
+		return ts.AsTime()
+	}
+	t, err := time.Parse(time.RFC3339Nano, legacy)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// timesToUTC converts every time reachable from v in place. REST responses
+// carry the server's UTC offset while protobuf timestamps are always UTC, so
+// this keeps the values of both transports equal.
+func timesToUTC(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			timesToUTC(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			timesToUTC(v.Index(i))
+		}
+	case reflect.Struct:
+		if !v.CanAddr() {
+			return
+		}
+		if t, ok := v.Addr().Interface().(*time.Time); ok {
+			*t = t.UTC()
+			return
+		}
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				timesToUTC(v.Field(i))
+			}
+		}
+	}
+}
+
+func timeRangeToPB(r model.TimeRange) (createdAfter, createdBefore, updatedAfter, updatedBefore *timestamppb.Timestamp) {
+	return timestampToPB(r.CreatedAfter), timestampToPB(r.CreatedBefore), timestampToPB(r.UpdatedAfter), timestampToPB(r.UpdatedBefore)
+}
+
+func timestampToPB(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func setTimeRangeParams(params url.Values, r model.TimeRange) {
+	bounds := map[string]*time.Time{
+		"created_after":  r.CreatedAfter,
+		"created_before": r.CreatedBefore,
+		"updated_after":  r.UpdatedAfter,
+		"updated_before": r.UpdatedBefore,
+	}
+	for name, t := range bounds {
+		if t != nil {
+			params.Set(name, t.Format(time.RFC3339Nano))
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package client
+
+import (
+	"context"
+	"io"
+
+	authpb "go-grpc-rest-demo/api/gen/go/auth/v1"
+	moneypb "go-grpc-rest-demo/api/gen/go/money/v1"
+	orderpb "go-grpc-rest-demo/api/gen/go/order/v1"
+	productpb "go-grpc-rest-demo/api/gen/go/product/v1"
+	tenantpb "go-grpc-rest-demo/api/gen/go/tenant/v1"
+	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
+	"go-grpc-rest-demo/internal/server/model"
+)
+
+// transport is the method set the client API is built on. RESTClient
+// implements it directly; grpcTransport adapts GRPCClient by converting its
+// messages to the model types.
+type transport interface {
+	CreateUser(ctx context.Context, username, email, fullName string) (*model.User, error)
+	GetUser(ctx context.Context, id string) (*model.User, error)
+	UpdateUser(ctx context.Context, id string, username, email, fullName *string, isActive *bool) (*model.User, error)
+	DeleteUser(ctx context.Context, id string) error
+	ListUsers(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) ([]model.User, int32, int32, int32, error)
+	CheckUserPolicy(ctx context.Context, repair bool) (*model.UserPolicyReport, error)
+	VerifyEmail(ctx context.Context, token string) (*model.User, error)
+	ResendVerification(ctx context.Context, id string) error
+	SetPassword(ctx context.Context, id, password, setupToken string) error
+	ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error
+	ExportUsers(ctx context.Context, format, orderBy string, filter *string, timeRange model.TimeRange, w io.Writer) (int64, error)
+
+	Login(ctx context.Context, username, password string) (*model.AuthTokens, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*model.AuthTokens, error)
+
+	CreateTenant(ctx context.Context, id, displayName string) (*model.Tenant, error)
+	GetTenant(ctx context.Context, id string) (*model.Tenant, error)
+	ListTenants(ctx context.Context) ([]model.Tenant, error)
+	DeleteTenant(ctx context.Context, id string) error
+
+	CreateProduct(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*model.Product, error)
+	GetProduct(ctx context.Context, id string) (*model.Product, error)
+	UpdateProduct(ctx context.Context, id string, name, description, category *string, price *model.Money, quantity *int32) (*model.Product, error)
+	DeleteProduct(ctx context.Context, id string) error
+	SearchProducts(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) ([]model.Product, int32, int32, int32, error)
+	ImportProducts(ctx context.Context, format string, data io.Reader) (*model.ImportProductsSummary, error)
+	ExportProducts(ctx context.Context, format string, query, category, categoryID, filter *string, includeDescendants bool, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, w io.Writer) (int64, error)
+
+	CreateOrder(ctx context.Context, userID string, items []model.CreateOrderItem) (*model.Order, error)
+	GetOrder(ctx context.Context, id string) (*model.Order, error)
+	ListOrders(ctx context.Context, userID string, page, pageSize int32) ([]model.Order, int32, int32, int32, error)
+	CancelOrder(ctx context.Context, id string) (*model.Order, error)
+}
+
+var (
+	_ transport = (*RESTClient)(nil)
+	_ transport = grpcTransport{}
+)
+
+// grpcTransport implements transport over a GRPCClient
+type grpcTransport struct {
+	c *GRPCClient
+}
+
+func (t grpcTransport) CreateUser(ctx context.Context, username, email, fullName string) (*model.User, error) {
+	user, err := t.c.CreateUser(ctx, username, email, fullName)
+	return userFromPB(user), errorFromGRPC(err)
+}
+
+func (t grpcTransport) GetUser(ctx context.Context, id string) (*model.User, error) {
+	user, err := t.c.GetUser(ctx, id)
+	return userFromPB(user), errorFromGRPC(err)
+}
+
+func (t grpcTransport) UpdateUser(ctx context.Context, id string, username, email, fullName *string, isActive *bool) (*model.User, error) {
+	user, err := t.c.UpdateUser(ctx, id, username, email, fullName, isActive)
+	return userFromPB(user), errorFromGRPC(err)
+}
+
+func (t grpcTransport) DeleteUser(ctx context.Context, id string) error {
+	return errorFromGRPC(t.c.DeleteUser(ctx, id))
+}
+
+func (t grpcTransport) ListUsers(ctx context.Context, page, pageSize int32, orderBy string, filter *string, timeRange model.TimeRange) ([]model.User, int32, int32, int32, error) {
+	users, total, page, pageSize, err := t.c.ListUsers(ctx, page, pageSize, orderBy, filter, timeRange)
+	return mapSlice(users, userFromPB), total, page, pageSize, errorFromGRPC(err)
+}
+
+func (t grpcTransport) CheckUserPolicy(ctx context.Context, repair bool) (*model.UserPolicyReport, error) {
+	resp, err := t.c.CheckUserPolicy(ctx, repair)
+	if err != nil {
+		return nil, errorFromGRPC(err)
+	}
+	report := &model.UserPolicyReport{Checked: resp.Checked, Repaired: resp.Repaired}
+	for _, v := range resp.Violations {
+		report.Violations = append(report.Violations, model.UserPolicyViolation{
+			UserID:          v.UserId,
+			Field:           v.Field,
+			Value:           v.Value,
+			Description:     v.Description,
+			NormalizedValue: v.NormalizedValue,
+			Repaired:        v.Repaired,
+		})
+	}
+	return report, nil
+}
+
+func (t grpcTransport) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	user, err := t.c.VerifyEmail(ctx, token)
+	return userFromPB(user), errorFromGRPC(err)
+}
+
+func (t grpcTransport) ResendVerification(ctx context.Context, id string) error {
+	return errorFromGRPC(t.c.ResendVerification(ctx, id))
+}
+
+func (t grpcTransport) SetPassword(ctx context.Context, id, password, setupToken string) error {
+	return errorFromGRPC(t.c.SetPassword(ctx, id, password, setupToken))
+}
+
+func (t grpcTransport) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	return errorFromGRPC(t.c.ChangePassword(ctx, id, currentPassword, newPassword))
+}
+
+func (t grpcTransport) ExportUsers(ctx context.Context, format, orderBy string, filter *string, timeRange model.TimeRange, w io.Writer) (int64, error) {
+	n, err := t.c.ExportUsers(ctx, format, orderBy, filter, timeRange, w)
+	return n, errorFromGRPC(err)
+}
+
+func (t grpcTransport) Login(ctx context.Context, username, password string) (*model.AuthTokens, error) {
+	tokens, err := t.c.Login(ctx, username, password)
+	return authTokensFromPB(tokens), errorFromGRPC(err)
+}
+
+func (t grpcTransport) RefreshToken(ctx context.Context, refreshToken string) (*model.AuthTokens, error) {
+	tokens, err := t.c.RefreshToken(ctx, refreshToken)
+	return authTokensFromPB(tokens), errorFromGRPC(err)
+}
+
+func (t grpcTransport) CreateTenant(ctx context.Context, id, displayName string) (*model.Tenant, error) {
+	tenant, err := t.c.CreateTenant(ctx, id, displayName)
+	return tenantFromPB(tenant), errorFromGRPC(err)
+}
+
+func (t grpcTransport) GetTenant(ctx context.Context, id string) (*model.Tenant, error) {
+	tenant, err := t.c.GetTenant(ctx, id)
+	return tenantFromPB(tenant), errorFromGRPC(err)
+}
+
+func (t grpcTransport) ListTenants(ctx context.Context) ([]model.Tenant, error) {
+	tenants, err := t.c.ListTenants(ctx)
+	return mapSlice(tenants, tenantFromPB), errorFromGRPC(err)
+}
+
+func (t grpcTransport) DeleteTenant(ctx context.Context, id string) error {
+	return errorFromGRPC(t.c.DeleteTenant(ctx, id))
+}
+
+func (t grpcTransport) CreateProduct(ctx context.Context, name, description, category string, price model.Money, quantity int32) (*model.Product, error) {
+	product, err := t.c.CreateProduct(ctx, name, description, category, price, quantity)
+	return productFromPB(product), errorFromGRPC(err)
+}
+
+func (t grpcTransport) GetProduct(ctx context.Context, id string) (*model.Product, error) {
+	product, err := t.c.GetProduct(ctx, id)
+	return productFromPB(product), errorFromGRPC(err)
+}
+
+func (t grpcTransport) UpdateProduct(ctx context.Context, id string, name, description, category *string, price *model.Money, quantity *int32) (*model.Product, error) {
+	product, err := t.c.UpdateProduct(ctx, id, name, description, category, price, quantity)
+	return productFromPB(product), errorFromGRPC(err)
+}
+
+func (t grpcTransport) DeleteProduct(ctx context.Context, id string) error {
+	return errorFromGRPC(t.c.DeleteProduct(ctx, id))
+}
+
+func (t grpcTransport) SearchProducts(ctx context.Context, query, category, filter *string, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, page, pageSize int32) ([]model.Product, int32, int32, int32, error) {
+	products, total, page, pageSize, err := t.c.SearchProducts(ctx, query, category, filter, minPrice, maxPrice, timeRange, orderBy, page, pageSize)
+	return mapSlice(products, productFromPB), total, page, pageSize, errorFromGRPC(err)
+}
+
+func (t grpcTransport) ImportProducts(ctx context.Context, format string, data io.Reader) (*model.ImportProductsSummary, error) {
+	resp, err := t.c.ImportProducts(ctx, format, data)
+	if err != nil {
+		return nil, errorFromGRPC(err)
+	}
+	summary := &model.ImportProductsSummary{Created: resp.Created, Updated: resp.Updated, Failed: resp.Failed}
+	for _, f := range resp.Failures {
+		summary.Failures = append(summary.Failures, model.ImportFailure{Line: f.Line, Message: f.Message})
+	}
+	return summary, nil
+}
+
+func (t grpcTransport) ExportProducts(ctx context.Context, format string, query, category, categoryID, filter *string, includeDescendants bool, minPrice, maxPrice *float64, timeRange model.TimeRange, orderBy string, w io.Writer) (int64, error) {
+	n, err := t.c.ExportProducts(ctx, format, query, category, categoryID, filter, includeDescendants, minPrice, maxPrice, timeRange, orderBy, w)
+	return n, errorFromGRPC(err)
+}
+
+func (t grpcTransport) CreateOrder(ctx context.Context, userID string, items []model.CreateOrderItem) (*model.Order, error) {
+	order, err := t.c.CreateOrder(ctx, userID, items)
+	return orderFromPB(order), errorFromGRPC(err)
+}
+
+func (t grpcTransport) GetOrder(ctx context.Context, id string) (*model.Order, error) {
+	order, err := t.c.GetOrder(ctx, id)
+	return orderFromPB(order), errorFromGRPC(err)
+}
+
+func (t grpcTransport) ListOrders(ctx context.Context, userID string, page, pageSize int32) ([]model.Order, int32, int32, int32, error) {
+	orders, total, page, pageSize, err := t.c.ListOrders(ctx, userID, page, pageSize)
+	return mapSlice(orders, orderFromPB), total, page, pageSize, errorFromGRPC(err)
+}
+
+func (t grpcTransport) CancelOrder(ctx context.Context, id string) (*model.Order, error) {
+	order, err := t.c.CancelOrder(ctx, id)
+	return orderFromPB(order), errorFromGRPC(err)
+}
+
+// Message conversions; nil messages convert to nil
+
+func mapSlice[M any, T any](messages []*M, convert func(*M) *T) []T {
+	if messages == nil {
+		return nil
+	}
+	items := make([]T, len(messages))
+	for i, m := range messages {
+		items[i] = *convert(m)
+	}
+	return items
+}
+
+func userFromPB(user *userpb.User) *model.User {
+	if user == nil {
+		return nil
+	}
+	return &model.User{
+		ID:                 user.Id,
+		TenantID:           user.TenantId,
+		Username:           user.Username,
+		Email:              user.Email,
+		FullName:           user.FullName,
+		IsActive:           user.IsActive,
+		EmailVerified:      user.EmailVerified,
+		CreatedAt:          CreateTime(user),
+		UpdatedAt:          UpdateTime(user),
+		PasswordSetupToken: user.PasswordSetupToken,
+	}
+}
+
+func authTokensFromPB(tokens *authpb.AuthTokens) *model.AuthTokens {
+	if tokens == nil {
+		return nil
+	}
+	return &model.AuthTokens{
+		AccessToken:           tokens.AccessToken,
+		TokenType:             tokens.TokenType,
+		AccessTokenExpiresAt:  tokens.AccessTokenExpireTime.AsTime(),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenExpireTime.AsTime(),
+		User:                  userFromPB(tokens.User),
+	}
+}
+
+func tenantFromPB(tenant *tenantpb.Tenant) *model.Tenant {
+	if tenant == nil {
+		return nil
+	}
+	return &model.Tenant{
+		ID:          tenant.Id,
+		DisplayName: tenant.DisplayName,
+		CreatedAt:   tenant.CreateTime.AsTime(),
+	}
+}
+
+func productFromPB(product *productpb.Product) *model.Product {
+	if product == nil {
+		return nil
+	}
+	return &model.Product{
+		ID:          product.Id,
+		TenantID:    product.TenantId,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		PriceMoney:  moneyFromPB(product.PriceMoney),
+		Quantity:    product.Quantity,
+		Category:    product.Category,
+		CategoryID:  product.CategoryId,
+		CreatedAt:   CreateTime(product),
+		UpdatedAt:   UpdateTime(product),
+	}
+}
+
+func moneyFromPB(money *moneypb.Money) model.Money {
+	return model.Money{CurrencyCode: money.GetCurrencyCode(), Units: money.GetUnits(), Nanos: money.GetNanos()}
+}
+
+var orderStatusesFromPB = map[orderpb.OrderStatus]model.OrderStatus{
+	orderpb.OrderStatus_ORDER_STATUS_PLACED:    model.OrderStatusPlaced,
+	orderpb.OrderStatus_ORDER_STATUS_CANCELLED: model.OrderStatusCancelled,
+}
+
+func orderFromPB(order *orderpb.Order) *model.Order {
+	if order == nil {
+		return nil
+	}
+	items := make([]model.OrderItem, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = model.OrderItem{
+			ProductID:      item.ProductId,
+			ProductName:    item.ProductName,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			UnitPriceMoney: moneyFromPB(item.UnitPriceMoney),
+		}
+	}
+	return &model.Order{
+		ID:              order.Id,
+		TenantID:        order.TenantId,
+		UserID:          order.UserId,
+		Items:           items,
+		TotalPrice:      order.TotalPrice,
+		TotalPriceMoney: moneyFromPB(order.TotalPriceMoney),
+		Status:          orderStatusesFromPB[order.Status],
+		CreatedAt:       messageTime(nil, order.CreatedAt),
+		UpdatedAt:       messageTime(nil, order.UpdatedAt),
+	}
+}
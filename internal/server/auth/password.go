@@ -0,0 +1,138 @@
+// Package auth holds the password and token primitives users sign in with.
+// Passwords are stored as argon2id hashes in PHC string format, so hashes
+// made with older parameters keep verifying after the parameters change.
+// Access tokens are HS256 JSON Web Tokens that other services can check with
+// the shared secret.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+
+	"go-grpc-rest-demo/internal/server/identity"
+)
+
+// PasswordPolicy configures the passwords a user may choose
+type PasswordPolicy struct {
+	// MinLength and MaxLength bound passwords in characters
+	MinLength int
+	MaxLength int
+	// MinCharClasses is how many of lower case, upper case, digits and other
+	// characters a password must mix
+	MinCharClasses int
+}
+
+// DefaultPasswordPolicy asks for 12 to 128 characters of at least two kinds
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:      12,
+		MaxLength:      128,
+		MinCharClasses: 2,
+	}
+}
+
+// Check describes what is wrong with a password for the user with the given
+// username and email, or returns "" when the policy allows it.
+func (p *PasswordPolicy) Check(password, username, email string) string {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength || length > p.MaxLength {
+		return fmt.Sprintf("must be between %d and %d characters", p.MinLength, p.MaxLength)
+	}
+
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			classes++
+		}
+	}
+	if classes < p.MinCharClasses {
+		return fmt.Sprintf("must mix at least %d of lower case letters, upper case letters, digits and symbols", p.MinCharClasses)
+	}
+
+	key := identity.Key(password)
+	localPart, _, _ := strings.Cut(email, "@")
+	for _, id := range []string{username, localPart} {
+		if id != "" && strings.Contains(key, identity.Key(id)) {
+			return "must not contain the username or email"
+		}
+	}
+	return ""
+}
+
+// Hasher hashes passwords with argon2id
+type Hasher struct {
+	// Time is the number of passes over the memory
+	Time uint32
+	// MemoryKiB is the memory used per hash
+	MemoryKiB  uint32
+	Threads    uint8
+	KeyLength  uint32
+	SaltLength int
+}
+
+// DefaultHasher uses the parameters RFC 9106 recommends for memory-constrained
+// environments: one pass over 64 MiB with four lanes.
+func DefaultHasher() *Hasher {
+	return &Hasher{Time: 1, MemoryKiB: 64 * 1024, Threads: 4, KeyLength: 32, SaltLength: 16}
+}
+
+const argon2idPrefix = "$argon2id$"
+
+// Hash returns the PHC string of a new salted hash of password
+func (h *Hasher) Hash(password string) string {
+	salt := make([]byte, h.SaltLength)
+	_, _ = rand.Read(salt)
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.MemoryKiB, h.Threads, h.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, h.MemoryKiB, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// VerifyPassword reports whether password matches a hash made by
+// Hasher.Hash, using the parameters recorded in the hash.
+func VerifyPassword(encoded, password string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || "$"+parts[1]+"$" != argon2idPrefix {
+		return false, fmt.Errorf("unsupported password hash")
+	}
+
+	var version int
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, fmt.Errorf("invalid argon2 parameters %q: %w", parts[3], err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("invalid salt: %w", err)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("invalid hash: %w", err)
+	}
+
+	got := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
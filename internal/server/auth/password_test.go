@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordTestSuite struct {
+	suite.Suite
+	policy *PasswordPolicy
+	hasher *Hasher
+}
+
+func (suite *PasswordTestSuite) SetupTest() {
+	suite.policy = DefaultPasswordPolicy()
+	suite.hasher = &Hasher{Time: 1, MemoryKiB: 64, Threads: 1, KeyLength: 32, SaltLength: 16}
+}
+
+func (suite *PasswordTestSuite) TestCheck() {
+	tests := map[string]string{
+		"correct-horse-battery":   "",
+		"Tr0ub4dor&3xyz":          "",
+		"short1A":                 "must be between 12 and 128 characters",
+		strings.Repeat("aA1", 50): "must be between 12 and 128 characters",
+		"alllowercaseletters":     "must mix at least 2 of lower case letters, upper case letters, digits and symbols",
+		"my-name-is-ALICE":        "must not contain the username or email",
+		"alice.smith-2024":        "must not contain the username or email",
+	}
+	for password, want := range tests {
+		assert.Equal(suite.T(), want, suite.policy.Check(password, "Alice", "alice.smith@example.com"), password)
+	}
+}
+
+func (suite *PasswordTestSuite) TestHashAndVerify() {
+	hash := suite.hasher.Hash("correct-horse-battery")
+	assert.True(suite.T(), strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+	assert.NotEqual(suite.T(), hash, suite.hasher.Hash("correct-horse-battery"))
+
+	ok, err := VerifyPassword(hash, "correct-horse-battery")
+	suite.Require().NoError(err)
+	assert.True(suite.T(), ok)
+
+	ok, err = VerifyPassword(hash, "correct-horse-battery!")
+	suite.Require().NoError(err)
+	assert.False(suite.T(), ok)
+
+	_, err = VerifyPassword("$2a$10$notargon", "x")
+	assert.Error(suite.T(), err)
+}
+
+func TestPasswordTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordTestSuite))
+}
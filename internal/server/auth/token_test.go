@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenTestSuite struct {
+	suite.Suite
+	signer *TokenSigner
+	now    time.Time
+}
+
+func (suite *TokenTestSuite) SetupTest() {
+	suite.signer = NewTokenSigner([]byte("secret"), 15*time.Minute)
+	suite.now = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+}
+
+func (suite *TokenTestSuite) TestIssueAndVerify() {
+	token, issued := suite.signer.Issue("1", "alice", "default", suite.now)
+	assert.Equal(suite.T(), suite.now.Add(15*time.Minute).Unix(), issued.ExpiresAt)
+
+	claims, err := suite.signer.Verify(token, suite.now.Add(14*time.Minute))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), issued, claims)
+	assert.Equal(suite.T(), "1", claims.Subject)
+	assert.Equal(suite.T(), "alice", claims.Username)
+	assert.Equal(suite.T(), "default", claims.Tenant)
+}
+
+func (suite *TokenTestSuite) TestVerifyRejectsExpiredAndTampered() {
+	token, _ := suite.signer.Issue("1", "alice", "default", suite.now)
+
+	_, err := suite.signer.Verify(token, suite.now.Add(15*time.Minute))
+	assert.ErrorContains(suite.T(), err, "expired")
+
+	for _, bad := range []string{"", "a.b", "a.b.c", token + "x", "x" + token} {
+		_, err := suite.signer.Verify(bad, suite.now)
+		assert.ErrorContains(suite.T(), err, "invalid", bad)
+	}
+
+	_, err = NewTokenSigner([]byte("other"), time.Hour).Verify(token, suite.now)
+	assert.ErrorContains(suite.T(), err, "invalid")
+}
+
+func TestTokenTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenTestSuite))
+}
@@ -0,0 +1,204 @@
+// Package convert maps service models to their protobuf messages. It is
+// shared by the gRPC servers and by exports that emit protobuf records.
+package convert
+
+import (
+	"time"
+
+	authpb "go-grpc-rest-demo/api/gen/go/auth/v1"
+	categorypb "go-grpc-rest-demo/api/gen/go/category/v1"
+	moneypb "go-grpc-rest-demo/api/gen/go/money/v1"
+	orderpb "go-grpc-rest-demo/api/gen/go/order/v1"
+	productpb "go-grpc-rest-demo/api/gen/go/product/v1"
+	tenantpb "go-grpc-rest-demo/api/gen/go/tenant/v1"
+	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func UserToPB(user *model.User) *userpb.User {
+	return &userpb.User{
+		Id:                 user.ID,
+		TenantId:           user.TenantID,
+		Username:           user.Username,
+		Email:              user.Email,
+		FullName:           user.FullName,
+		IsActive:           user.IsActive,
+		CreatedAt:          user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          user.UpdatedAt.Format(time.RFC3339),
+		CreateTime:         timestamppb.New(user.CreatedAt),
+		UpdateTime:         timestamppb.New(user.UpdatedAt),
+		EmailVerified:      user.EmailVerified,
+		PasswordSetupToken: user.PasswordSetupToken,
+	}
+}
+
+func AuthTokensToPB(tokens *model.AuthTokens) *authpb.AuthTokens {
+	return &authpb.AuthTokens{
+		AccessToken:            tokens.AccessToken,
+		TokenType:              tokens.TokenType,
+		AccessTokenExpireTime:  timestamppb.New(tokens.AccessTokenExpiresAt),
+		RefreshToken:           tokens.RefreshToken,
+		RefreshTokenExpireTime: timestamppb.New(tokens.RefreshTokenExpiresAt),
+		User:                   UserToPB(tokens.User),
+	}
+}
+
+func ProductToPB(product *model.Product) *productpb.Product {
+	return &productpb.Product{
+		Id:          product.ID,
+		TenantId:    product.TenantID,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		PriceMoney:  MoneyToPB(product.PriceMoney),
+		Quantity:    product.Quantity,
+		Category:    product.Category,
+		CategoryId:  product.CategoryID,
+		CreatedAt:   product.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   product.UpdatedAt.Format(time.RFC3339),
+		CreateTime:  timestamppb.New(product.CreatedAt),
+		UpdateTime:  timestamppb.New(product.UpdatedAt),
+	}
+}
+
+func MoneyToPB(money model.Money) *moneypb.Money {
+	return &moneypb.Money{
+		CurrencyCode: money.CurrencyCode,
+		Units:        money.Units,
+		Nanos:        money.Nanos,
+	}
+}
+
+// MoneyFromPB validates a protobuf amount; a nil message yields nil
+func MoneyFromPB(money *moneypb.Money) (*model.Money, error) {
+	if money == nil {
+		return nil, nil
+	}
+	m, err := model.NewMoney(money.CurrencyCode, money.Units, money.Nanos)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func ProductFacetsToPB(facets *model.ProductFacets) *productpb.ProductFacets {
+	if facets == nil {
+		return nil
+	}
+	pbFacets := &productpb.ProductFacets{}
+	for _, c := range facets.Categories {
+		pbFacets.Categories = append(pbFacets.Categories, &productpb.CategoryFacetCount{
+			Category:   c.Category,
+			CategoryId: c.CategoryID,
+			Count:      c.Count,
+		})
+	}
+	for _, b := range facets.PriceBuckets {
+		bucket := &productpb.PriceBucketCount{Count: b.Count}
+		if b.Min != nil {
+			bucket.Min = MoneyToPB(*b.Min)
+		}
+		if b.Max != nil {
+			bucket.Max = MoneyToPB(*b.Max)
+		}
+		pbFacets.PriceBuckets = append(pbFacets.PriceBuckets, bucket)
+	}
+	if facets.Stock != nil {
+		pbFacets.Stock = &productpb.StockFacetCount{
+			InStock:    facets.Stock.InStock,
+			OutOfStock: facets.Stock.OutOfStock,
+		}
+	}
+	return pbFacets
+}
+
+// TimeRangeFromPB validates the bounds of a time-range filter; nil bounds are open
+func TimeRangeFromPB(createdAfter, createdBefore, updatedAfter, updatedBefore *timestamppb.Timestamp) (model.TimeRange, error) {
+	var r model.TimeRange
+	bounds := []struct {
+		name string
+		ts   *timestamppb.Timestamp
+		dst  **time.Time
+	}{
+		{"created_after", createdAfter, &r.CreatedAfter},
+		{"created_before", createdBefore, &r.CreatedBefore},
+		{"updated_after", updatedAfter, &r.UpdatedAfter},
+		{"updated_before", updatedBefore, &r.UpdatedBefore},
+	}
+	for _, bound := range bounds {
+		if bound.ts == nil {
+			continue
+		}
+		if err := bound.ts.CheckValid(); err != nil {
+			return model.TimeRange{}, errors.NewValidationError(bound.name, err.Error())
+		}
+		t := bound.ts.AsTime()
+		*bound.dst = &t
+	}
+	return r, nil
+}
+
+func CategoryToPB(category *model.Category) *categorypb.Category {
+	return &categorypb.Category{
+		Id:        category.ID,
+		TenantId:  category.TenantID,
+		Name:      category.Name,
+		Slug:      category.Slug,
+		ParentId:  category.ParentID,
+		CreatedAt: category.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: category.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func TenantToPB(tenant *model.Tenant) *tenantpb.Tenant {
+	return &tenantpb.Tenant{
+		Id:          tenant.ID,
+		DisplayName: tenant.DisplayName,
+		CreateTime:  timestamppb.New(tenant.CreatedAt),
+	}
+}
+
+func ReservationToPB(reservation *model.Reservation) *productpb.Reservation {
+	return &productpb.Reservation{
+		Id:        reservation.ID,
+		ProductId: reservation.ProductID,
+		Quantity:  reservation.Quantity,
+		CreatedAt: reservation.CreatedAt.Format(time.RFC3339),
+		ExpiresAt: reservation.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+var orderStatuses = map[model.OrderStatus]orderpb.OrderStatus{
+	model.OrderStatusPlaced:    orderpb.OrderStatus_ORDER_STATUS_PLACED,
+	model.OrderStatusCancelled: orderpb.OrderStatus_ORDER_STATUS_CANCELLED,
+}
+
+func OrderToPB(order *model.Order) *orderpb.Order {
+	items := make([]*orderpb.OrderItem, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = &orderpb.OrderItem{
+			ProductId:      item.ProductID,
+			ProductName:    item.ProductName,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			UnitPriceMoney: MoneyToPB(item.UnitPriceMoney),
+		}
+	}
+
+	// Orders have no Timestamp fields, so their strings keep the precision
+	// REST responses show
+	return &orderpb.Order{
+		Id:              order.ID,
+		TenantId:        order.TenantID,
+		UserId:          order.UserID,
+		Items:           items,
+		TotalPrice:      order.TotalPrice,
+		TotalPriceMoney: MoneyToPB(order.TotalPriceMoney),
+		Status:          orderStatuses[order.Status],
+		CreatedAt:       order.CreatedAt.Format(time.RFC3339Nano),
+		UpdatedAt:       order.UpdatedAt.Format(time.RFC3339Nano),
+	}
+}
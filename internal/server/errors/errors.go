@@ -3,7 +3,9 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -13,28 +15,38 @@ type ErrorCode string
 
 const (
 	// Client errors
-	ErrCodeInvalidRequest   ErrorCode = "INVALID_REQUEST"
-	ErrCodeValidationFailed ErrorCode = "VALIDATION_FAILED"
-	ErrCodeNotFound         ErrorCode = "NOT_FOUND"
-	ErrCodeAlreadyExists    ErrorCode = "ALREADY_EXISTS"
-	ErrCodeUnauthorized     ErrorCode = "UNAUTHORIZED"
-	ErrCodeForbidden        ErrorCode = "FORBIDDEN"
+	ErrCodeInvalidRequest     ErrorCode = "INVALID_REQUEST"
+	ErrCodeValidationFailed   ErrorCode = "VALIDATION_FAILED"
+	ErrCodeNotFound           ErrorCode = "NOT_FOUND"
+	ErrCodeAlreadyExists      ErrorCode = "ALREADY_EXISTS"
+	ErrCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden          ErrorCode = "FORBIDDEN"
+	ErrCodeFailedPrecondition ErrorCode = "FAILED_PRECONDITION"
+	ErrCodeResourceExhausted  ErrorCode = "RESOURCE_EXHAUSTED"
 
 	// Server errors
-	ErrCodeInternal       ErrorCode = "INTERNAL_ERROR"
-	ErrCodeServiceDown    ErrorCode = "SERVICE_DOWN"
-	ErrCodeDatabaseError  ErrorCode = "DATABASE_ERROR"
-	ErrCodeExternalAPI    ErrorCode = "EXTERNAL_API_ERROR"
+	ErrCodeInternal      ErrorCode = "INTERNAL_ERROR"
+	ErrCodeServiceDown   ErrorCode = "SERVICE_DOWN"
+	ErrCodeDatabaseError ErrorCode = "DATABASE_ERROR"
+	ErrCodeExternalAPI   ErrorCode = "EXTERNAL_API_ERROR"
 )
 
+// FieldViolation describes one invalid field of a request. Field is a path
+// such as "items[0].quantity".
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
 // AppError represents a structured application error
 type AppError struct {
-	Code       ErrorCode `json:"code"`
-	Message    string    `json:"message"`
-	Details    string    `json:"details,omitempty"`
-	Field      string    `json:"field,omitempty"`
-	HTTPStatus int       `json:"-"`
-	GRPCCode   codes.Code `json:"-"`
+	Code       ErrorCode        `json:"code"`
+	Message    string           `json:"message"`
+	Details    string           `json:"details,omitempty"`
+	Field      string           `json:"field,omitempty"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+	HTTPStatus int              `json:"-"`
+	GRPCCode   codes.Code       `json:"-"`
 }
 
 func (e *AppError) Error() string {
@@ -61,6 +73,10 @@ func (e *AppError) ToHTTPStatus() int {
 		return http.StatusUnauthorized
 	case ErrCodeForbidden:
 		return http.StatusForbidden
+	case ErrCodeFailedPrecondition:
+		return http.StatusConflict
+	case ErrCodeResourceExhausted:
+		return http.StatusTooManyRequests
 	case ErrCodeInternal, ErrCodeDatabaseError:
 		return http.StatusInternalServerError
 	case ErrCodeServiceDown, ErrCodeExternalAPI:
@@ -70,10 +86,30 @@ func (e *AppError) ToHTTPStatus() int {
 	}
 }
 
-// ToGRPCStatus returns the appropriate gRPC status
+// ToGRPCStatus returns the appropriate gRPC status. Field violations are
+// attached as a google.rpc.BadRequest detail.
 func (e *AppError) ToGRPCStatus() *status.Status {
+	st := status.New(e.grpcCode(), e.Message)
+	if len(e.Violations) == 0 {
+		return st
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	if detailed, err := st.WithDetails(badRequest); err == nil {
+		return detailed
+	}
+	return st
+}
+
+func (e *AppError) grpcCode() codes.Code {
 	if e.GRPCCode != codes.OK {
-		return status.New(e.GRPCCode, e.Message)
+		return e.GRPCCode
 	}
 
 	var grpcCode codes.Code
@@ -88,6 +124,10 @@ func (e *AppError) ToGRPCStatus() *status.Status {
 		grpcCode = codes.Unauthenticated
 	case ErrCodeForbidden:
 		grpcCode = codes.PermissionDenied
+	case ErrCodeFailedPrecondition:
+		grpcCode = codes.FailedPrecondition
+	case ErrCodeResourceExhausted:
+		grpcCode = codes.ResourceExhausted
 	case ErrCodeInternal, ErrCodeDatabaseError:
 		grpcCode = codes.Internal
 	case ErrCodeServiceDown, ErrCodeExternalAPI:
@@ -96,7 +136,7 @@ func (e *AppError) ToGRPCStatus() *status.Status {
 		grpcCode = codes.Internal
 	}
 
-	return status.New(grpcCode, e.Message)
+	return grpcCode
 }
 
 // Error constructors for common scenarios
@@ -109,6 +149,24 @@ func NewValidationError(field, message string) *AppError {
 	}
 }
 
+// NewViolationsError reports every invalid field of a request at once. The
+// message lists all violations; Field names the first one.
+func NewViolationsError(violations []FieldViolation) *AppError {
+	descriptions := make([]string, len(violations))
+	for i, v := range violations {
+		descriptions[i] = v.Description
+	}
+	appErr := &AppError{
+		Code:       ErrCodeValidationFailed,
+		Message:    strings.Join(descriptions, "; "),
+		Violations: violations,
+	}
+	if len(violations) > 0 {
+		appErr.Field = violations[0].Field
+	}
+	return appErr
+}
+
 func NewNotFoundError(resource, id string) *AppError {
 	return &AppError{
 		Code:    ErrCodeNotFound,
@@ -133,6 +191,34 @@ func NewInvalidRequestError(message string) *AppError {
 	}
 }
 
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Code:    ErrCodeUnauthorized,
+		Message: message,
+	}
+}
+
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Code:    ErrCodeForbidden,
+		Message: message,
+	}
+}
+
+func NewFailedPreconditionError(message string) *AppError {
+	return &AppError{
+		Code:    ErrCodeFailedPrecondition,
+		Message: message,
+	}
+}
+
+func NewResourceExhaustedError(message string) *AppError {
+	return &AppError{
+		Code:    ErrCodeResourceExhausted,
+		Message: message,
+	}
+}
+
 func NewInternalError(message string) *AppError {
 	return &AppError{
 		Code:    ErrCodeInternal,
@@ -165,4 +251,4 @@ func AsAppError(err error) *AppError {
 		Message: "Internal server error",
 		Details: err.Error(),
 	}
-}
\ No newline at end of file
+}
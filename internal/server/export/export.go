@@ -0,0 +1,155 @@
+// Package export encodes snapshots of users and products as NDJSON, CSV or
+// length-delimited protobuf. Records are written as they are encoded, so an
+// export never holds more than one buffer of output in memory.
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/convert"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+// BufferSize is the amount of encoded output buffered before it is written
+const BufferSize = 32 * 1024
+
+var contentTypes = map[string]string{
+	model.ExportFormatNDJSON:   "application/x-ndjson",
+	model.ExportFormatCSV:      "text/csv",
+	model.ExportFormatProtobuf: "application/x-protobuf",
+}
+
+// Encoder writes records of type T in one export format
+type Encoder[T any] interface {
+	Encode(item *T) error
+	// Close flushes buffered output; it does not close the underlying writer
+	Close() error
+}
+
+// ContentType returns the media type of an export format, which defaults to NDJSON
+func ContentType(format string) string {
+	return contentTypes[normalizeFormat(format)]
+}
+
+// Write encodes items in order and closes the encoder, stopping early when ctx is done
+func Write[T any](ctx context.Context, enc Encoder[T], items []T) error {
+	for i := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := enc.Encode(&items[i]); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+var userColumns = []string{"id", "username", "email", "full_name", "is_active", "created_at", "updated_at"}
+
+func NewUserEncoder(format string, w io.Writer) (Encoder[model.User], error) {
+	return newEncoder(format, w, userColumns, func(user *model.User) []string {
+		return []string{
+			user.ID,
+			user.Username,
+			user.Email,
+			user.FullName,
+			strconv.FormatBool(user.IsActive),
+			user.CreatedAt.Format(time.RFC3339),
+			user.UpdatedAt.Format(time.RFC3339),
+		}
+	}, func(user *model.User) proto.Message {
+		return convert.UserToPB(user)
+	})
+}
+
+// productColumns is a superset of the product import columns, so a CSV
+// export can be imported again.
+var productColumns = []string{"id", "name", "description", "price", "quantity", "category", "created_at", "updated_at", "currency_code"}
+
+func NewProductEncoder(format string, w io.Writer) (Encoder[model.Product], error) {
+	return newEncoder(format, w, productColumns, func(product *model.Product) []string {
+		return []string{
+			product.ID,
+			product.Name,
+			product.Description,
+			product.PriceMoney.Amount(),
+			strconv.FormatInt(int64(product.Quantity), 10),
+			product.Category,
+			product.CreatedAt.Format(time.RFC3339),
+			product.UpdatedAt.Format(time.RFC3339),
+			product.PriceMoney.CurrencyCode,
+		}
+	}, func(product *model.Product) proto.Message {
+		return convert.ProductToPB(product)
+	})
+}
+
+func normalizeFormat(format string) string {
+	if format == "" {
+		return model.ExportFormatNDJSON
+	}
+	return format
+}
+
+type encoder[T any] struct {
+	encode func(*T) error
+	flush  func() error
+}
+
+func (e *encoder[T]) Encode(item *T) error {
+	return e.encode(item)
+}
+
+func (e *encoder[T]) Close() error {
+	return e.flush()
+}
+
+func newEncoder[T any](format string, w io.Writer, columns []string, row func(*T) []string, toPB func(*T) proto.Message) (Encoder[T], error) {
+	buf := bufio.NewWriterSize(w, BufferSize)
+
+	switch normalizeFormat(format) {
+	case model.ExportFormatNDJSON:
+		enc := json.NewEncoder(buf)
+		return &encoder[T]{
+			encode: func(item *T) error { return enc.Encode(item) },
+			flush:  buf.Flush,
+		}, nil
+	case model.ExportFormatCSV:
+		writer := csv.NewWriter(buf)
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+		return &encoder[T]{
+			encode: func(item *T) error { return writer.Write(row(item)) },
+			flush: func() error {
+				writer.Flush()
+				if err := writer.Error(); err != nil {
+					return err
+				}
+				return buf.Flush()
+			},
+		}, nil
+	case model.ExportFormatProtobuf:
+		return &encoder[T]{
+			encode: func(item *T) error {
+				_, err := protodelim.MarshalTo(buf, toPB(item))
+				return err
+			},
+			flush: buf.Flush,
+		}, nil
+	default:
+		return nil, errors.NewValidationError("format",
+			fmt.Sprintf("unsupported export format %q (use ndjson, csv or protobuf)", format))
+	}
+}
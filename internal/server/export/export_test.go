@@ -0,0 +1,85 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	userpb "go-grpc-rest-demo/api/gen/go/user/v1"
+	"go-grpc-rest-demo/internal/server/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+type ExportTestSuite struct {
+	suite.Suite
+	users []model.User
+}
+
+func (suite *ExportTestSuite) SetupTest() {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	suite.users = []model.User{
+		{ID: "1", Username: "alice", Email: "alice@example.com", FullName: "Alice, Jr.", IsActive: true, CreatedAt: now, UpdatedAt: now},
+		{ID: "2", Username: "bob", Email: "bob@example.com", FullName: "Bob", CreatedAt: now, UpdatedAt: now},
+	}
+}
+
+func (suite *ExportTestSuite) export(format string) *bytes.Buffer {
+	var buf bytes.Buffer
+	enc, err := NewUserEncoder(format, &buf)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), Write(context.Background(), enc, suite.users))
+	return &buf
+}
+
+func (suite *ExportTestSuite) TestNDJSON() {
+	decoder := json.NewDecoder(suite.export(""))
+	for _, want := range suite.users {
+		var got model.User
+		assert.NoError(suite.T(), decoder.Decode(&got))
+		assert.Equal(suite.T(), want.Username, got.Username)
+	}
+	assert.False(suite.T(), decoder.More())
+}
+
+func (suite *ExportTestSuite) TestCSV() {
+	records, err := csv.NewReader(suite.export(model.ExportFormatCSV)).ReadAll()
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), records, 3)
+	assert.Equal(suite.T(), userColumns, records[0])
+	assert.Equal(suite.T(), []string{"1", "alice", "alice@example.com", "Alice, Jr.", "true", "2024-01-02T03:04:05Z", "2024-01-02T03:04:05Z"}, records[1])
+}
+
+func (suite *ExportTestSuite) TestProtobuf() {
+	buf := suite.export(model.ExportFormatProtobuf)
+	for _, want := range suite.users {
+		var got userpb.User
+		assert.NoError(suite.T(), protodelim.UnmarshalFrom(buf, &got))
+		assert.Equal(suite.T(), want.ID, got.Id)
+		assert.Equal(suite.T(), want.IsActive, got.IsActive)
+	}
+	assert.Zero(suite.T(), buf.Len())
+}
+
+func (suite *ExportTestSuite) TestUnsupportedFormat() {
+	_, err := NewUserEncoder("xml", &bytes.Buffer{})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ExportTestSuite) TestCancelledContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	enc, err := NewUserEncoder(model.ExportFormatNDJSON, &bytes.Buffer{})
+	assert.NoError(suite.T(), err)
+	assert.ErrorIs(suite.T(), Write(ctx, enc, suite.users), context.Canceled)
+}
+
+func TestExportTestSuite(t *testing.T) {
+	suite.Run(t, new(ExportTestSuite))
+}
@@ -0,0 +1,457 @@
+// Package filter parses and evaluates AIP-160 filter expressions such as
+//
+//	is_active = false AND created_at >= "2025-10-01T00:00:00Z"
+//	email = "*@corp.com" OR NOT name:laptop
+//
+// Expressions combine comparisons (=, !=, <, <=, >, >=, and : for
+// has-substring) with AND, OR, NOT and parentheses; OR binds tighter than
+// AND, and terms separated only by whitespace are ANDed. A bare value such
+// as "alice" matches when any of the schema's search fields contains it.
+// Timestamps are quoted RFC 3339 strings.
+package filter
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// Kind is the type of a filterable field, which decides the literals and
+// operators it accepts.
+type Kind int
+
+const (
+	String Kind = iota
+	Bool
+	Number
+	Timestamp
+)
+
+// Field reads one filterable field of T. Exactly one accessor is set,
+// matching Kind; use the StringField, BoolField, NumberField and TimeField
+// constructors.
+type Field[T any] struct {
+	Kind      Kind
+	getString func(*T) string
+	getBool   func(*T) bool
+	getNumber func(*T) float64
+	getTime   func(*T) time.Time
+}
+
+func StringField[T any](get func(*T) string) Field[T] {
+	return Field[T]{Kind: String, getString: get}
+}
+
+func BoolField[T any](get func(*T) bool) Field[T] {
+	return Field[T]{Kind: Bool, getBool: get}
+}
+
+func NumberField[T any](get func(*T) float64) Field[T] {
+	return Field[T]{Kind: Number, getNumber: get}
+}
+
+func TimeField[T any](get func(*T) time.Time) Field[T] {
+	return Field[T]{Kind: Timestamp, getTime: get}
+}
+
+// Schema lists the fields of T a filter may refer to. Search names the
+// string fields a bare value is matched against.
+type Schema[T any] struct {
+	Fields map[string]Field[T]
+	Search []string
+}
+
+// Filter is a parsed expression
+type Filter[T any] struct {
+	match func(*T) bool
+}
+
+// Match reports whether item satisfies the filter. A nil filter matches
+// everything.
+func (f *Filter[T]) Match(item *T) bool {
+	return f == nil || f.match(item)
+}
+
+// Parse compiles expr against the schema. An empty expression yields a nil
+// filter. Errors are validation errors on param that give the position, a
+// 1-based byte offset, of the offending token.
+func (s *Schema[T]) Parse(param, expr string) (*Filter[T], error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, errorAt(param, err)
+	}
+
+	p := &parser[T]{schema: s, tokens: tokens}
+	match, err := p.parseExpression()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = p.errorf(p.peek(), "unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, errorAt(param, err)
+	}
+	return &Filter[T]{match: match}, nil
+}
+
+type syntaxError struct {
+	pos int
+	msg string
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.msg, e.pos)
+}
+
+func errorAt(param string, err error) error {
+	return errors.NewValidationError(param, fmt.Sprintf("invalid %s: %s", param, err.Error()))
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	value string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of filter"
+	case tokenString:
+		return strconv.Quote(t.value)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && t.text == keyword
+}
+
+const operatorChars = "=!<>:"
+
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i + 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i + 1})
+			i++
+		case c == '"' || c == '\'':
+			value, end, ok := readString(expr, i)
+			if !ok {
+				return nil, &syntaxError{pos: i + 1, msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expr[i:end], pos: i + 1, value: value})
+			i = end
+		case strings.IndexByte(operatorChars, c) >= 0:
+			op := string(c)
+			if i+1 < len(expr) && expr[i+1] == '=' && c != ':' && c != '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &syntaxError{pos: i + 1, msg: `expected "!="`}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i + 1})
+			i += len(op)
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t\n\r()\"'"+operatorChars, rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: expr[start:i], pos: start + 1, value: expr[start:i]})
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr) + 1}), nil
+}
+
+// readString reads the quoted string starting at expr[start], handling
+// backslash escapes, and returns its value and the offset after it.
+func readString(expr string, start int) (string, int, bool) {
+	quote := expr[start]
+	var b strings.Builder
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			if i+1 == len(expr) {
+				return "", 0, false
+			}
+			i++
+			b.WriteByte(expr[i])
+		case quote:
+			return b.String(), i + 1, true
+		default:
+			b.WriteByte(expr[i])
+		}
+	}
+	return "", 0, false
+}
+
+type parser[T any] struct {
+	schema *Schema[T]
+	tokens []token
+	next   int
+}
+
+func (p *parser[T]) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser[T]) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser[T]) errorf(t token, format string, args ...any) error {
+	return &syntaxError{pos: t.pos, msg: fmt.Sprintf(format, args...)}
+}
+
+// parseExpression parses: sequence { "AND" sequence }. As in AIP-160, OR
+// binds tighter than AND, so "a AND b OR c" means "a AND (b OR c)".
+func (p *parser[T]) parseExpression() (func(*T) bool, error) {
+	left, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("AND") {
+		p.advance()
+		right, err := p.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item *T) bool { return l(item) && right(item) }
+	}
+	return left, nil
+}
+
+// parseSequence parses: factor { factor }, terms separated by whitespace
+// that must all match
+func (p *parser[T]) parseSequence() (func(*T) bool, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind == tokenEOF || t.kind == tokenRParen || t.isKeyword("AND") {
+			return left, nil
+		}
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item *T) bool { return l(item) && right(item) }
+	}
+}
+
+// parseFactor parses: unary { "OR" unary }
+func (p *parser[T]) parseFactor() (func(*T) bool, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("OR") {
+		p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item *T) bool { return l(item) || right(item) }
+	}
+	return left, nil
+}
+
+// parseUnary parses: "NOT" unary | "(" expression ")" | term
+func (p *parser[T]) parseUnary() (func(*T) bool, error) {
+	t := p.peek()
+	switch {
+	case t.isKeyword("NOT"):
+		p.advance()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(item *T) bool { return !operand(item) }, nil
+	case t.kind == tokenLParen:
+		p.advance()
+		inner, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokenRParen {
+			return nil, p.errorf(closing, `expected ")" but found %s`, closing)
+		}
+		return inner, nil
+	default:
+		return p.parseTerm()
+	}
+}
+
+// parseTerm parses: field operator value | value
+func (p *parser[T]) parseTerm() (func(*T) bool, error) {
+	t := p.advance()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return nil, p.errorf(t, "expected a comparison or value but found %s", t)
+	}
+	if t.isKeyword("AND") || t.isKeyword("OR") {
+		return nil, p.errorf(t, "expected a comparison or value but found %s", t)
+	}
+	if p.peek().kind != tokenOperator {
+		return p.search(t.value), nil
+	}
+	if t.kind != tokenWord {
+		return nil, p.errorf(t, "expected a field name but found %s", t)
+	}
+
+	field, ok := p.schema.Fields[t.text]
+	if !ok {
+		return nil, p.errorf(t, "unknown field %q", t.text)
+	}
+	op := p.advance()
+	value := p.advance()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, p.errorf(value, "expected a value after %s but found %s", op, value)
+	}
+	return compare(field, op, value)
+}
+
+// search matches value as a case-insensitive substring of any search field
+func (p *parser[T]) search(value string) func(*T) bool {
+	needle := strings.ToLower(value)
+	var fields []func(*T) string
+	for _, name := range p.schema.Search {
+		fields = append(fields, p.schema.Fields[name].getString)
+	}
+	return func(item *T) bool {
+		for _, get := range fields {
+			if strings.Contains(strings.ToLower(get(item)), needle) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func compare[T any](field Field[T], op, value token) (func(*T) bool, error) {
+	if op.text == ":" && field.Kind != String {
+		return nil, &syntaxError{pos: op.pos, msg: `":" only applies to string fields`}
+	}
+
+	switch field.Kind {
+	case String:
+		return compareString(field.getString, op, value)
+	case Bool:
+		b, err := strconv.ParseBool(value.value)
+		if err != nil {
+			return nil, &syntaxError{pos: value.pos, msg: fmt.Sprintf("expected true or false but found %s", value)}
+		}
+		if op.text != "=" && op.text != "!=" {
+			return nil, &syntaxError{pos: op.pos, msg: fmt.Sprintf("operator %s does not apply to boolean fields", op)}
+		}
+		want := b == (op.text == "=")
+		return func(item *T) bool { return field.getBool(item) == want }, nil
+	case Number:
+		n, err := strconv.ParseFloat(value.value, 64)
+		if err != nil {
+			return nil, &syntaxError{pos: value.pos, msg: fmt.Sprintf("expected a number but found %s", value)}
+		}
+		return ordered(op, func(item *T) int {
+			return cmp.Compare(field.getNumber(item), n)
+		}), nil
+	default:
+		t, err := time.Parse(time.RFC3339Nano, value.value)
+		if err != nil {
+			return nil, &syntaxError{pos: value.pos, msg: fmt.Sprintf("expected an RFC 3339 timestamp but found %s", value)}
+		}
+		return ordered(op, func(item *T) int {
+			return field.getTime(item).Compare(t)
+		}), nil
+	}
+}
+
+// compareString handles the string operators. ":" is a case-insensitive
+// substring match; "=" and "!=" match exactly, with a leading or trailing
+// "*" matching any suffix or prefix.
+func compareString[T any](get func(*T) string, op, value token) (func(*T) bool, error) {
+	want := value.value
+	switch op.text {
+	case ":":
+		needle := strings.ToLower(want)
+		return func(item *T) bool { return strings.Contains(strings.ToLower(get(item)), needle) }, nil
+	case "=", "!=":
+		equal := wildcard(want)
+		if op.text == "!=" {
+			return func(item *T) bool { return !equal(get(item)) }, nil
+		}
+		return func(item *T) bool { return equal(get(item)) }, nil
+	default:
+		return ordered(op, func(item *T) int { return strings.Compare(get(item), want) }), nil
+	}
+}
+
+func wildcard(pattern string) func(string) bool {
+	prefix := strings.HasSuffix(pattern, "*")
+	suffix := strings.HasPrefix(pattern, "*")
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(pattern, "*"), "*")
+	switch {
+	case pattern == "*":
+		return func(string) bool { return true }
+	case prefix && suffix:
+		return func(s string) bool { return strings.Contains(s, trimmed) }
+	case prefix:
+		return func(s string) bool { return strings.HasPrefix(s, trimmed) }
+	case suffix:
+		return func(s string) bool { return strings.HasSuffix(s, trimmed) }
+	default:
+		return func(s string) bool { return s == pattern }
+	}
+}
+
+// ordered turns a three-way comparison against the literal into op
+func ordered[T any](op token, compare func(*T) int) func(*T) bool {
+	var accept func(int) bool
+	switch op.text {
+	case "=":
+		accept = func(c int) bool { return c == 0 }
+	case "!=":
+		accept = func(c int) bool { return c != 0 }
+	case "<":
+		accept = func(c int) bool { return c < 0 }
+	case "<=":
+		accept = func(c int) bool { return c <= 0 }
+	case ">":
+		accept = func(c int) bool { return c > 0 }
+	default:
+		accept = func(c int) bool { return c >= 0 }
+	}
+	return func(item *T) bool { return accept(compare(item)) }
+}
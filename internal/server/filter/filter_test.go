@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type account struct {
+	name      string
+	email     string
+	active    bool
+	balance   float64
+	createdAt time.Time
+}
+
+var accountSchema = &Schema[account]{
+	Fields: map[string]Field[account]{
+		"name":       StringField(func(a *account) string { return a.name }),
+		"email":      StringField(func(a *account) string { return a.email }),
+		"is_active":  BoolField(func(a *account) bool { return a.active }),
+		"balance":    NumberField(func(a *account) float64 { return a.balance }),
+		"created_at": TimeField(func(a *account) time.Time { return a.createdAt }),
+	},
+	Search: []string{"name", "email"},
+}
+
+type FilterTestSuite struct {
+	suite.Suite
+	accounts []account
+}
+
+func (suite *FilterTestSuite) SetupTest() {
+	suite.accounts = []account{
+		{"alice", "alice@corp.com", true, 10, time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)},
+		{"bob", "bob@example.com", false, 0, time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC)},
+		{"carol", "carol@corp.com", false, 25.5, time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func (suite *FilterTestSuite) matching(expr string) string {
+	f, err := accountSchema.Parse("filter", expr)
+	suite.Require().NoError(err, expr)
+
+	var names []string
+	for i := range suite.accounts {
+		if f.Match(&suite.accounts[i]) {
+			names = append(names, suite.accounts[i].name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (suite *FilterTestSuite) TestExpressions() {
+	tests := map[string]string{
+		``:      "alice,bob,carol",
+		`alice`: "alice",
+		`CORP`:  "alice,carol",
+		`is_active = false AND created_at >= "2025-10-01T00:00:00Z"`: "bob,carol",
+		`email = "*@corp.com"`:        "alice,carol",
+		`email = "bob*"`:              "bob",
+		`name != alice`:               "bob,carol",
+		`email:EXAMPLE`:               "bob",
+		`balance > 5 balance <= 25.5`: "alice,carol",
+		// OR binds tighter than AND
+		`NOT is_active = true OR name = alice AND balance < 1`: "bob",
+		`name = carol AND balance > 20 OR is_active = true`:    "carol",
+		`(name = alice OR name = bob) AND NOT balance = 0`:     "alice",
+		`name < "bob"`:   "alice",
+		`name = 'carol'`: "carol",
+	}
+	for expr, want := range tests {
+		assert.Equal(suite.T(), want, suite.matching(expr), expr)
+	}
+}
+
+func (suite *FilterTestSuite) TestErrorsReportPosition() {
+	tests := map[string]string{
+		`name = alice AND`:         "at position 17",
+		`password = x`:             `unknown field "password" at position 1`,
+		`is_active = maybe`:        "at position 13",
+		`balance > ten`:            "expected a number",
+		`created_at > "yesterday"`: "RFC 3339",
+		`(name = alice`:            `expected ")"`,
+		`name = "alice`:            "unterminated string at position 8",
+		`is_active:true`:           `":" only applies to string fields`,
+		`name = alice)`:            `unexpected ")" at position 13`,
+		`name ! alice`:             `expected "!="`,
+	}
+	for expr, want := range tests {
+		_, err := accountSchema.Parse("filter", expr)
+		suite.Require().Error(err, expr)
+		appErr := errors.AsAppError(err)
+		assert.Equal(suite.T(), errors.ErrCodeValidationFailed, appErr.Code, expr)
+		assert.Equal(suite.T(), "filter", appErr.Field, expr)
+		assert.Contains(suite.T(), appErr.Message, want, expr)
+	}
+}
+
+func TestFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(FilterTestSuite))
+}
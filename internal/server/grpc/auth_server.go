@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"context"
+
+	pb "go-grpc-rest-demo/api/gen/go/auth/v1"
+	"go-grpc-rest-demo/internal/server/convert"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
+)
+
+type AuthServer struct {
+	pb.UnimplementedAuthServiceServer
+	authService *service.AuthService
+}
+
+func NewAuthServer(authService *service.AuthService) *AuthServer {
+	return &AuthServer{authService: authService}
+}
+
+func (s *AuthServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	modelReq := &model.LoginRequest{
+		Username: req.Username,
+		Password: req.Password,
+	}
+
+	tokens, err := s.authService.Login(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.LoginResponse{
+		Tokens:  convert.AuthTokensToPB(tokens),
+		Message: "Logged in successfully",
+	}, nil
+}
+
+func (s *AuthServer) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	tokens, err := s.authService.RefreshToken(ctx, &model.RefreshTokenRequest{RefreshToken: req.RefreshToken})
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.RefreshTokenResponse{
+		Tokens:  convert.AuthTokensToPB(tokens),
+		Message: "Tokens refreshed successfully",
+	}, nil
+}
@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+
+	pb "go-grpc-rest-demo/api/gen/go/category/v1"
+	"go-grpc-rest-demo/internal/server/convert"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
+)
+
+type CategoryServer struct {
+	pb.UnimplementedCategoryServiceServer
+	categoryService *service.CategoryService
+	productService  *service.ProductService
+}
+
+func NewCategoryServer(categoryService *service.CategoryService, productService *service.ProductService) *CategoryServer {
+	return &CategoryServer{categoryService: categoryService, productService: productService}
+}
+
+func (s *CategoryServer) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CreateCategoryResponse, error) {
+	category, err := s.categoryService.CreateCategory(ctx, &model.CreateCategoryRequest{
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentId,
+	})
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.CreateCategoryResponse{
+		Category: convert.CategoryToPB(category),
+		Message:  "Category created successfully",
+	}, nil
+}
+
+func (s *CategoryServer) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.GetCategoryResponse, error) {
+	if req.Id == "" {
+		return nil, handleGRPCError(errors.NewValidationError("id", "id is required"))
+	}
+
+	category, err := s.categoryService.GetCategory(ctx, req.Id)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.GetCategoryResponse{
+		Category: convert.CategoryToPB(category),
+		Message:  "Category retrieved successfully",
+	}, nil
+}
+
+func (s *CategoryServer) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.UpdateCategoryResponse, error) {
+	category, err := s.categoryService.UpdateCategory(ctx, &model.UpdateCategoryRequest{
+		ID:       req.Id,
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentId,
+	})
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.UpdateCategoryResponse{
+		Category: convert.CategoryToPB(category),
+		Message:  "Category updated successfully",
+	}, nil
+}
+
+func (s *CategoryServer) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+	if req.Id == "" {
+		return nil, handleGRPCError(errors.NewValidationError("id", "id is required"))
+	}
+
+	if err := s.categoryService.DeleteCategory(ctx, req.Id); err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.DeleteCategoryResponse{Message: "Category deleted successfully"}, nil
+}
+
+func (s *CategoryServer) ListCategories(ctx context.Context, req *pb.ListCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	categories, err := s.categoryService.ListCategories(ctx, &model.ListCategoriesRequest{ParentID: req.ParentId})
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	pbCategories := make([]*pb.Category, len(categories))
+	for i := range categories {
+		pbCategories[i] = convert.CategoryToPB(&categories[i])
+	}
+	return &pb.ListCategoriesResponse{Categories: pbCategories}, nil
+}
+
+func (s *CategoryServer) MigrateProductCategories(ctx context.Context, req *pb.MigrateProductCategoriesRequest) (*pb.MigrateProductCategoriesResponse, error) {
+	summary, err := s.productService.MigrateCategories(ctx)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.MigrateProductCategoriesResponse{
+		CategoriesCreated: summary.CategoriesCreated,
+		ProductsUpdated:   summary.ProductsUpdated,
+	}, nil
+}
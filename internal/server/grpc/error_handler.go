@@ -2,6 +2,9 @@ package grpc
 
 import (
 	"go-grpc-rest-demo/internal/server/errors"
+
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
 )
 
 // handleGRPCError converts AppError to gRPC status error
@@ -10,4 +13,12 @@ func handleGRPCError(err error) error {
 		return nil
 	}
 	return errors.AsAppError(err).ToGRPCStatus().Err()
-}
\ No newline at end of file
+}
+
+// batchItemStatus converts the outcome of one batch item to a google.rpc.Status
+func batchItemStatus(appErr *errors.AppError) *statuspb.Status {
+	if appErr == nil {
+		return &statuspb.Status{Code: int32(codes.OK)}
+	}
+	return appErr.ToGRPCStatus().Proto()
+}
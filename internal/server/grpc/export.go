@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	exportpb "go-grpc-rest-demo/api/gen/go/export/v1"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/export"
+	"go-grpc-rest-demo/internal/server/model"
+)
+
+var exportFormats = map[exportpb.ExportFormat]string{
+	exportpb.ExportFormat_EXPORT_FORMAT_UNSPECIFIED: model.ExportFormatNDJSON,
+	exportpb.ExportFormat_EXPORT_FORMAT_NDJSON:      model.ExportFormatNDJSON,
+	exportpb.ExportFormat_EXPORT_FORMAT_CSV:         model.ExportFormatCSV,
+	exportpb.ExportFormat_EXPORT_FORMAT_PROTOBUF:    model.ExportFormatProtobuf,
+}
+
+func exportFormatFromPB(format exportpb.ExportFormat) (string, error) {
+	name, ok := exportFormats[format]
+	if !ok {
+		return "", errors.NewValidationError("format", "unsupported export format")
+	}
+	return name, nil
+}
+
+// chunkWriter sends written bytes as export response messages of at most
+// export.BufferSize bytes. Send marshals before returning, so the caller's
+// buffer may be reused.
+type chunkWriter func(data []byte) error
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), export.BufferSize)
+		if err := w(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
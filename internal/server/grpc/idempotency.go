@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/idempotency"
+	"go-grpc-rest-demo/internal/server/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// IdempotencyKeyMetadata makes a mutating call safe to retry
+	IdempotencyKeyMetadata = "idempotency-key"
+	// IdempotentReplayedMetadata is sent as a header on replayed responses
+	IdempotentReplayedMetadata = "idempotent-replayed"
+)
+
+type recordedCall struct {
+	resp any
+	err  error
+}
+
+// transientCodes are outcomes a retry may change, so they are not replayed
+var transientCodes = map[codes.Code]bool{
+	codes.Canceled:          true,
+	codes.Unknown:           true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+	codes.Aborted:           true,
+	codes.Internal:          true,
+	codes.Unavailable:       true,
+	codes.DataLoss:          true,
+}
+
+// IdempotencyInterceptor replays the first outcome of a unary call for
+// retries that carry the same idempotency-key metadata. Keys are scoped to
+// the tenant and the caller's authorization metadata, so it must run after
+// TenantUnaryInterceptor; reusing a key for a different method or request
+// message is rejected. Outcomes are kept for ttl.
+func IdempotencyInterceptor(ttl time.Duration) grpc.UnaryServerInterceptor {
+	store := idempotency.NewStore[recordedCall](ttl)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		key := firstValue(md, IdempotencyKeyMetadata)
+		msg, ok := req.(proto.Message)
+		if key == "" || !ok {
+			return handler(ctx, req)
+		}
+		if err := idempotency.ValidateKey(key); err != nil {
+			return nil, handleGRPCError(err)
+		}
+
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to fingerprint request: %v", err)
+		}
+		fingerprint := idempotency.Fingerprint([]byte(info.FullMethod), body)
+		principal := tenant.FromContext(ctx) + ":" + idempotency.Principal(firstValue(md, "authorization"))
+
+		call, replayed, err := store.Do(ctx, principal, key, fingerprint, func() (recordedCall, bool) {
+			resp, err := handler(ctx, req)
+			return recordedCall{resp: resp, err: err}, !transientCodes[status.Code(err)]
+		})
+		if err != nil {
+			return nil, handleGRPCError(err)
+		}
+		if replayed {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedMetadata, "true"))
+			if m, ok := call.resp.(proto.Message); ok && call.err == nil {
+				return proto.Clone(m), nil
+			}
+		}
+		return call.resp, call.err
+	}
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
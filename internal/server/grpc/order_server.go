@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"context"
+
+	pb "go-grpc-rest-demo/api/gen/go/order/v1"
+	"go-grpc-rest-demo/internal/server/convert"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
+)
+
+type OrderServer struct {
+	pb.UnimplementedOrderServiceServer
+	orderService *service.OrderService
+}
+
+func NewOrderServer(orderService *service.OrderService) *OrderServer {
+	return &OrderServer{orderService: orderService}
+}
+
+func (s *OrderServer) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
+	modelReq := &model.CreateOrderRequest{
+		UserID: req.UserId,
+		Items:  make([]model.CreateOrderItem, len(req.Items)),
+	}
+	for i, item := range req.Items {
+		modelReq.Items[i] = model.CreateOrderItem{
+			ProductID: item.ProductId,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	order, err := s.orderService.CreateOrder(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.CreateOrderResponse{
+		Order:   convert.OrderToPB(order),
+		Message: "Order created successfully",
+	}, nil
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+	if req.Id == "" {
+		return nil, handleGRPCError(errors.NewValidationError("id", "id is required"))
+	}
+
+	order, err := s.orderService.GetOrder(ctx, req.Id)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.GetOrderResponse{
+		Order:   convert.OrderToPB(order),
+		Message: "Order retrieved successfully",
+	}, nil
+}
+
+func (s *OrderServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	modelReq := &model.ListOrdersRequest{
+		UserID:   req.UserId,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+
+	orders, totalCount, page, pageSize, err := s.orderService.ListOrders(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	pbOrders := make([]*pb.Order, len(orders))
+	for i := range orders {
+		pbOrders[i] = convert.OrderToPB(&orders[i])
+	}
+
+	return &pb.ListOrdersResponse{
+		Orders:     pbOrders,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *OrderServer) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	if req.Id == "" {
+		return nil, handleGRPCError(errors.NewValidationError("id", "id is required"))
+	}
+
+	order, err := s.orderService.CancelOrder(ctx, req.Id)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.CancelOrderResponse{
+		Order:   convert.OrderToPB(order),
+		Message: "Order cancelled successfully",
+	}, nil
+}
@@ -2,10 +2,13 @@ package grpc
 
 import (
 	"context"
-	"time"
+	"fmt"
+	"io"
 
 	pb "go-grpc-rest-demo/api/gen/go/product/v1"
+	"go-grpc-rest-demo/internal/server/convert"
 	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/export"
 	"go-grpc-rest-demo/internal/server/model"
 	"go-grpc-rest-demo/internal/server/service"
 )
@@ -19,36 +22,25 @@ func NewProductServer(productService *service.ProductService) *ProductServer {
 	return &ProductServer{productService: productService}
 }
 
-func productToPB(product *model.Product) *pb.Product {
-	return &pb.Product{
-		Id:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Quantity:    product.Quantity,
-		Category:    product.Category,
-		CreatedAt:   product.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   product.UpdatedAt.Format(time.RFC3339),
-	}
+var productEventTypes = map[model.EventType]pb.ProductEventType{
+	model.EventCreated: pb.ProductEventType_PRODUCT_EVENT_TYPE_CREATED,
+	model.EventUpdated: pb.ProductEventType_PRODUCT_EVENT_TYPE_UPDATED,
+	model.EventDeleted: pb.ProductEventType_PRODUCT_EVENT_TYPE_DELETED,
 }
 
-func (s *ProductServer) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
-	if req.Name == "" || req.Description == "" || req.Category == "" {
-		return nil, handleGRPCError(errors.NewValidationError("fields", "name, description, and category are required"))
-	}
-	if req.Price < 0 {
-		return nil, handleGRPCError(errors.NewValidationError("price", "price must be non-negative"))
-	}
-	if req.Quantity < 0 {
-		return nil, handleGRPCError(errors.NewValidationError("quantity", "quantity must be non-negative"))
+func productEventToPB(event *model.ProductEvent) *pb.WatchProductsResponse {
+	return &pb.WatchProductsResponse{
+		Sequence: event.Sequence,
+		Type:     productEventTypes[event.Type],
+		Product:  convert.ProductToPB(&event.Product),
+		Reason:   event.Reason,
 	}
+}
 
-	modelReq := &model.CreateProductRequest{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Quantity:    req.Quantity,
-		Category:    req.Category,
+func (s *ProductServer) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
+	modelReq, err := createProductRequestFromPB(req)
+	if err != nil {
+		return nil, handleGRPCError(err)
 	}
 
 	product, err := s.productService.CreateProduct(ctx, modelReq)
@@ -57,45 +49,140 @@ func (s *ProductServer) CreateProduct(ctx context.Context, req *pb.CreateProduct
 	}
 
 	return &pb.CreateProductResponse{
-		Product: productToPB(product),
+		Product: convert.ProductToPB(product),
 		Message: "Product created successfully",
 	}, nil
 }
 
 func (s *ProductServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.GetProductResponse, error) {
-	if req.Id == "" {
-		return nil, handleGRPCError(errors.NewValidationError("id", "id is required"))
-	}
-
 	product, err := s.productService.GetProduct(ctx, req.Id)
 	if err != nil {
 		return nil, handleGRPCError(err)
 	}
 
 	return &pb.GetProductResponse{
-		Product: productToPB(product),
+		Product: convert.ProductToPB(product),
 		Message: "Product retrieved successfully",
 	}, nil
 }
 
+func (s *ProductServer) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+	modelReq, err := updateProductRequestFromPB(req)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	product, err := s.productService.UpdateProduct(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.UpdateProductResponse{
+		Product: convert.ProductToPB(product),
+		Message: "Product updated successfully",
+	}, nil
+}
+
+func (s *ProductServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	if err := s.productService.DeleteProduct(ctx, req.Id); err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.DeleteProductResponse{
+		Message: "Product deleted successfully",
+	}, nil
+}
+
+func createProductRequestFromPB(req *pb.CreateProductRequest) (*model.CreateProductRequest, error) {
+	price, err := convert.MoneyFromPB(req.PriceMoney)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreateProductRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		PriceMoney:  price,
+		Quantity:    req.Quantity,
+		Category:    req.Category,
+		CategoryID:  req.CategoryId,
+	}, nil
+}
+
+func updateProductRequestFromPB(req *pb.UpdateProductRequest) (*model.UpdateProductRequest, error) {
+	price, err := convert.MoneyFromPB(req.PriceMoney)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UpdateProductRequest{
+		ID:          req.Id,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		PriceMoney:  price,
+		Quantity:    req.Quantity,
+		Category:    req.Category,
+		CategoryID:  req.CategoryId,
+	}, nil
+}
+
 func (s *ProductServer) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
+	minPrice, err := convert.MoneyFromPB(req.MinPriceMoney)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+	maxPrice, err := convert.MoneyFromPB(req.MaxPriceMoney)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+	timeRange, err := convert.TimeRangeFromPB(req.CreatedAfter, req.CreatedBefore, req.UpdatedAfter, req.UpdatedBefore)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+	facets, err := facetsFromPB(req.Facets)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+	bounds := make([]model.Money, len(req.PriceBucketBounds))
+	for i, bound := range req.PriceBucketBounds {
+		money, err := convert.MoneyFromPB(bound)
+		if err != nil {
+			return nil, handleGRPCError(err)
+		}
+		if money == nil {
+			return nil, handleGRPCError(errors.NewValidationError("price_bucket_bounds", "price_bucket_bounds cannot contain empty amounts"))
+		}
+		bounds[i] = *money
+	}
+
 	modelReq := &model.SearchProductsRequest{
-		Query:    req.Query,
-		Category: req.Category,
-		MinPrice: req.MinPrice,
-		MaxPrice: req.MaxPrice,
-		Page:     req.Page,
-		PageSize: req.PageSize,
+		Query:              req.Query,
+		Category:           req.Category,
+		MinPrice:           req.MinPrice,
+		MaxPrice:           req.MaxPrice,
+		Page:               req.Page,
+		PageSize:           req.PageSize,
+		CategoryID:         req.CategoryId,
+		IncludeDescendants: req.IncludeDescendants,
+		MinPriceMoney:      minPrice,
+		MaxPriceMoney:      maxPrice,
+		TimeRange:          timeRange,
+		Facets:             facets,
+		PriceBucketBounds:  bounds,
+		OrderBy:            req.OrderBy,
+		Filter:             req.Filter,
 	}
 
-	products, totalCount, page, pageSize, err := s.productService.SearchProducts(ctx, modelReq)
+	products, productFacets, totalCount, page, pageSize, err := s.productService.SearchProductsWithFacets(ctx, modelReq)
 	if err != nil {
 		return nil, handleGRPCError(err)
 	}
 
 	pbProducts := make([]*pb.Product, len(products))
 	for i := range products {
-		pbProducts[i] = productToPB(&products[i])
+		pbProducts[i] = convert.ProductToPB(&products[i])
 	}
 
 	return &pb.SearchProductsResponse{
@@ -103,5 +190,266 @@ func (s *ProductServer) SearchProducts(ctx context.Context, req *pb.SearchProduc
 		TotalCount: totalCount,
 		Page:       page,
 		PageSize:   pageSize,
+		Facets:     convert.ProductFacetsToPB(productFacets),
+	}, nil
+}
+
+var facetNames = map[pb.ProductFacet]string{
+	pb.ProductFacet_PRODUCT_FACET_CATEGORY: model.FacetCategory,
+	pb.ProductFacet_PRODUCT_FACET_PRICE:    model.FacetPrice,
+	pb.ProductFacet_PRODUCT_FACET_STOCK:    model.FacetStock,
+}
+
+func facetsFromPB(facets []pb.ProductFacet) ([]string, error) {
+	names := make([]string, len(facets))
+	for i, facet := range facets {
+		name, ok := facetNames[facet]
+		if !ok {
+			return nil, errors.NewValidationError("facets", fmt.Sprintf("unsupported facet %s", facet))
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+func (s *ProductServer) WatchProducts(req *pb.WatchProductsRequest, stream pb.ProductService_WatchProductsServer) error {
+	modelReq := &model.WatchProductsRequest{
+		Category:      req.Category,
+		SinceSequence: req.SinceSequence,
+	}
+
+	watcher, err := s.productService.WatchProducts(stream.Context(), modelReq)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	for event := range watcher.Events() {
+		if err := stream.Send(productEventToPB(&event)); err != nil {
+			return err
+		}
+	}
+	return handleGRPCError(watcher.Err())
+}
+
+func batchProductResultsToPB(results []model.BatchProductResult) []*pb.BatchProductResult {
+	pbResults := make([]*pb.BatchProductResult, len(results))
+	for i, result := range results {
+		pbResults[i] = &pb.BatchProductResult{Status: batchItemStatus(result.Error)}
+		if result.Product != nil {
+			pbResults[i].Product = convert.ProductToPB(result.Product)
+		}
+	}
+	return pbResults
+}
+
+func (s *ProductServer) BatchCreateProducts(ctx context.Context, req *pb.BatchCreateProductsRequest) (*pb.BatchCreateProductsResponse, error) {
+	modelReq := &model.BatchCreateProductsRequest{
+		Requests: make([]model.CreateProductRequest, len(req.Requests)),
+		Atomic:   req.Atomic,
+	}
+	for i, item := range req.Requests {
+		itemReq, err := createProductRequestFromPB(item)
+		if err != nil {
+			return nil, handleGRPCError(err)
+		}
+		modelReq.Requests[i] = *itemReq
+	}
+
+	results, err := s.productService.BatchCreateProducts(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.BatchCreateProductsResponse{Results: batchProductResultsToPB(results)}, nil
+}
+
+func (s *ProductServer) BatchGetProducts(ctx context.Context, req *pb.BatchGetProductsRequest) (*pb.BatchGetProductsResponse, error) {
+	modelReq := &model.BatchGetProductsRequest{
+		IDs:    req.Ids,
+		Atomic: req.Atomic,
+	}
+
+	results, err := s.productService.BatchGetProducts(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.BatchGetProductsResponse{Results: batchProductResultsToPB(results)}, nil
+}
+
+func (s *ProductServer) BatchUpdateProducts(ctx context.Context, req *pb.BatchUpdateProductsRequest) (*pb.BatchUpdateProductsResponse, error) {
+	modelReq := &model.BatchUpdateProductsRequest{
+		Requests: make([]model.UpdateProductRequest, len(req.Requests)),
+		Atomic:   req.Atomic,
+	}
+	for i, item := range req.Requests {
+		itemReq, err := updateProductRequestFromPB(item)
+		if err != nil {
+			return nil, handleGRPCError(err)
+		}
+		modelReq.Requests[i] = *itemReq
+	}
+
+	results, err := s.productService.BatchUpdateProducts(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.BatchUpdateProductsResponse{Results: batchProductResultsToPB(results)}, nil
+}
+
+var importFormats = map[pb.ImportFormat]string{
+	pb.ImportFormat_IMPORT_FORMAT_CSV:    model.ImportFormatCSV,
+	pb.ImportFormat_IMPORT_FORMAT_NDJSON: model.ImportFormatNDJSON,
+}
+
+// importStreamReader exposes the data chunks of an ImportProducts stream as an io.Reader
+type importStreamReader struct {
+	stream pb.ProductService_ImportProductsServer
+	buf    []byte
+}
+
+func (r *importStreamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = msg.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (s *ProductServer) ImportProducts(stream pb.ProductService_ImportProductsServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return handleGRPCError(errors.NewValidationError("data", "import stream is empty"))
+	}
+	if err != nil {
+		return err
+	}
+
+	format, ok := importFormats[first.Format]
+	if !ok {
+		return handleGRPCError(errors.NewValidationError("format", "format is required on the first message (csv or ndjson)"))
+	}
+
+	reader := &importStreamReader{stream: stream, buf: first.Data}
+	summary, err := s.productService.ImportProducts(stream.Context(), format, reader)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	failures := make([]*pb.ImportFailure, len(summary.Failures))
+	for i, failure := range summary.Failures {
+		failures[i] = &pb.ImportFailure{Line: failure.Line, Message: failure.Message}
+	}
+
+	return stream.SendAndClose(&pb.ImportProductsResponse{
+		Created:  summary.Created,
+		Updated:  summary.Updated,
+		Failed:   summary.Failed,
+		Failures: failures,
+	})
+}
+
+func (s *ProductServer) ExportProducts(req *pb.ExportProductsRequest, stream pb.ProductService_ExportProductsServer) error {
+	format, err := exportFormatFromPB(req.Format)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	send := chunkWriter(func(data []byte) error {
+		return stream.Send(&pb.ExportProductsResponse{Data: data})
+	})
+	enc, err := export.NewProductEncoder(format, send)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	timeRange, err := convert.TimeRangeFromPB(req.CreatedAfter, req.CreatedBefore, req.UpdatedAfter, req.UpdatedBefore)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	modelReq := &model.ExportProductsRequest{
+		Format:             format,
+		Query:              req.Query,
+		Category:           req.Category,
+		MinPrice:           req.MinPrice,
+		MaxPrice:           req.MaxPrice,
+		CategoryID:         req.CategoryId,
+		IncludeDescendants: req.IncludeDescendants,
+		TimeRange:          timeRange,
+		OrderBy:            req.OrderBy,
+		Filter:             req.Filter,
+	}
+	products, err := s.productService.ExportProducts(stream.Context(), modelReq)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	return export.Write(stream.Context(), enc, products)
+}
+
+func (s *ProductServer) AdjustStock(ctx context.Context, req *pb.AdjustStockRequest) (*pb.AdjustStockResponse, error) {
+	modelReq := &model.AdjustStockRequest{
+		ProductID: req.Id,
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+	}
+
+	product, err := s.productService.AdjustStock(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.AdjustStockResponse{
+		Product: convert.ProductToPB(product),
+		Message: "Stock adjusted successfully",
+	}, nil
+}
+
+func (s *ProductServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	modelReq := &model.ReserveStockRequest{
+		ProductID:  req.ProductId,
+		Quantity:   req.Quantity,
+		TTLSeconds: req.TtlSeconds,
+	}
+
+	reservation, err := s.productService.ReserveStock(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.ReserveStockResponse{
+		Reservation: convert.ReservationToPB(reservation),
+		Message:     "Stock reserved successfully",
+	}, nil
+}
+
+func (s *ProductServer) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+	reservation, err := s.productService.CommitReservation(ctx, req.ReservationId)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.CommitReservationResponse{
+		Reservation: convert.ReservationToPB(reservation),
+		Message:     "Reservation committed successfully",
+	}, nil
+}
+
+func (s *ProductServer) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	product, err := s.productService.ReleaseReservation(ctx, req.ReservationId)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.ReleaseReservationResponse{
+		Product: convert.ProductToPB(product),
+		Message: "Reservation released successfully",
 	}, nil
 }
@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/service"
+	"go-grpc-rest-demo/internal/server/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TenantMetadata names the tenant a call acts for
+const TenantMetadata = "x-tenant-id"
+
+// TenantUnaryInterceptor resolves the tenant of each unary call from its
+// x-tenant-id metadata and its bearer access token, and puts it in the
+// context the services read it from, along with the token's claims. Calls
+// naming an unknown tenant, or a tenant other than their token's, fail
+// before reaching a service.
+func TenantUnaryInterceptor(tenants *service.TenantService, authService *service.AuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := resolveTenant(ctx, tenants, authService)
+		if err != nil {
+			return nil, handleGRPCError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// TenantStreamInterceptor resolves the tenant of streaming calls like
+// TenantUnaryInterceptor does for unary ones
+func TenantStreamInterceptor(tenants *service.TenantService, authService *service.AuthService) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(ss.Context(), tenants, authService)
+		if err != nil {
+			return handleGRPCError(err)
+		}
+		return handler(srv, &tenantStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func resolveTenant(ctx context.Context, tenants *service.TenantService, authService *service.AuthService) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var tokenTenant string
+	if token, ok := strings.CutPrefix(firstValue(md, "authorization"), "Bearer "); ok {
+		claims, err := authService.VerifyAccessToken(token)
+		if err != nil {
+			return nil, err
+		}
+		tokenTenant = claims.Tenant
+		ctx = auth.NewContext(ctx, claims)
+	}
+
+	id, err := tenants.Resolve(firstValue(md, TenantMetadata), tokenTenant)
+	if err != nil {
+		return nil, err
+	}
+	return tenant.NewContext(ctx, id), nil
+}
+
+// tenantStream is a server stream whose context carries the resolved tenant
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
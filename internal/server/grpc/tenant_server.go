@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+
+	pb "go-grpc-rest-demo/api/gen/go/tenant/v1"
+	"go-grpc-rest-demo/internal/server/convert"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
+)
+
+type TenantServer struct {
+	pb.UnimplementedTenantServiceServer
+	tenantService *service.TenantService
+}
+
+func NewTenantServer(tenantService *service.TenantService) *TenantServer {
+	return &TenantServer{tenantService: tenantService}
+}
+
+func (s *TenantServer) CreateTenant(ctx context.Context, req *pb.CreateTenantRequest) (*pb.CreateTenantResponse, error) {
+	tenant, err := s.tenantService.CreateTenant(ctx, &model.CreateTenantRequest{
+		ID:          req.Id,
+		DisplayName: req.DisplayName,
+	})
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.CreateTenantResponse{
+		Tenant:  convert.TenantToPB(tenant),
+		Message: "Tenant created successfully",
+	}, nil
+}
+
+func (s *TenantServer) GetTenant(ctx context.Context, req *pb.GetTenantRequest) (*pb.GetTenantResponse, error) {
+	if req.Id == "" {
+		return nil, handleGRPCError(errors.NewValidationError("id", "id is required"))
+	}
+
+	tenant, err := s.tenantService.GetTenant(ctx, req.Id)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.GetTenantResponse{
+		Tenant:  convert.TenantToPB(tenant),
+		Message: "Tenant retrieved successfully",
+	}, nil
+}
+
+func (s *TenantServer) ListTenants(ctx context.Context, req *pb.ListTenantsRequest) (*pb.ListTenantsResponse, error) {
+	tenants, err := s.tenantService.ListTenants(ctx)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	pbTenants := make([]*pb.Tenant, len(tenants))
+	for i := range tenants {
+		pbTenants[i] = convert.TenantToPB(&tenants[i])
+	}
+	return &pb.ListTenantsResponse{Tenants: pbTenants}, nil
+}
+
+func (s *TenantServer) DeleteTenant(ctx context.Context, req *pb.DeleteTenantRequest) (*pb.DeleteTenantResponse, error) {
+	if req.Id == "" {
+		return nil, handleGRPCError(errors.NewValidationError("id", "id is required"))
+	}
+
+	if err := s.tenantService.DeleteTenant(ctx, req.Id); err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.DeleteTenantResponse{Message: "Tenant deleted successfully"}, nil
+}
@@ -2,10 +2,11 @@ package grpc
 
 import (
 	"context"
-	"time"
 
 	pb "go-grpc-rest-demo/api/gen/go/user/v1"
+	"go-grpc-rest-demo/internal/server/convert"
 	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/export"
 	"go-grpc-rest-demo/internal/server/model"
 	"go-grpc-rest-demo/internal/server/service"
 )
@@ -19,15 +20,17 @@ func NewUserServer(userService *service.UserService) *UserServer {
 	return &UserServer{userService: userService}
 }
 
-func userToPB(user *model.User) *pb.User {
-	return &pb.User{
-		Id:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+var userEventTypes = map[model.EventType]pb.UserEventType{
+	model.EventCreated: pb.UserEventType_USER_EVENT_TYPE_CREATED,
+	model.EventUpdated: pb.UserEventType_USER_EVENT_TYPE_UPDATED,
+	model.EventDeleted: pb.UserEventType_USER_EVENT_TYPE_DELETED,
+}
+
+func userEventToPB(event *model.UserEvent) *pb.WatchUsersResponse {
+	return &pb.WatchUsersResponse{
+		Sequence: event.Sequence,
+		Type:     userEventTypes[event.Type],
+		User:     convert.UserToPB(&event.User),
 	}
 }
 
@@ -44,7 +47,7 @@ func (s *UserServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 	}
 
 	return &pb.CreateUserResponse{
-		User:    userToPB(user),
+		User:    convert.UserToPB(user),
 		Message: "User created successfully",
 	}, nil
 }
@@ -60,7 +63,7 @@ func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.G
 	}
 
 	return &pb.GetUserResponse{
-		User:    userToPB(user),
+		User:    convert.UserToPB(user),
 		Message: "User retrieved successfully",
 	}, nil
 }
@@ -84,7 +87,7 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 	}
 
 	return &pb.UpdateUserResponse{
-		User:    userToPB(user),
+		User:    convert.UserToPB(user),
 		Message: "User updated successfully",
 	}, nil
 }
@@ -104,11 +107,18 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest)
 }
 
 func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	timeRange, err := convert.TimeRangeFromPB(req.CreatedAfter, req.CreatedBefore, req.UpdatedAfter, req.UpdatedBefore)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
 	modelReq := &model.ListUsersRequest{
-		Page:     req.Page,
-		PageSize: req.PageSize,
-		SortBy:   req.SortBy,
-		Filter:   req.Filter,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		SortBy:    req.SortBy,
+		Filter:    req.Filter,
+		TimeRange: timeRange,
+		OrderBy:   req.OrderBy,
 	}
 
 	users, totalCount, page, pageSize, err := s.userService.ListUsers(ctx, modelReq)
@@ -118,7 +128,7 @@ func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*
 
 	pbUsers := make([]*pb.User, len(users))
 	for i := range users {
-		pbUsers[i] = userToPB(&users[i])
+		pbUsers[i] = convert.UserToPB(&users[i])
 	}
 
 	return &pb.ListUsersResponse{
@@ -128,3 +138,176 @@ func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*
 		PageSize:   pageSize,
 	}, nil
 }
+
+func (s *UserServer) WatchUsers(req *pb.WatchUsersRequest, stream pb.UserService_WatchUsersServer) error {
+	modelReq := &model.WatchUsersRequest{
+		IsActive:      req.IsActive,
+		SinceSequence: req.SinceSequence,
+	}
+
+	watcher, err := s.userService.WatchUsers(stream.Context(), modelReq)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	for event := range watcher.Events() {
+		if err := stream.Send(userEventToPB(&event)); err != nil {
+			return err
+		}
+	}
+	return handleGRPCError(watcher.Err())
+}
+
+func batchUserResultsToPB(results []model.BatchUserResult) []*pb.BatchUserResult {
+	pbResults := make([]*pb.BatchUserResult, len(results))
+	for i, result := range results {
+		pbResults[i] = &pb.BatchUserResult{Status: batchItemStatus(result.Error)}
+		if result.User != nil {
+			pbResults[i].User = convert.UserToPB(result.User)
+		}
+	}
+	return pbResults
+}
+
+func (s *UserServer) BatchCreateUsers(ctx context.Context, req *pb.BatchCreateUsersRequest) (*pb.BatchCreateUsersResponse, error) {
+	modelReq := &model.BatchCreateUsersRequest{
+		Requests: make([]model.CreateUserRequest, len(req.Requests)),
+		Atomic:   req.Atomic,
+	}
+	for i, item := range req.Requests {
+		modelReq.Requests[i] = model.CreateUserRequest{
+			Username: item.Username,
+			Email:    item.Email,
+			FullName: item.FullName,
+		}
+	}
+
+	results, err := s.userService.BatchCreateUsers(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.BatchCreateUsersResponse{Results: batchUserResultsToPB(results)}, nil
+}
+
+func (s *UserServer) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	modelReq := &model.BatchGetUsersRequest{
+		IDs:    req.Ids,
+		Atomic: req.Atomic,
+	}
+
+	results, err := s.userService.BatchGetUsers(ctx, modelReq)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.BatchGetUsersResponse{Results: batchUserResultsToPB(results)}, nil
+}
+
+func (s *UserServer) ExportUsers(req *pb.ExportUsersRequest, stream pb.UserService_ExportUsersServer) error {
+	format, err := exportFormatFromPB(req.Format)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	send := chunkWriter(func(data []byte) error {
+		return stream.Send(&pb.ExportUsersResponse{Data: data})
+	})
+	enc, err := export.NewUserEncoder(format, send)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	timeRange, err := convert.TimeRangeFromPB(req.CreatedAfter, req.CreatedBefore, req.UpdatedAfter, req.UpdatedBefore)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	modelReq := &model.ExportUsersRequest{
+		Format:    format,
+		SortBy:    req.SortBy,
+		Filter:    req.Filter,
+		TimeRange: timeRange,
+		OrderBy:   req.OrderBy,
+	}
+	users, err := s.userService.ExportUsers(stream.Context(), modelReq)
+	if err != nil {
+		return handleGRPCError(err)
+	}
+
+	return export.Write(stream.Context(), enc, users)
+}
+
+func (s *UserServer) CheckUserPolicy(ctx context.Context, req *pb.CheckUserPolicyRequest) (*pb.CheckUserPolicyResponse, error) {
+	report, err := s.userService.CheckUserPolicy(ctx, req.Repair)
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	resp := &pb.CheckUserPolicyResponse{Checked: report.Checked, Repaired: report.Repaired}
+	for _, v := range report.Violations {
+		resp.Violations = append(resp.Violations, &pb.UserPolicyViolation{
+			UserId:          v.UserID,
+			Field:           v.Field,
+			Value:           v.Value,
+			Description:     v.Description,
+			NormalizedValue: v.NormalizedValue,
+			Repaired:        v.Repaired,
+		})
+	}
+	return resp, nil
+}
+
+func (s *UserServer) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	user, err := s.userService.VerifyEmail(ctx, &model.VerifyEmailRequest{Token: req.Token})
+	if err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.VerifyEmailResponse{
+		User:    convert.UserToPB(user),
+		Message: "Email verified successfully",
+	}, nil
+}
+
+func (s *UserServer) ResendVerification(ctx context.Context, req *pb.ResendVerificationRequest) (*pb.ResendVerificationResponse, error) {
+	if err := s.userService.ResendVerification(ctx, &model.ResendVerificationRequest{ID: req.Id}); err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.ResendVerificationResponse{
+		Message: "Verification email sent",
+	}, nil
+}
+
+func (s *UserServer) SetPassword(ctx context.Context, req *pb.SetPasswordRequest) (*pb.SetPasswordResponse, error) {
+	modelReq := &model.SetPasswordRequest{
+		ID:         req.Id,
+		Password:   req.Password,
+		SetupToken: req.SetupToken,
+	}
+
+	if err := s.userService.SetPassword(ctx, modelReq); err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.SetPasswordResponse{
+		Message: "Password set successfully",
+	}, nil
+}
+
+func (s *UserServer) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	modelReq := &model.ChangePasswordRequest{
+		ID:              req.Id,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	}
+
+	if err := s.userService.ChangePassword(ctx, modelReq); err != nil {
+		return nil, handleGRPCError(err)
+	}
+
+	return &pb.ChangePasswordResponse{
+		Message: "Password changed successfully",
+	}, nil
+}
@@ -4,22 +4,31 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	pb "go-grpc-rest-demo/api/gen/go/user/v1"
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/model"
 	"go-grpc-rest-demo/internal/server/service"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type UserServerTestSuite struct {
 	suite.Suite
-	server *UserServer
+	server      *UserServer
+	userService *service.UserService
 }
 
 func (suite *UserServerTestSuite) SetupTest() {
-	userService := service.NewUserService()
-	suite.server = NewUserServer(userService)
+	suite.userService = service.NewUserService()
+	suite.server = NewUserServer(suite.userService)
 }
 
 func (suite *UserServerTestSuite) TestCreateUser() {
@@ -181,6 +190,94 @@ func (suite *UserServerTestSuite) TestListUsers() {
 	assert.Equal(suite.T(), int32(3), listResp.PageSize)
 }
 
+func (suite *UserServerTestSuite) TestBatchCreateUsers() {
+	req := &pb.BatchCreateUsersRequest{
+		Requests: []*pb.CreateUserRequest{
+			{Username: "batch1", Email: "batch1@example.com", FullName: "Batch One"},
+			{Username: "batch1", Email: "batch2@example.com", FullName: "Batch Two"},
+		},
+	}
+
+	resp, err := suite.server.BatchCreateUsers(context.Background(), req)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), resp.Results, 2)
+	assert.Equal(suite.T(), int32(codes.OK), resp.Results[0].Status.Code)
+	assert.Equal(suite.T(), "batch1", resp.Results[0].User.Username)
+	assert.Equal(suite.T(), int32(codes.AlreadyExists), resp.Results[1].Status.Code)
+	assert.Nil(suite.T(), resp.Results[1].User)
+
+	req.Atomic = true
+	req.Requests[0].Username = "batch3"
+	_, err = suite.server.BatchCreateUsers(context.Background(), req)
+	assert.Equal(suite.T(), codes.AlreadyExists, status.Code(err))
+}
+
+func (suite *UserServerTestSuite) TestCreateUserReportsAllViolations() {
+	_, err := suite.server.CreateUser(context.Background(), &pb.CreateUserRequest{
+		Email: "not-an-email",
+	})
+
+	st, ok := status.FromError(err)
+	suite.Require().True(ok)
+	assert.Equal(suite.T(), codes.InvalidArgument, st.Code())
+	assert.Equal(suite.T(), "username is required; email must be a valid email address; full_name is required", st.Message())
+
+	suite.Require().Len(st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	suite.Require().True(ok)
+	var fields []string
+	for _, v := range badRequest.FieldViolations {
+		fields = append(fields, v.Field)
+	}
+	assert.Equal(suite.T(), []string{"username", "email", "full_name"}, fields)
+}
+
+func (suite *UserServerTestSuite) TestUsersAreScopedToTenant() {
+	products := service.NewProductService(service.NewCategoryService())
+	tenants := service.NewTenantService(suite.userService, products, service.NewOrderService(suite.userService, products))
+	_, err := tenants.CreateTenant(context.Background(), &model.CreateTenantRequest{ID: "acme", DisplayName: "Acme"})
+	suite.Require().NoError(err)
+	authService := service.NewAuthService(suite.userService, auth.NewTokenSigner([]byte("secret"), time.Minute), time.Hour)
+	interceptor := TenantUnaryInterceptor(tenants, authService)
+
+	call := func(tenantID string, req any, method func(context.Context, any) (any, error)) (any, error) {
+		ctx := context.Background()
+		if tenantID != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(TenantMetadata, tenantID))
+		}
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{}, method)
+	}
+	createUser := func(ctx context.Context, req any) (any, error) {
+		return suite.server.CreateUser(ctx, req.(*pb.CreateUserRequest))
+	}
+	getUser := func(ctx context.Context, req any) (any, error) {
+		return suite.server.GetUser(ctx, req.(*pb.GetUserRequest))
+	}
+	listUsers := func(ctx context.Context, req any) (any, error) {
+		return suite.server.ListUsers(ctx, req.(*pb.ListUsersRequest))
+	}
+
+	alice := &pb.CreateUserRequest{Username: "alice", Email: "alice@example.com", FullName: "Alice"}
+	resp, err := call("acme", alice, createUser)
+	suite.Require().NoError(err)
+	user := resp.(*pb.CreateUserResponse).User
+	assert.Equal(suite.T(), "acme", user.TenantId)
+	_, err = call("", alice, createUser)
+	assert.NoError(suite.T(), err)
+
+	_, err = call("acme", &pb.GetUserRequest{Id: user.Id}, getUser)
+	assert.NoError(suite.T(), err)
+	_, err = call("", &pb.GetUserRequest{Id: user.Id}, getUser)
+	assert.Equal(suite.T(), codes.NotFound, status.Code(err))
+
+	resp, err = call("acme", &pb.ListUsersRequest{}, listUsers)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int32(1), resp.(*pb.ListUsersResponse).TotalCount)
+
+	_, err = call("initech", &pb.ListUsersRequest{}, listUsers)
+	assert.Equal(suite.T(), codes.NotFound, status.Code(err))
+}
+
 func TestUserServerTestSuite(t *testing.T) {
 	suite.Run(t, new(UserServerTestSuite))
 }
\ No newline at end of file
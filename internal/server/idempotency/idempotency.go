@@ -0,0 +1,172 @@
+// Package idempotency remembers the outcome of requests carrying an
+// idempotency key so that a client retrying after a timeout gets the first
+// response again instead of repeating the side effect. Outcomes are kept per
+// principal and key for a fixed TTL.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// DefaultTTL is how long outcomes are kept when no TTL is configured
+const DefaultTTL = 24 * time.Hour
+
+// MaxKeyLength bounds the length of a client-supplied key
+const MaxKeyLength = 255
+
+// sweepInterval is how often expired outcomes are dropped
+const sweepInterval = time.Minute
+
+type entryKey struct {
+	principal string
+	key       string
+}
+
+type entry[V any] struct {
+	fingerprint [sha256.Size]byte
+	// done is closed once the first request has finished
+	done    chan struct{}
+	value   V
+	stored  bool
+	expires time.Time
+}
+
+// Store keeps the outcome of the first request made with each key
+type Store[V any] struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[entryKey]*entry[V]
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+func NewStore[V any](ttl time.Duration) *Store[V] {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Store[V]{
+		ttl:     ttl,
+		entries: make(map[entryKey]*entry[V]),
+		now:     time.Now,
+	}
+}
+
+// Do runs fn for the first request with a principal and key and returns its
+// result. A retry with the same fingerprint waits for the first request to
+// finish and gets its result with replayed set; one with a different
+// fingerprint fails with a validation error. fn reports whether its result
+// may be replayed: results that may not, such as server errors, are
+// forgotten so that a retry runs again.
+func (s *Store[V]) Do(ctx context.Context, principal, key string, fingerprint [sha256.Size]byte, fn func() (V, bool)) (result V, replayed bool, err error) {
+	k := entryKey{principal: principal, key: key}
+	for {
+		s.mu.Lock()
+		now := s.now()
+		s.sweepLocked(now)
+
+		e, exists := s.entries[k]
+		if !exists {
+			e = &entry[V]{fingerprint: fingerprint, done: make(chan struct{})}
+			s.entries[k] = e
+			s.mu.Unlock()
+			return s.run(k, e, fn), false, nil
+		}
+		s.mu.Unlock()
+
+		if e.fingerprint != fingerprint {
+			return result, false, errors.NewValidationError("idempotency_key", "idempotency key was already used for a different request")
+		}
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return result, false, ctx.Err()
+		}
+		if e.stored {
+			return e.value, true, nil
+		}
+		// The first request's outcome was not kept; run this one instead
+	}
+}
+
+// run executes the first request with a key. The entry is removed again
+// unless its outcome is kept, including when fn panics.
+func (s *Store[V]) run(k entryKey, e *entry[V], fn func() (V, bool)) V {
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !e.stored {
+			delete(s.entries, k)
+		}
+		close(e.done)
+	}()
+
+	value, keep := fn()
+	if keep {
+		s.mu.Lock()
+		e.value = value
+		e.stored = true
+		e.expires = s.now().Add(s.ttl)
+		s.mu.Unlock()
+	}
+	return value
+}
+
+// sweepLocked drops expired outcomes at most once per sweepInterval
+func (s *Store[V]) sweepLocked(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	for k, e := range s.entries {
+		if e.stored && !now.Before(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.nextSweep = now.Add(sweepInterval)
+}
+
+// ValidateKey rejects keys that are too long or contain characters other
+// than printable ASCII.
+func ValidateKey(key string) error {
+	if len(key) > MaxKeyLength {
+		return errors.NewValidationError("idempotency_key", "idempotency key must not be longer than 255 characters")
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return errors.NewValidationError("idempotency_key", "idempotency key must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
+
+// Fingerprint identifies a request by its parts, such as method, path and
+// body, so reusing a key for a different request can be detected.
+func Fingerprint(parts ...[]byte) [sha256.Size]byte {
+	h := sha256.New()
+	var size [8]byte
+	for _, part := range parts {
+		binary.BigEndian.PutUint64(size[:], uint64(len(part)))
+		h.Write(size[:])
+		h.Write(part)
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// Principal derives the namespace of a caller's keys from the credential it
+// presented, hashed so that credentials are not kept in memory. Callers
+// without a credential share the empty principal.
+func Principal(credential string) string {
+	if credential == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotencyTestSuite struct {
+	suite.Suite
+	store *Store[int]
+	now   time.Time
+	calls int
+}
+
+func (suite *IdempotencyTestSuite) SetupTest() {
+	suite.now = time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	suite.store = NewStore[int](time.Hour)
+	suite.store.now = func() time.Time { return suite.now }
+	suite.calls = 0
+}
+
+func (suite *IdempotencyTestSuite) do(principal, key, body string, keep bool) (int, bool, error) {
+	return suite.store.Do(context.Background(), principal, key, Fingerprint([]byte(body)), func() (int, bool) {
+		suite.calls++
+		return suite.calls, keep
+	})
+}
+
+func (suite *IdempotencyTestSuite) TestReplaysFirstResult() {
+	first, replayed, err := suite.do("alice", "k1", "body", true)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), replayed)
+
+	again, replayed, err := suite.do("alice", "k1", "body", true)
+	suite.Require().NoError(err)
+	assert.True(suite.T(), replayed)
+	assert.Equal(suite.T(), first, again)
+	assert.Equal(suite.T(), 1, suite.calls)
+
+	// Keys are scoped to the principal
+	other, replayed, err := suite.do("bob", "k1", "body", true)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), replayed)
+	assert.Equal(suite.T(), 2, other)
+}
+
+func (suite *IdempotencyTestSuite) TestRejectsDifferentRequest() {
+	_, _, err := suite.do("alice", "k1", "body", true)
+	suite.Require().NoError(err)
+
+	_, _, err = suite.do("alice", "k1", "other body", true)
+	suite.Require().Error(err)
+	appErr := errors.AsAppError(err)
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, appErr.Code)
+	assert.Equal(suite.T(), "idempotency_key", appErr.Field)
+}
+
+func (suite *IdempotencyTestSuite) TestRerunsResultsNotKept() {
+	_, _, err := suite.do("alice", "k1", "body", false)
+	suite.Require().NoError(err)
+
+	result, replayed, err := suite.do("alice", "k1", "body", true)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), replayed)
+	assert.Equal(suite.T(), 2, result)
+}
+
+func (suite *IdempotencyTestSuite) TestExpiresAfterTTL() {
+	_, _, err := suite.do("alice", "k1", "body", true)
+	suite.Require().NoError(err)
+
+	suite.now = suite.now.Add(time.Hour)
+	result, replayed, err := suite.do("alice", "k1", "other body", true)
+	suite.Require().NoError(err)
+	assert.False(suite.T(), replayed)
+	assert.Equal(suite.T(), 2, result)
+}
+
+func (suite *IdempotencyTestSuite) TestValidateKey() {
+	assert.NoError(suite.T(), ValidateKey("3f2a-retry_1"))
+	assert.Error(suite.T(), ValidateKey("has space"))
+	assert.Error(suite.T(), ValidateKey(string(make([]byte, MaxKeyLength+1))))
+}
+
+func TestIdempotencyTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyTestSuite))
+}
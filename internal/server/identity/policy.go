@@ -0,0 +1,124 @@
+// Package identity holds the rules usernames and emails must follow. Values
+// are stored in Unicode NFKC form; uniqueness is decided on their case-folded
+// keys, so "Alice" and "alice", or "A@x.com" and "a@x.com", are the same
+// identity.
+package identity
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Policy configures the usernames and emails a user may have
+type Policy struct {
+	// UsernameMinLength and UsernameMaxLength bound usernames in characters
+	UsernameMinLength int
+	UsernameMaxLength int
+	// ASCIIOnly restricts usernames to ASCII letters and digits; otherwise
+	// letters and digits of any script are allowed
+	ASCIIOnly bool
+	// ReservedUsernames cannot be taken, compared case-insensitively
+	ReservedUsernames []string
+	// AllowedEmailDomains, when not empty, lists the only domains emails may
+	// use, compared case-insensitively
+	AllowedEmailDomains []string
+}
+
+// usernameSeparators may appear inside a username, but not at its ends
+const usernameSeparators = "._-"
+
+// DefaultPolicy allows usernames of 3 to 32 letters, digits and separators
+// from any script, reserves administrative names and accepts any domain.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		UsernameMinLength: 3,
+		UsernameMaxLength: 32,
+		ReservedUsernames: []string{"admin", "administrator", "root", "system", "support", "api", "null"},
+	}
+}
+
+var folder = cases.Fold()
+
+// NormalizeUsername returns the form a username is stored in
+func NormalizeUsername(username string) string {
+	return norm.NFKC.String(strings.TrimSpace(username))
+}
+
+// NormalizeEmail returns the form an email is stored in. The local part
+// keeps its case; the domain is lower-cased.
+func NormalizeEmail(email string) string {
+	email = norm.NFKC.String(strings.TrimSpace(email))
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return email
+	}
+	return email[:at+1] + strings.ToLower(email[at+1:])
+}
+
+// Key is the case-folded form two usernames or emails are compared by
+func Key(value string) string {
+	return norm.NFKC.String(folder.String(norm.NFKC.String(value)))
+}
+
+// CheckUsername describes what is wrong with a normalized username, or
+// returns "" when the policy allows it.
+func (p *Policy) CheckUsername(username string) string {
+	length := utf8.RuneCountInString(username)
+	if length < p.UsernameMinLength || length > p.UsernameMaxLength {
+		return fmt.Sprintf("must be between %d and %d characters", p.UsernameMinLength, p.UsernameMaxLength)
+	}
+	for i, r := range username {
+		separator := strings.ContainsRune(usernameSeparators, r)
+		if separator && (i == 0 || i+utf8.RuneLen(r) == len(username)) {
+			return "must start and end with a letter or digit"
+		}
+		if !separator && !p.usernameRune(r) {
+			return fmt.Sprintf("may only contain %s and %q", p.charsetName(), usernameSeparators)
+		}
+	}
+	key := Key(username)
+	if slices.ContainsFunc(p.ReservedUsernames, func(reserved string) bool { return Key(reserved) == key }) {
+		return "is reserved"
+	}
+	return ""
+}
+
+func (p *Policy) usernameRune(r rune) bool {
+	if p.ASCIIOnly {
+		return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+func (p *Policy) charsetName() string {
+	if p.ASCIIOnly {
+		return "ASCII letters, digits"
+	}
+	return "letters, digits"
+}
+
+// CheckEmail describes what is wrong with a normalized email, or returns ""
+// when the policy allows it.
+func (p *Policy) CheckEmail(email string) string {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "must be a valid email address"
+	}
+	domain := email[strings.LastIndexByte(email, '@')+1:]
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 || slices.Contains(labels, "") {
+		return "must have a domain such as example.com"
+	}
+	if len(p.AllowedEmailDomains) > 0 &&
+		!slices.ContainsFunc(p.AllowedEmailDomains, func(allowed string) bool { return strings.EqualFold(allowed, domain) }) {
+		return fmt.Sprintf("must use one of the domains %s", strings.Join(p.AllowedEmailDomains, ", "))
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PolicyTestSuite struct {
+	suite.Suite
+	policy *Policy
+}
+
+func (suite *PolicyTestSuite) SetupTest() {
+	suite.policy = DefaultPolicy()
+}
+
+func (suite *PolicyTestSuite) TestNormalizeAndKey() {
+	assert.Equal(suite.T(), "Alice", NormalizeUsername(" Ａｌｉｃｅ "))
+	assert.Equal(suite.T(), "Jane.Doe@example.com", NormalizeEmail("Jane.Doe@EXAMPLE.Com"))
+	assert.Equal(suite.T(), Key("STRASSE"), Key("straße"))
+	assert.Equal(suite.T(), Key("A@x.com"), Key("a@X.COM"))
+	assert.NotEqual(suite.T(), Key("alice"), Key("alicia"))
+}
+
+func (suite *PolicyTestSuite) TestCheckUsername() {
+	tests := map[string]string{
+		"alice":        "",
+		"jean-luc.p_2": "",
+		"zoë":          "",
+		"ab":           "must be between 3 and 32 characters",
+		"_alice":       "must start and end with a letter or digit",
+		"alice.":       "must start and end with a letter or digit",
+		"al ice":       `may only contain letters, digits and "._-"`,
+		"ADMIN":        "is reserved",
+	}
+	for username, want := range tests {
+		assert.Equal(suite.T(), want, suite.policy.CheckUsername(username), username)
+	}
+
+	suite.policy.ASCIIOnly = true
+	assert.Equal(suite.T(), `may only contain ASCII letters, digits and "._-"`, suite.policy.CheckUsername("zoë"))
+}
+
+func (suite *PolicyTestSuite) TestCheckEmail() {
+	assert.Empty(suite.T(), suite.policy.CheckEmail("jane@example.com"))
+	assert.Equal(suite.T(), "must be a valid email address", suite.policy.CheckEmail("Jane <jane@example.com>"))
+	assert.Equal(suite.T(), "must have a domain such as example.com", suite.policy.CheckEmail("jane@localhost"))
+
+	suite.policy.AllowedEmailDomains = []string{"corp.com", "corp.io"}
+	assert.Empty(suite.T(), suite.policy.CheckEmail("jane@CORP.com"))
+	assert.Equal(suite.T(), "must use one of the domains corp.com, corp.io", suite.policy.CheckEmail("jane@example.com"))
+}
+
+func TestPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PolicyTestSuite))
+}
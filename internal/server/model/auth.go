@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+type LoginRequest struct {
+	// Username is the username or email of the user
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthTokens are issued on login. The access token is a short-lived HS256
+// JWT; the refresh token is opaque and can be exchanged once for new tokens.
+type AuthTokens struct {
+	AccessToken           string    `json:"access_token"`
+	TokenType             string    `json:"token_type"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	User                  *User     `json:"user"`
+}
+
+type AuthResponse struct {
+	Tokens     *AuthTokens             `json:"tokens,omitempty"`
+	Code       errors.ErrorCode        `json:"code,omitempty"`
+	Message    string                  `json:"message,omitempty"`
+	Violations []errors.FieldViolation `json:"violations,omitempty"`
+	Success    bool                    `json:"success,omitempty"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// Category is a node of the product category tree of a tenant. Slugs are
+// unique within the tenant's tree.
+type Category struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateCategoryRequest struct {
+	Name     string `json:"name"`
+	Slug     string `json:"slug,omitempty"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+type UpdateCategoryRequest struct {
+	ID   string  `json:"-"`
+	Name *string `json:"name,omitempty"`
+	Slug *string `json:"slug,omitempty"`
+	// An empty ParentID moves the category to the top level
+	ParentID *string `json:"parent_id,omitempty"`
+}
+
+type ListCategoriesRequest struct {
+	// When set, only direct children are listed; empty lists top-level categories
+	ParentID *string `json:"parent_id,omitempty" form:"parent_id"`
+}
+
+type CategoryMigrationSummary struct {
+	CategoriesCreated int32 `json:"categories_created"`
+	ProductsUpdated   int32 `json:"products_updated"`
+}
+
+type CategoryResponse struct {
+	Category   *Category                 `json:"category,omitempty"`
+	Categories []Category                `json:"categories,omitempty"`
+	Migration  *CategoryMigrationSummary `json:"migration,omitempty"`
+	Code       errors.ErrorCode          `json:"code,omitempty"`
+	Message    string                    `json:"message,omitempty"`
+	Violations []errors.FieldViolation   `json:"violations,omitempty"`
+}
@@ -0,0 +1,33 @@
+package model
+
+// EventType describes the kind of change carried by a watch event
+type EventType string
+
+const (
+	EventCreated EventType = "CREATED"
+	EventUpdated EventType = "UPDATED"
+	EventDeleted EventType = "DELETED"
+)
+
+type UserEvent struct {
+	Sequence int64     `json:"sequence"`
+	Type     EventType `json:"type"`
+	User     User      `json:"user"`
+}
+
+type ProductEvent struct {
+	Sequence int64     `json:"sequence"`
+	Type     EventType `json:"type"`
+	Product  Product   `json:"product"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+type WatchUsersRequest struct {
+	IsActive      *bool `json:"is_active,omitempty" form:"is_active"`
+	SinceSequence int64 `json:"since_sequence,omitempty" form:"since_sequence"`
+}
+
+type WatchProductsRequest struct {
+	Category      *string `json:"category,omitempty" form:"category"`
+	SinceSequence int64   `json:"since_sequence,omitempty" form:"since_sequence"`
+}
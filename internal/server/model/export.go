@@ -0,0 +1,33 @@
+package model
+
+// Supported encodings of a bulk export
+const (
+	ExportFormatNDJSON   = "ndjson"
+	ExportFormatCSV      = "csv"
+	ExportFormatProtobuf = "protobuf"
+)
+
+// ExportUsersRequest selects and orders users like ListUsersRequest
+type ExportUsersRequest struct {
+	Format    string  `json:"format,omitempty" form:"format"`
+	SortBy    *string `json:"sort_by,omitempty" form:"sort_by"`
+	Filter    *string `json:"filter,omitempty" form:"filter"`
+	TimeRange `form:"-"`
+	// OrderBy supersedes the deprecated single-field SortBy
+	OrderBy string `json:"order_by,omitempty" form:"order_by"`
+}
+
+// ExportProductsRequest selects and orders products like
+// SearchProductsRequest
+type ExportProductsRequest struct {
+	Format             string   `json:"format,omitempty" form:"format"`
+	Query              *string  `json:"query,omitempty" form:"query"`
+	Category           *string  `json:"category,omitempty" form:"category"`
+	MinPrice           *float64 `json:"min_price,omitempty" form:"min_price"`
+	MaxPrice           *float64 `json:"max_price,omitempty" form:"max_price"`
+	CategoryID         *string  `json:"category_id,omitempty" form:"category_id"`
+	IncludeDescendants bool     `json:"include_descendants,omitempty" form:"include_descendants"`
+	TimeRange          `form:"-"`
+	OrderBy            string  `json:"order_by,omitempty" form:"order_by"`
+	Filter             *string `json:"filter,omitempty" form:"filter"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+type AdjustStockRequest struct {
+	ProductID string `json:"-"`
+	Delta     int32  `json:"delta"`
+	Reason    string `json:"reason"`
+}
+
+type ReserveStockRequest struct {
+	ProductID  string `json:"-"`
+	Quantity   int32  `json:"quantity"`
+	TTLSeconds int32  `json:"ttl_seconds,omitempty"`
+}
+
+// Reservation holds stock of a product for a pending checkout. The reserved
+// quantity is taken out of the product's stock until the reservation is
+// released or expires.
+type Reservation struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	Quantity  int32     `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// StockLine is a quantity of one product taken out of or returned to stock
+type StockLine struct {
+	ProductID string `json:"product_id"`
+	Quantity  int32  `json:"quantity"`
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// DefaultCurrency is the currency of prices given only as a plain number
+const DefaultCurrency = "USD"
+
+const nanosPerUnit = 1_000_000_000
+
+var (
+	currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	amountPattern       = regexp.MustCompile(`^([+-]?)(\d+)(?:\.(\d{1,9}))?$`)
+	bigNanosPerUnit     = big.NewInt(nanosPerUnit)
+)
+
+// Money is an exact amount of a currency, stored like google.type.Money as
+// whole units plus nano units of the same sign. In JSON it is rendered as
+// {"currency_code": "USD", "amount": "19.99"} so no precision is lost; fields
+// of this type are documented with swaggertype:"object,string".
+type Money struct {
+	CurrencyCode string
+	Units        int64
+	Nanos        int32
+}
+
+// NewMoney validates units and nanos and normalizes the currency code
+func NewMoney(currencyCode string, units int64, nanos int32) (Money, error) {
+	code, err := normalizeCurrencyCode(currencyCode)
+	if err != nil {
+		return Money{}, err
+	}
+	if nanos <= -nanosPerUnit || nanos >= nanosPerUnit {
+		return Money{}, errors.NewValidationError("nanos", "nanos must be between -999999999 and 999999999")
+	}
+	if (units > 0 && nanos < 0) || (units < 0 && nanos > 0) {
+		return Money{}, errors.NewValidationError("nanos", "units and nanos must have the same sign")
+	}
+	return Money{CurrencyCode: code, Units: units, Nanos: nanos}, nil
+}
+
+// ParseMoney parses a decimal amount such as "19.99" with at most nine
+// fractional digits.
+func ParseMoney(currencyCode, amount string) (Money, error) {
+	code, err := normalizeCurrencyCode(currencyCode)
+	if err != nil {
+		return Money{}, err
+	}
+
+	match := amountPattern.FindStringSubmatch(strings.TrimSpace(amount))
+	if match == nil {
+		return Money{}, errors.NewValidationError("amount", fmt.Sprintf("invalid amount %q", amount))
+	}
+	nanos, _ := new(big.Int).SetString(match[2]+match[3]+strings.Repeat("0", 9-len(match[3])), 10)
+	if match[1] == "-" {
+		nanos.Neg(nanos)
+	}
+	return moneyFromNanos(code, nanos)
+}
+
+// MoneyFromFloat converts a legacy floating-point price, rounded to the nearest nano
+func MoneyFromFloat(currencyCode string, amount float64) (Money, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return Money{}, errors.NewValidationError("amount", "amount must be a finite number")
+	}
+	return ParseMoney(currencyCode, strconv.FormatFloat(amount, 'f', 9, 64))
+}
+
+func normalizeCurrencyCode(currencyCode string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currencyCode))
+	if !currencyCodePattern.MatchString(code) {
+		return "", errors.NewValidationError("currency_code", fmt.Sprintf("invalid currency code %q", currencyCode))
+	}
+	return code, nil
+}
+
+func (m Money) nanos() *big.Int {
+	n := new(big.Int).Mul(big.NewInt(m.Units), bigNanosPerUnit)
+	return n.Add(n, big.NewInt(int64(m.Nanos)))
+}
+
+func moneyFromNanos(currencyCode string, nanos *big.Int) (Money, error) {
+	units, rem := new(big.Int).QuoRem(nanos, bigNanosPerUnit, new(big.Int))
+	if !units.IsInt64() {
+		return Money{}, errors.NewValidationError("amount", "amount is out of range")
+	}
+	return Money{CurrencyCode: currencyCode, Units: units.Int64(), Nanos: int32(rem.Int64())}, nil
+}
+
+// Amount returns the amount as a plain decimal without trailing zeros, e.g. "19.99"
+func (m Money) Amount() string {
+	n := m.nanos()
+	sign := ""
+	if n.Sign() < 0 {
+		sign = "-"
+		n.Neg(n)
+	}
+	units, rem := new(big.Int).QuoRem(n, bigNanosPerUnit, new(big.Int))
+	frac := strings.TrimRight(fmt.Sprintf("%09d", rem.Int64()), "0")
+	if frac == "" {
+		return sign + units.String()
+	}
+	return sign + units.String() + "." + frac
+}
+
+func (m Money) String() string {
+	return m.Amount() + " " + m.CurrencyCode
+}
+
+// Float64 approximates the amount for the deprecated floating-point fields
+func (m Money) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(m.nanos(), bigNanosPerUnit).Float64()
+	return f
+}
+
+func (m Money) IsNegative() bool {
+	return m.Units < 0 || m.Nanos < 0
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or +1
+func (m Money) Cmp(other Money) int {
+	return m.nanos().Cmp(other.nanos())
+}
+
+// Add returns the exact sum of two amounts of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.CurrencyCode != other.CurrencyCode {
+		return Money{}, errors.NewFailedPreconditionError(
+			fmt.Sprintf("cannot add %s to %s", other.CurrencyCode, m.CurrencyCode))
+	}
+	return moneyFromNanos(m.CurrencyCode, new(big.Int).Add(m.nanos(), other.nanos()))
+}
+
+// Mul returns the exact amount multiplied by n
+func (m Money) Mul(n int64) (Money, error) {
+	return moneyFromNanos(m.CurrencyCode, new(big.Int).Mul(m.nanos(), big.NewInt(n)))
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		CurrencyCode string `json:"currency_code"`
+		Amount       string `json:"amount"`
+	}{m.CurrencyCode, m.Amount()})
+}
+
+// UnmarshalJSON accepts the amount as a decimal string or a JSON number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v struct {
+		CurrencyCode string      `json:"currency_code"`
+		Amount       json.Number `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	money, err := ParseMoney(v.CurrencyCode, v.Amount.String())
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
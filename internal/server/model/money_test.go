@@ -0,0 +1,79 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MoneyTestSuite struct {
+	suite.Suite
+}
+
+func (suite *MoneyTestSuite) TestParseMoney() {
+	money, err := ParseMoney("usd", "19.99")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), Money{CurrencyCode: "USD", Units: 19, Nanos: 990000000}, money)
+
+	money, err = ParseMoney("EUR", "-0.5")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), Money{CurrencyCode: "EUR", Units: 0, Nanos: -500000000}, money)
+	assert.Equal(suite.T(), "-0.5", money.Amount())
+
+	for _, amount := range []string{"", "1.", "1.0000000001", "1e3", "abc"} {
+		_, err = ParseMoney("USD", amount)
+		assert.Error(suite.T(), err, amount)
+	}
+	_, err = ParseMoney("US", "1")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *MoneyTestSuite) TestArithmeticIsExact() {
+	price, err := MoneyFromFloat("USD", 0.1)
+	assert.NoError(suite.T(), err)
+
+	total := Money{CurrencyCode: "USD"}
+	for range 3 {
+		total, err = total.Add(price)
+		assert.NoError(suite.T(), err)
+	}
+	assert.Equal(suite.T(), "0.3", total.Amount())
+
+	product, err := ParseMoney("USD", "19.99")
+	assert.NoError(suite.T(), err)
+	total, err = product.Mul(3)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "59.97", total.Amount())
+	assert.Equal(suite.T(), 1, total.Cmp(product))
+
+	_, err = total.Add(Money{CurrencyCode: "EUR", Units: 1})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *MoneyTestSuite) TestNewMoneyValidatesNanos() {
+	_, err := NewMoney("USD", 1, -1)
+	assert.Error(suite.T(), err)
+	_, err = NewMoney("USD", 0, 1_000_000_000)
+	assert.Error(suite.T(), err)
+
+	money, err := NewMoney("jpy", -3, -5)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "-3.000000005 JPY", money.String())
+}
+
+func (suite *MoneyTestSuite) TestJSON() {
+	data, err := json.Marshal(Money{CurrencyCode: "USD", Units: 20})
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), `{"currency_code":"USD","amount":"20"}`, string(data))
+
+	var money Money
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`{"currency_code":"EUR","amount":12.5}`), &money))
+	assert.Equal(suite.T(), Money{CurrencyCode: "EUR", Units: 12, Nanos: 500000000}, money)
+	assert.Error(suite.T(), json.Unmarshal([]byte(`{"currency_code":"EUR","amount":"12.5.1"}`), &money))
+}
+
+func TestMoneyTestSuite(t *testing.T) {
+	suite.Run(t, new(MoneyTestSuite))
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// OrderStatus is the lifecycle state of an order
+type OrderStatus string
+
+const (
+	OrderStatusPlaced    OrderStatus = "PLACED"
+	OrderStatusCancelled OrderStatus = "CANCELLED"
+)
+
+// OrderItem is one line of an order. Name and price are copied from the
+// product when the order is placed, so later product changes do not alter it.
+// UnitPrice is deprecated in favour of UnitPriceMoney.
+type OrderItem struct {
+	ProductID      string  `json:"product_id"`
+	ProductName    string  `json:"product_name"`
+	Quantity       int32   `json:"quantity"`
+	UnitPrice      float64 `json:"unit_price"`
+	UnitPriceMoney Money   `json:"unit_price_money" swaggertype:"object,string"`
+}
+
+// Order.TotalPrice is deprecated in favour of TotalPriceMoney, the exact sum
+// of the items, which all share one currency.
+type Order struct {
+	ID              string      `json:"id"`
+	TenantID        string      `json:"tenant_id"`
+	UserID          string      `json:"user_id"`
+	Items           []OrderItem `json:"items"`
+	TotalPrice      float64     `json:"total_price"`
+	TotalPriceMoney Money       `json:"total_price_money" swaggertype:"object,string"`
+	Status          OrderStatus `json:"status"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+type CreateOrderItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int32  `json:"quantity"`
+}
+
+type CreateOrderRequest struct {
+	UserID string            `json:"user_id"`
+	Items  []CreateOrderItem `json:"items"`
+}
+
+type ListOrdersRequest struct {
+	UserID   string `json:"user_id" form:"user_id"`
+	Page     int32  `json:"page" form:"page"`
+	PageSize int32  `json:"page_size" form:"page_size"`
+}
+
+type OrderResponse struct {
+	Order      *Order                  `json:"order,omitempty"`
+	Orders     []Order                 `json:"orders,omitempty"`
+	TotalCount int32                   `json:"total_count,omitempty"`
+	Page       int32                   `json:"page,omitempty"`
+	PageSize   int32                   `json:"page_size,omitempty"`
+	Code       errors.ErrorCode        `json:"code,omitempty"`
+	Message    string                  `json:"message,omitempty"`
+	Violations []errors.FieldViolation `json:"violations,omitempty"`
+}
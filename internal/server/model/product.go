@@ -2,25 +2,50 @@ package model
 
 import (
 	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
 )
 
+// Product.Price is deprecated in favour of PriceMoney and is kept populated
+// with the same amount.
 type Product struct {
 	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Price       float64   `json:"price"`
+	PriceMoney  Money     `json:"price_money" swaggertype:"object,string"`
 	Quantity    int32     `json:"quantity"`
 	Category    string    `json:"category"`
+	CategoryID  string    `json:"category_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// CreateProductRequest takes the price from PriceMoney when it is set, and
+// otherwise from the deprecated Price in DefaultCurrency. Either Category or
+// CategoryID is required; CategoryID takes precedence.
 type CreateProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description" binding:"required"`
-	Price       float64 `json:"price" binding:"required,min=0"`
-	Quantity    int32   `json:"quantity" binding:"required,min=0"`
-	Category    string  `json:"category" binding:"required"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	PriceMoney  *Money  `json:"price_money,omitempty" swaggertype:"object,string"`
+	Quantity    int32   `json:"quantity"`
+	Category    string  `json:"category"`
+	CategoryID  string  `json:"category_id,omitempty"`
+}
+
+// UpdateProductRequest takes the price from PriceMoney when it is set; the
+// deprecated Price keeps the product's currency.
+type UpdateProductRequest struct {
+	ID          string   `json:"id,omitempty"`
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Price       *float64 `json:"price,omitempty"`
+	PriceMoney  *Money   `json:"price_money,omitempty" swaggertype:"object,string"`
+	Quantity    *int32   `json:"quantity,omitempty"`
+	Category    *string  `json:"category,omitempty"`
+	CategoryID  *string  `json:"category_id,omitempty"`
 }
 
 type SearchProductsRequest struct {
@@ -30,13 +55,75 @@ type SearchProductsRequest struct {
 	MaxPrice *float64 `json:"max_price,omitempty" form:"max_price"`
 	Page     int32    `json:"page" form:"page"`
 	PageSize int32    `json:"page_size" form:"page_size"`
+	// CategoryID matches linked products, including those in descendant
+	// categories when IncludeDescendants is set
+	CategoryID         *string `json:"category_id,omitempty" form:"category_id"`
+	IncludeDescendants bool    `json:"include_descendants,omitempty" form:"include_descendants"`
+	// Exact price bounds; only products priced in the same currency match
+	MinPriceMoney *Money `json:"min_price_money,omitempty" form:"-"`
+	MaxPriceMoney *Money `json:"max_price_money,omitempty" form:"-"`
+	TimeRange
+	// Facets lists the facets to compute, see FacetCategory; price buckets
+	// default to DefaultPriceBucketBounds
+	Facets            []string `json:"facets,omitempty" form:"-"`
+	PriceBucketBounds []Money  `json:"price_bucket_bounds,omitempty" form:"-"`
+	// OrderBy is an AIP-132 clause such as "price desc, name"; products are
+	// ordered by name by default
+	OrderBy string `json:"order_by,omitempty" form:"order_by"`
+	// Filter is an AIP-160 expression such as "quantity > 0 AND name:pro"
+	Filter *string `json:"filter,omitempty" form:"filter"`
+}
+
+type BatchCreateProductsRequest struct {
+	Requests []CreateProductRequest `json:"requests"`
+	Atomic   bool                   `json:"atomic"`
+}
+
+type BatchGetProductsRequest struct {
+	IDs    []string `json:"ids" form:"ids"`
+	Atomic bool     `json:"atomic" form:"atomic"`
+}
+
+type BatchUpdateProductsRequest struct {
+	Requests []UpdateProductRequest `json:"requests"`
+	Atomic   bool                   `json:"atomic"`
+}
+
+// BatchProductResult is the outcome of one item of a batch request
+type BatchProductResult struct {
+	Product *Product         `json:"product,omitempty"`
+	Error   *errors.AppError `json:"error,omitempty"`
+}
+
+// Supported formats of a product import document
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+type ImportFailure struct {
+	Line    int32  `json:"line"`
+	Message string `json:"message"`
+}
+
+type ImportProductsSummary struct {
+	Created  int32           `json:"created"`
+	Updated  int32           `json:"updated"`
+	Failed   int32           `json:"failed"`
+	Failures []ImportFailure `json:"failures,omitempty"`
 }
 
 type ProductResponse struct {
-	Product    *Product  `json:"product,omitempty"`
-	Products   []Product `json:"products,omitempty"`
-	TotalCount int32     `json:"total_count,omitempty"`
-	Page       int32     `json:"page,omitempty"`
-	PageSize   int32     `json:"page_size,omitempty"`
-	Message    string    `json:"message,omitempty"`
-}
\ No newline at end of file
+	Product     *Product                `json:"product,omitempty"`
+	Products    []Product               `json:"products,omitempty"`
+	Results     []BatchProductResult    `json:"results,omitempty"`
+	Summary     *ImportProductsSummary  `json:"summary,omitempty"`
+	Reservation *Reservation            `json:"reservation,omitempty"`
+	Facets      *ProductFacets          `json:"facets,omitempty"`
+	TotalCount  int32                   `json:"total_count,omitempty"`
+	Page        int32                   `json:"page,omitempty"`
+	PageSize    int32                   `json:"page_size,omitempty"`
+	Code        errors.ErrorCode        `json:"code,omitempty"`
+	Message     string                  `json:"message,omitempty"`
+	Violations  []errors.FieldViolation `json:"violations,omitempty"`
+}
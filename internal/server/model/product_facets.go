@@ -0,0 +1,44 @@
+package model
+
+// Facet names accepted in SearchProductsRequest.Facets
+const (
+	FacetCategory = "category"
+	FacetPrice    = "price"
+	FacetStock    = "stock"
+)
+
+// DefaultPriceBucketBounds split prices into buckets when a price facet is
+// requested without bounds.
+var DefaultPriceBucketBounds = []Money{
+	{CurrencyCode: DefaultCurrency, Units: 10},
+	{CurrencyCode: DefaultCurrency, Units: 50},
+	{CurrencyCode: DefaultCurrency, Units: 100},
+	{CurrencyCode: DefaultCurrency, Units: 500},
+}
+
+type CategoryFacetCount struct {
+	Category   string `json:"category"`
+	CategoryID string `json:"category_id,omitempty"`
+	Count      int32  `json:"count"`
+}
+
+// PriceBucketCount counts the products priced from Min (inclusive) up to Max
+// (exclusive). The first bucket has no Min and the last no Max.
+type PriceBucketCount struct {
+	Min   *Money `json:"min,omitempty" swaggertype:"object,string"`
+	Max   *Money `json:"max,omitempty" swaggertype:"object,string"`
+	Count int32  `json:"count"`
+}
+
+type StockFacetCount struct {
+	InStock    int32 `json:"in_stock"`
+	OutOfStock int32 `json:"out_of_stock"`
+}
+
+// ProductFacets summarizes all products matching a search, not just the
+// returned page. Only the requested facets are set.
+type ProductFacets struct {
+	Categories   []CategoryFacetCount `json:"categories,omitempty"`
+	PriceBuckets []PriceBucketCount   `json:"price_buckets,omitempty"`
+	Stock        *StockFacetCount     `json:"stock,omitempty"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// Tenant is a team hosted on the server. Users and products belong to
+// exactly one tenant and are never visible to the others.
+type Tenant struct {
+	// ID is chosen at creation and names the tenant in requests
+	ID          string    `json:"id"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CreateTenantRequest struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+type TenantResponse struct {
+	Tenant     *Tenant                 `json:"tenant,omitempty"`
+	Tenants    []Tenant                `json:"tenants,omitempty"`
+	Code       errors.ErrorCode        `json:"code,omitempty"`
+	Message    string                  `json:"message,omitempty"`
+	Violations []errors.FieldViolation `json:"violations,omitempty"`
+}
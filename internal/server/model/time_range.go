@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// TimeRange filters resources by creation and update time. Each range is
+// half-open: the *After bound is inclusive and the *Before bound exclusive.
+type TimeRange struct {
+	CreatedAfter  *time.Time `json:"created_after,omitempty" form:"created_after"`
+	CreatedBefore *time.Time `json:"created_before,omitempty" form:"created_before"`
+	UpdatedAfter  *time.Time `json:"updated_after,omitempty" form:"updated_after"`
+	UpdatedBefore *time.Time `json:"updated_before,omitempty" form:"updated_before"`
+}
+
+// Validate rejects ranges that cannot match anything
+func (r TimeRange) Validate() error {
+	if r.CreatedAfter != nil && r.CreatedBefore != nil && !r.CreatedAfter.Before(*r.CreatedBefore) {
+		return errors.NewValidationError("created_before", "created_before must be later than created_after")
+	}
+	if r.UpdatedAfter != nil && r.UpdatedBefore != nil && !r.UpdatedAfter.Before(*r.UpdatedBefore) {
+		return errors.NewValidationError("updated_before", "updated_before must be later than updated_after")
+	}
+	return nil
+}
+
+// Contains reports whether a resource with the given times is in the range
+func (r TimeRange) Contains(createdAt, updatedAt time.Time) bool {
+	return inRange(createdAt, r.CreatedAfter, r.CreatedBefore) && inRange(updatedAt, r.UpdatedAfter, r.UpdatedBefore)
+}
+
+func inRange(t time.Time, after, before *time.Time) bool {
+	return (after == nil || !t.Before(*after)) && (before == nil || t.Before(*before))
+}
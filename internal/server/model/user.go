@@ -2,22 +2,33 @@ package model
 
 import (
 	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
 )
 
 type User struct {
-	ID        string    `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FullName  string    `json:"full_name"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID string `json:"id"`
+	// TenantID is the tenant the user belongs to; usernames and emails are
+	// unique within it
+	TenantID string `json:"tenant_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	IsActive bool   `json:"is_active"`
+	// EmailVerified is set once the user proved they receive mail at Email
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	// PasswordSetupToken is only set in the response creating the user. It
+	// lets its holder set the user's first password once, see
+	// SetPasswordRequest.
+	PasswordSetupToken string `json:"password_setup_token,omitempty"`
 }
 
 type CreateUserRequest struct {
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	FullName string `json:"full_name" binding:"required"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
 }
 
 type UpdateUserRequest struct {
@@ -28,19 +39,83 @@ type UpdateUserRequest struct {
 	IsActive *bool   `json:"is_active,omitempty"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationRequest struct {
+	ID string `json:"id"`
+}
+
+// SetPasswordRequest sets a password without the current one. Callers not
+// signed in as the user can only set the first password, and must pass the
+// user's PasswordSetupToken.
+type SetPasswordRequest struct {
+	ID         string `json:"id"`
+	Password   string `json:"password"`
+	SetupToken string `json:"setup_token,omitempty"`
+}
+
+type ChangePasswordRequest struct {
+	ID              string `json:"id"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
 type ListUsersRequest struct {
 	Page     int32   `json:"page" form:"page"`
 	PageSize int32   `json:"page_size" form:"page_size"`
 	SortBy   *string `json:"sort_by,omitempty" form:"sort_by"`
 	Filter   *string `json:"filter,omitempty" form:"filter"`
+	TimeRange
+	// OrderBy is an AIP-132 clause such as "created_at desc, username". It
+	// supersedes the deprecated single-field SortBy.
+	OrderBy string `json:"order_by,omitempty" form:"order_by"`
+}
+
+type BatchCreateUsersRequest struct {
+	Requests []CreateUserRequest `json:"requests"`
+	Atomic   bool                `json:"atomic"`
+}
+
+type BatchGetUsersRequest struct {
+	IDs    []string `json:"ids" form:"ids"`
+	Atomic bool     `json:"atomic" form:"atomic"`
+}
+
+// BatchUserResult is the outcome of one item of a batch request
+type BatchUserResult struct {
+	User  *User            `json:"user,omitempty"`
+	Error *errors.AppError `json:"error,omitempty"`
+}
+
+// UserPolicyViolation is a field of a stored user that breaks the identity
+// policy. NormalizedValue is set when normalizing the value repairs it.
+type UserPolicyViolation struct {
+	UserID          string `json:"user_id"`
+	Field           string `json:"field"`
+	Value           string `json:"value"`
+	Description     string `json:"description"`
+	NormalizedValue string `json:"normalized_value,omitempty"`
+	Repaired        bool   `json:"repaired,omitempty"`
+}
+
+type UserPolicyReport struct {
+	Checked    int32                 `json:"checked"`
+	Repaired   int32                 `json:"repaired"`
+	Violations []UserPolicyViolation `json:"violations,omitempty"`
 }
 
 type UserResponse struct {
-	User       *User  `json:"user,omitempty"`
-	Users      []User `json:"users,omitempty"`
-	TotalCount int32  `json:"total_count,omitempty"`
-	Page       int32  `json:"page,omitempty"`
-	PageSize   int32  `json:"page_size,omitempty"`
-	Message    string `json:"message,omitempty"`
-	Success    bool   `json:"success,omitempty"`
-}
\ No newline at end of file
+	User       *User                   `json:"user,omitempty"`
+	Users      []User                  `json:"users,omitempty"`
+	Results    []BatchUserResult       `json:"results,omitempty"`
+	Policy     *UserPolicyReport       `json:"policy,omitempty"`
+	TotalCount int32                   `json:"total_count,omitempty"`
+	Page       int32                   `json:"page,omitempty"`
+	PageSize   int32                   `json:"page_size,omitempty"`
+	Code       errors.ErrorCode        `json:"code,omitempty"`
+	Message    string                  `json:"message,omitempty"`
+	Violations []errors.FieldViolation `json:"violations,omitempty"`
+	Success    bool                    `json:"success,omitempty"`
+}
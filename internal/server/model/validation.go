@@ -0,0 +1,119 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/validation"
+)
+
+// Validation rules of the request types. Services check requests against
+// them with validation.Validate, so REST and gRPC calls fail the same way.
+func init() {
+	validation.Register(
+		validation.Field("username", func(r *CreateUserRequest) string { return r.Username }, validation.Required[string]()),
+		validation.Field("email", func(r *CreateUserRequest) string { return r.Email }, validation.Required[string](), validation.Email()),
+		validation.Field("full_name", func(r *CreateUserRequest) string { return r.FullName }, validation.Required[string]()),
+	)
+	validation.Register(
+		validation.Field("id", func(r *UpdateUserRequest) string { return r.ID }, validation.Required[string]()),
+		validation.Optional("username", func(r *UpdateUserRequest) *string { return r.Username }, validation.NotBlank()),
+		validation.Optional("email", func(r *UpdateUserRequest) *string { return r.Email }, validation.Email()),
+		validation.Optional("full_name", func(r *UpdateUserRequest) *string { return r.FullName }, validation.NotBlank()),
+	)
+	validation.Register(
+		validation.Field("token", func(r *VerifyEmailRequest) string { return r.Token }, validation.Required[string]()),
+	)
+	validation.Register(
+		validation.Field("id", func(r *ResendVerificationRequest) string { return r.ID }, validation.Required[string]()),
+	)
+	validation.Register(
+		validation.Field("id", func(r *SetPasswordRequest) string { return r.ID }, validation.Required[string]()),
+		validation.Field("password", func(r *SetPasswordRequest) string { return r.Password }, validation.Required[string]()),
+	)
+	validation.Register(
+		validation.Field("id", func(r *ChangePasswordRequest) string { return r.ID }, validation.Required[string]()),
+		validation.Field("current_password", func(r *ChangePasswordRequest) string { return r.CurrentPassword }, validation.Required[string]()),
+		validation.Field("new_password", func(r *ChangePasswordRequest) string { return r.NewPassword }, validation.Required[string]()),
+	)
+	validation.Register(
+		validation.Field("username", func(r *LoginRequest) string { return r.Username }, validation.Required[string]()),
+		validation.Field("password", func(r *LoginRequest) string { return r.Password }, validation.Required[string]()),
+	)
+	validation.Register(
+		validation.Field("refresh_token", func(r *RefreshTokenRequest) string { return r.RefreshToken }, validation.Required[string]()),
+	)
+
+	validation.Register(
+		validation.Field("name", func(r *CreateProductRequest) string { return r.Name }, validation.Required[string]()),
+		validation.Field("description", func(r *CreateProductRequest) string { return r.Description }, validation.Required[string]()),
+		validation.Assert("category", func(r *CreateProductRequest) bool { return r.Category != "" || r.CategoryID != "" },
+			"or category_id is required"),
+		validation.Field("price", func(r *CreateProductRequest) float64 { return r.Price }, validation.Min(0.0)),
+		validation.Optional("price_money", func(r *CreateProductRequest) *Money { return r.PriceMoney }, nonNegativeMoney()),
+		validation.Field("quantity", func(r *CreateProductRequest) int32 { return r.Quantity }, validation.Min[int32](0)),
+	)
+	validation.Register(
+		validation.Field("id", func(r *UpdateProductRequest) string { return r.ID }, validation.Required[string]()),
+		validation.Optional("name", func(r *UpdateProductRequest) *string { return r.Name }, validation.NotBlank()),
+		validation.Optional("description", func(r *UpdateProductRequest) *string { return r.Description }, validation.NotBlank()),
+		validation.Optional("price", func(r *UpdateProductRequest) *float64 { return r.Price }, validation.Min(0.0)),
+		validation.Optional("price_money", func(r *UpdateProductRequest) *Money { return r.PriceMoney }, nonNegativeMoney()),
+		validation.Optional("quantity", func(r *UpdateProductRequest) *int32 { return r.Quantity }, validation.Min[int32](0)),
+		validation.Optional("category", func(r *UpdateProductRequest) *string { return r.Category }, validation.NotBlank()),
+	)
+
+	validation.Register(
+		validation.Field("id", func(r *AdjustStockRequest) string { return r.ProductID }, validation.Required[string]()),
+		validation.Field("delta", func(r *AdjustStockRequest) int32 { return r.Delta }, validation.NonZero[int32]()),
+		validation.Field("reason", func(r *AdjustStockRequest) string { return r.Reason }, validation.NotBlank()),
+	)
+	validation.Register(
+		validation.Field("product_id", func(r *ReserveStockRequest) string { return r.ProductID }, validation.Required[string]()),
+		validation.Field("quantity", func(r *ReserveStockRequest) int32 { return r.Quantity }, validation.Min[int32](1)),
+	)
+
+	validation.Register(
+		validation.Field("name", func(r *CreateCategoryRequest) string { return strings.TrimSpace(r.Name) }, validation.Required[string]()),
+	)
+	validation.Register(
+		validation.Field("id", func(r *UpdateCategoryRequest) string { return r.ID }, validation.Required[string]()),
+		validation.Optional("name", func(r *UpdateCategoryRequest) *string { return r.Name }, validation.NotBlank()),
+	)
+
+	validation.Register(
+		validation.Field("product_id", func(r *CreateOrderItem) string { return r.ProductID }, validation.Required[string]()),
+		validation.Field("quantity", func(r *CreateOrderItem) int32 { return r.Quantity }, validation.Min[int32](1)),
+	)
+	validation.Register(
+		validation.Field("user_id", func(r *CreateOrderRequest) string { return r.UserID }, validation.Required[string]()),
+		validation.Assert("items", func(r *CreateOrderRequest) bool { return len(r.Items) > 0 }, "must not be empty"),
+		validation.Each("items", func(r *CreateOrderRequest) []CreateOrderItem { return r.Items }),
+	)
+
+	validation.Register(
+		validation.Field("id", func(r *CreateTenantRequest) string { return r.ID }, validation.Required[string](), tenantID()),
+		validation.Field("display_name", func(r *CreateTenantRequest) string { return r.DisplayName }, validation.NotBlank()),
+	)
+}
+
+// tenantIDPattern keeps tenant IDs safe to use in headers, metadata and URLs
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+func tenantID() validation.Check[string] {
+	return func(value string) string {
+		if !tenantIDPattern.MatchString(value) {
+			return "must be 1 to 63 lower case letters, digits and inner hyphens"
+		}
+		return ""
+	}
+}
+
+func nonNegativeMoney() validation.Check[Money] {
+	return func(value Money) string {
+		if value.IsNegative() {
+			return "must not be negative"
+		}
+		return ""
+	}
+}
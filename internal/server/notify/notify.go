@@ -0,0 +1,77 @@
+// Package notify delivers messages to users, such as email verification
+// links. Deployments plug in a Sender for their mail provider; LogSender and
+// FileSender keep messages local for development.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is an email to one recipient
+type Message struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender writes messages to the standard logger
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("notify: to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender appends messages to a file as JSON lines
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	return f.Close()
+}
+
+// NewSender creates the sender a sink describes: "log", or "file:<path>"
+func NewSender(sink string) (Sender, error) {
+	switch {
+	case sink == "" || sink == "log":
+		return LogSender{}, nil
+	case strings.HasPrefix(sink, "file:") && len(sink) > len("file:"):
+		return NewFileSender(strings.TrimPrefix(sink, "file:")), nil
+	}
+	return nil, fmt.Errorf("unsupported notification sink %q (use log or file:<path>)", sink)
+}
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	authService *service.AuthService
+}
+
+func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Check the username or email and password of a user of the tenant named by X-Tenant-ID (the default tenant when absent) and issue a short-lived access token (HS256 JWT) and a single-use refresh token. Inactive accounts are refused, and repeated failures lock the account for a while.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body model.LoginRequest true "Username or email and password"
+// @Success 200 {object} model.AuthResponse
+// @Failure 400 {object} model.AuthResponse
+// @Failure 401 {object} model.AuthResponse
+// @Failure 403 {object} model.AuthResponse
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleAuthError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	tokens, err := h.authService.Login(c.Request.Context(), &req)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	respondAuthSuccess(c, tokens, "Logged in successfully")
+}
+
+// RefreshToken godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once, and stops working when the user is deactivated or changes their password.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refresh body model.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} model.AuthResponse
+// @Failure 400 {object} model.AuthResponse
+// @Failure 401 {object} model.AuthResponse
+// @Failure 403 {object} model.AuthResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleAuthError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	tokens, err := h.authService.RefreshToken(c.Request.Context(), &req)
+	if err != nil {
+		handleAuthError(c, err)
+		return
+	}
+
+	respondAuthSuccess(c, tokens, "Tokens refreshed successfully")
+}
@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"net/http"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryService *service.CategoryService
+	productService  *service.ProductService
+}
+
+func NewCategoryHandler(categoryService *service.CategoryService, productService *service.ProductService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+		productService:  productService,
+	}
+}
+
+// CreateCategory godoc
+// @Summary Create a new category
+// @Description Create a category, optionally under a parent. The slug is derived from the name unless given and must be unique.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body model.CreateCategoryRequest true "Category information"
+// @Success 201 {object} model.CategoryResponse
+// @Failure 400 {object} model.CategoryResponse
+// @Failure 409 {object} model.CategoryResponse
+// @Router /categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req model.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleCategoryError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	respondCategorySuccess(c, http.StatusCreated, category)
+}
+
+// GetCategory godoc
+// @Summary Get category by ID
+// @Description Get a category by its ID
+// @Tags categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} model.CategoryResponse
+// @Failure 404 {object} model.CategoryResponse
+// @Router /categories/{id} [get]
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	category, err := h.categoryService.GetCategory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	respondCategorySuccess(c, http.StatusOK, category)
+}
+
+// UpdateCategory godoc
+// @Summary Update category
+// @Description Rename or move a category. Renaming also updates the category name of linked products; an empty parent_id moves the category to the top level.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param category body model.UpdateCategoryRequest true "Updated category information"
+// @Success 200 {object} model.CategoryResponse
+// @Failure 400 {object} model.CategoryResponse
+// @Failure 404 {object} model.CategoryResponse
+// @Failure 409 {object} model.CategoryResponse
+// @Router /categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	var req model.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleCategoryError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	req.ID = c.Param("id")
+	category, err := h.categoryService.UpdateCategory(c.Request.Context(), &req)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	respondCategorySuccess(c, http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete category
+// @Description Delete a category that has no child categories and no linked products
+// @Tags categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} model.CategoryResponse
+// @Failure 404 {object} model.CategoryResponse
+// @Failure 409 {object} model.CategoryResponse
+// @Router /categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	if err := h.categoryService.DeleteCategory(c.Request.Context(), c.Param("id")); err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.CategoryResponse{Message: "Category deleted successfully"})
+}
+
+// ListCategories godoc
+// @Summary List categories
+// @Description List all categories ordered by name, or only the direct children of parent_id. An empty parent_id lists the top-level categories.
+// @Tags categories
+// @Produce json
+// @Param parent_id query string false "Parent category ID"
+// @Success 200 {object} model.CategoryResponse
+// @Failure 404 {object} model.CategoryResponse
+// @Router /categories [get]
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	var req model.ListCategoriesRequest
+	if parentID, ok := c.GetQuery("parent_id"); ok {
+		req.ParentID = &parentID
+	}
+
+	categories, err := h.categoryService.ListCategories(c.Request.Context(), &req)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.CategoryResponse{
+		Categories: categories,
+		Message:    "Categories retrieved successfully",
+	})
+}
+
+// MigrateProductCategories godoc
+// @Summary Migrate legacy product categories
+// @Description Link every product that only has a category name to a category record, creating missing top-level categories. Names that differ only in case, spacing or punctuation share one category.
+// @Tags categories
+// @Produce json
+// @Success 200 {object} model.CategoryResponse
+// @Router /categories:migrate [post]
+func (h *CategoryHandler) MigrateProductCategories(c *gin.Context) {
+	summary, err := h.productService.MigrateCategories(c.Request.Context())
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.CategoryResponse{
+		Migration: summary,
+		Message:   "Product categories migrated successfully",
+	})
+}
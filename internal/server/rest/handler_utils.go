@@ -1,17 +1,33 @@
 package rest
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/export"
 	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 )
 
+// sseKeepAliveInterval keeps idle event streams open through proxies
+const sseKeepAliveInterval = 15 * time.Second
+
 func handleUserError(c *gin.Context, err error) {
 	appErr := errors.AsAppError(err)
 	c.JSON(appErr.ToHTTPStatus(), model.UserResponse{
-		Success: false,
-		Message: appErr.Message,
+		Success:    false,
+		Code:       appErr.Code,
+		Message:    appErr.Message,
+		Violations: appErr.Violations,
 	})
 }
 
@@ -26,7 +42,9 @@ func respondUserSuccess(c *gin.Context, statusCode int, user *model.User) {
 func handleProductError(c *gin.Context, err error) {
 	appErr := errors.AsAppError(err)
 	c.JSON(appErr.ToHTTPStatus(), model.ProductResponse{
-		Message: appErr.Message,
+		Code:       appErr.Code,
+		Message:    appErr.Message,
+		Violations: appErr.Violations,
 	})
 }
 
@@ -36,3 +54,195 @@ func respondProductSuccess(c *gin.Context, statusCode int, product *model.Produc
 		Message: "Operation successful",
 	})
 }
+
+func handleOrderError(c *gin.Context, err error) {
+	appErr := errors.AsAppError(err)
+	c.JSON(appErr.ToHTTPStatus(), model.OrderResponse{
+		Code:       appErr.Code,
+		Message:    appErr.Message,
+		Violations: appErr.Violations,
+	})
+}
+
+func respondOrderSuccess(c *gin.Context, statusCode int, order *model.Order) {
+	c.JSON(statusCode, model.OrderResponse{
+		Order:   order,
+		Message: "Operation successful",
+	})
+}
+
+func handleAuthError(c *gin.Context, err error) {
+	appErr := errors.AsAppError(err)
+	c.JSON(appErr.ToHTTPStatus(), model.AuthResponse{
+		Code:       appErr.Code,
+		Message:    appErr.Message,
+		Violations: appErr.Violations,
+	})
+}
+
+func respondAuthSuccess(c *gin.Context, tokens *model.AuthTokens, message string) {
+	c.JSON(http.StatusOK, model.AuthResponse{
+		Tokens:  tokens,
+		Message: message,
+		Success: true,
+	})
+}
+
+func handleTenantError(c *gin.Context, err error) {
+	appErr := errors.AsAppError(err)
+	c.JSON(appErr.ToHTTPStatus(), model.TenantResponse{
+		Code:       appErr.Code,
+		Message:    appErr.Message,
+		Violations: appErr.Violations,
+	})
+}
+
+func respondTenantSuccess(c *gin.Context, statusCode int, tenant *model.Tenant) {
+	c.JSON(statusCode, model.TenantResponse{
+		Tenant:  tenant,
+		Message: "Operation successful",
+	})
+}
+
+func handleCategoryError(c *gin.Context, err error) {
+	appErr := errors.AsAppError(err)
+	c.JSON(appErr.ToHTTPStatus(), model.CategoryResponse{
+		Code:       appErr.Code,
+		Message:    appErr.Message,
+		Violations: appErr.Violations,
+	})
+}
+
+func respondCategorySuccess(c *gin.Context, statusCode int, category *model.Category) {
+	c.JSON(statusCode, model.CategoryResponse{
+		Category: category,
+		Message:  "Operation successful",
+	})
+}
+
+// parseMoneyQuery reads an exact decimal amount from a query parameter,
+// returning nil when it is absent.
+func parseMoneyQuery(c *gin.Context, name, currency string) (*model.Money, error) {
+	amount := c.Query(name)
+	if amount == "" {
+		return nil, nil
+	}
+	money, err := model.ParseMoney(currency, amount)
+	if err != nil {
+		return nil, err
+	}
+	return &money, nil
+}
+
+// parsePriceBuckets reads the comma-separated price_buckets query parameter
+// as amounts in currency_code, or in the default currency when it is absent.
+func parsePriceBuckets(c *gin.Context) ([]model.Money, error) {
+	raw := c.Query("price_buckets")
+	if raw == "" {
+		return nil, nil
+	}
+	currency := c.DefaultQuery("currency_code", model.DefaultCurrency)
+	var bounds []model.Money
+	for _, amount := range strings.Split(raw, ",") {
+		bound, err := model.ParseMoney(currency, amount)
+		if err != nil {
+			return nil, errors.NewValidationError("price_buckets", fmt.Sprintf("invalid price bucket bound %q", amount))
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds, nil
+}
+
+// parseTimeRange reads the created_after, created_before, updated_after and
+// updated_before query parameters as RFC 3339 timestamps.
+func parseTimeRange(c *gin.Context) (model.TimeRange, error) {
+	var r model.TimeRange
+	bounds := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &r.CreatedAfter},
+		{"created_before", &r.CreatedBefore},
+		{"updated_after", &r.UpdatedAfter},
+		{"updated_before", &r.UpdatedBefore},
+	}
+	for _, bound := range bounds {
+		raw := c.Query(bound.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return model.TimeRange{}, errors.NewValidationError(bound.name, bound.name+" must be an RFC 3339 timestamp")
+		}
+		*bound.dst = &t
+	}
+	return r, nil
+}
+
+// parseSinceSequence reads the resume point of a watch from the
+// since_sequence query parameter, falling back to the Last-Event-ID header
+// that browsers send when reconnecting an EventSource.
+func parseSinceSequence(c *gin.Context) (int64, error) {
+	raw := c.Query("since_sequence")
+	if raw == "" {
+		raw = c.GetHeader("Last-Event-ID")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	since, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, errors.NewValidationError("since_sequence", "since_sequence must be an integer")
+	}
+	return since, nil
+}
+
+// streamEvents writes watcher events as Server-Sent Events until the watch
+// ends. The event sequence is sent as the SSE id so clients can resume.
+func streamEvents[E any](c *gin.Context, watcher *service.Watcher[E], describe func(E) (int64, model.EventType)) {
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	// The watcher's channel closes when the client disconnects, because the
+	// watch is bound to the request context.
+	for {
+		select {
+		case event, ok := <-watcher.Events():
+			if !ok {
+				if err := watcher.Err(); err != nil {
+					c.SSEvent("error", errors.AsAppError(err))
+					c.Writer.Flush()
+				}
+				return
+			}
+			seq, eventType := describe(event)
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatInt(seq, 10),
+				Event: strings.ToLower(string(eventType)),
+				Data:  event,
+			})
+		case <-keepAlive.C:
+			_, _ = io.WriteString(c.Writer, ": keep-alive\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+// streamExport writes an export snapshot as the response body. Once the
+// first bytes are sent the status can no longer change, so a failure part
+// way through is recorded on the context and the connection is cut short.
+func streamExport[T any](c *gin.Context, format string, enc export.Encoder[T], items []T) {
+	c.Header("Content-Type", export.ContentType(format))
+	c.Status(http.StatusOK)
+
+	if err := export.Write(c.Request.Context(), enc, items); err != nil && err != context.Canceled {
+		_ = c.Error(err)
+	}
+}
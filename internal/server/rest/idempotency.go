@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/idempotency"
+	"go-grpc-rest-demo/internal/server/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader makes a POST, PUT or DELETE safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed for a retry
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotentBodyBytes bounds the body buffered to fingerprint a request
+	maxIdempotentBodyBytes = 1 << 20
+)
+
+// recordedResponse is what is replayed for a retried request
+type recordedResponse struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// responseRecorder copies everything a handler writes
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware replays the first response to a POST, PUT or DELETE
+// for retries that carry the same Idempotency-Key. Keys are scoped to the
+// tenant and the caller's Authorization header, so it must run after
+// tenantMiddleware; reusing a key for a different method, path or body is
+// rejected. Server errors are not replayed, so such requests can be
+// retried with the same key. Streaming routes, named by their full path, are
+// passed through, as their bodies are too large to buffer.
+func idempotencyMiddleware(store *idempotency.Store[recordedResponse], streamingRoutes ...string) gin.HandlerFunc {
+	streaming := make(map[string]bool, len(streamingRoutes))
+	for _, route := range streamingRoutes {
+		streaming[route] = true
+	}
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		method := c.Request.Method
+		if key == "" || (method != http.MethodPost && method != http.MethodPut && method != http.MethodDelete) || streaming[c.FullPath()] {
+			c.Next()
+			return
+		}
+		if err := idempotency.ValidateKey(key); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if tooLarge := new(http.MaxBytesError); stderrors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    errors.ErrCodeInvalidRequest,
+				"message": fmt.Sprintf("request body exceeds the %d bytes an idempotent request may have", tooLarge.Limit),
+			})
+			return
+		}
+		if err != nil {
+			abortWithError(c, errors.NewInvalidRequestError("failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := idempotency.Fingerprint([]byte(method), []byte(c.Request.URL.RequestURI()), body)
+		principal := tenant.FromContext(c.Request.Context()) + ":" + idempotency.Principal(c.GetHeader("Authorization"))
+		resp, replayed, err := store.Do(c.Request.Context(), principal, key, fingerprint, func() (recordedResponse, bool) {
+			recorder := &responseRecorder{ResponseWriter: c.Writer}
+			c.Writer = recorder
+			c.Next()
+			c.Writer = recorder.ResponseWriter
+
+			status := recorder.Status()
+			return recordedResponse{
+				status:      status,
+				contentType: recorder.Header().Get("Content-Type"),
+				body:        recorder.body.Bytes(),
+			}, status < http.StatusInternalServerError
+		})
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if replayed {
+			c.Header(IdempotentReplayedHeader, strconv.FormatBool(true))
+			c.Data(resp.status, resp.contentType, resp.body)
+			c.Abort()
+		}
+	}
+}
+
+func abortWithError(c *gin.Context, err error) {
+	appErr := errors.AsAppError(err)
+	c.AbortWithStatusJSON(appErr.ToHTTPStatus(), gin.H{"code": appErr.Code, "message": appErr.Message})
+}
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrderHandler struct {
+	orderService *service.OrderService
+}
+
+func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+	}
+}
+
+// CreateOrder godoc
+// @Summary Create a new order
+// @Description Place an order for an active user. Stock is taken for all items atomically and product prices are recorded on the order.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param order body model.CreateOrderRequest true "Order information"
+// @Success 201 {object} model.OrderResponse
+// @Failure 400 {object} model.OrderResponse
+// @Failure 404 {object} model.OrderResponse
+// @Failure 409 {object} model.OrderResponse
+// @Router /orders [post]
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req model.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleOrderError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	order, err := h.orderService.CreateOrder(c.Request.Context(), &req)
+	if err != nil {
+		handleOrderError(c, err)
+		return
+	}
+
+	respondOrderSuccess(c, http.StatusCreated, order)
+}
+
+// GetOrder godoc
+// @Summary Get order by ID
+// @Description Get an order by its ID
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} model.OrderResponse
+// @Failure 404 {object} model.OrderResponse
+// @Router /orders/{id} [get]
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	order, err := h.orderService.GetOrder(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleOrderError(c, err)
+		return
+	}
+
+	respondOrderSuccess(c, http.StatusOK, order)
+}
+
+// ListOrders godoc
+// @Summary List orders of a user
+// @Description Get a paginated list of a user's orders, oldest first
+// @Tags orders
+// @Produce json
+// @Param user_id query string true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Items per page" default(10)
+// @Success 200 {object} model.OrderResponse
+// @Failure 400 {object} model.OrderResponse
+// @Router /orders [get]
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 32)
+	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 32)
+
+	req := &model.ListOrdersRequest{
+		UserID:   c.Query("user_id"),
+		Page:     int32(page),
+		PageSize: int32(pageSize),
+	}
+
+	orders, totalCount, retPage, retPageSize, err := h.orderService.ListOrders(c.Request.Context(), req)
+	if err != nil {
+		handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.OrderResponse{
+		Orders:     orders,
+		TotalCount: totalCount,
+		Page:       retPage,
+		PageSize:   retPageSize,
+		Message:    "Orders retrieved successfully",
+	})
+}
+
+// CancelOrder godoc
+// @Summary Cancel an order
+// @Description Cancel a placed order and restore the stock of its items
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} model.OrderResponse
+// @Failure 404 {object} model.OrderResponse
+// @Failure 409 {object} model.OrderResponse
+// @Router /orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	order, err := h.orderService.CancelOrder(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleOrderError(c, err)
+		return
+	}
+
+	respondOrderSuccess(c, http.StatusOK, order)
+}
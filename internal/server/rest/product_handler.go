@@ -1,10 +1,13 @@
 package rest
 
 import (
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/export"
 	"go-grpc-rest-demo/internal/server/model"
 	"go-grpc-rest-demo/internal/server/service"
 
@@ -74,26 +77,107 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	respondProductSuccess(c, http.StatusOK, product)
 }
 
+// UpdateProduct godoc
+// @Summary Update product
+// @Description Update a product by its ID
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param product body model.UpdateProductRequest true "Updated product information"
+// @Success 200 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
+// @Router /products/{id} [put]
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		handleProductError(c, errors.NewValidationError("id", "Product ID is required"))
+		return
+	}
+
+	var req model.UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleProductError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	req.ID = id
+	product, err := h.productService.UpdateProduct(c.Request.Context(), &req)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	respondProductSuccess(c, http.StatusOK, product)
+}
+
+// DeleteProduct godoc
+// @Summary Delete product
+// @Description Delete a product by its ID, releasing its open reservations
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
+// @Router /products/{id} [delete]
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		handleProductError(c, errors.NewValidationError("id", "Product ID is required"))
+		return
+	}
+
+	if err := h.productService.DeleteProduct(c.Request.Context(), id); err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	respondProductSuccess(c, http.StatusOK, nil)
+}
+
 // SearchProducts godoc
 // @Summary Search products
 // @Description Search products with optional filters
 // @Tags products
 // @Produce json
 // @Param query query string false "Search query (matches name or description)"
-// @Param category query string false "Filter by category"
-// @Param min_price query number false "Minimum price filter"
-// @Param max_price query number false "Maximum price filter"
+// @Param category query string false "Filter by legacy category name"
+// @Param category_id query string false "Filter by category ID"
+// @Param include_descendants query bool false "Also match products in descendants of category_id"
+// @Param min_price query string false "Minimum price filter"
+// @Param max_price query string false "Maximum price filter"
+// @Param currency_code query string false "Compare min_price and max_price exactly in this currency; other currencies never match"
+// @Param created_after query string false "Only products created at or after this RFC 3339 time"
+// @Param created_before query string false "Only products created before this RFC 3339 time"
+// @Param updated_after query string false "Only products updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only products updated before this RFC 3339 time"
+// @Param filter query string false "AIP-160 filter, e.g. \"quantity > 0 AND name:pro\"; a bare value matches name or description"
+// @Param order_by query string false "AIP-132 ordering, e.g. \"price desc, name\" (id, name, price, quantity, category, created_at, updated_at; default name)"
+// @Param facets query string false "Comma-separated facets to compute over all matches (category, price, stock)"
+// @Param price_buckets query string false "Comma-separated ascending price bucket bounds in currency_code (default 10,50,100,500 USD)"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Items per page" default(10)
 // @Success 200 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
 // @Router /products/search [get]
 func (h *ProductHandler) SearchProducts(c *gin.Context) {
 	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 32)
 	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 32)
 
+	timeRange, err := parseTimeRange(c)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
 	req := &model.SearchProductsRequest{
-		Page:     int32(page),
-		PageSize: int32(pageSize),
+		Page:      int32(page),
+		PageSize:  int32(pageSize),
+		TimeRange: timeRange,
+		OrderBy:   c.Query("order_by"),
 	}
 
 	if query := c.Query("query"); query != "" {
@@ -104,19 +188,48 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 		req.Category = &category
 	}
 
-	if minPriceStr := c.Query("min_price"); minPriceStr != "" {
-		if minPrice, err := strconv.ParseFloat(minPriceStr, 64); err == nil {
-			req.MinPrice = &minPrice
-		}
+	if filter := c.Query("filter"); filter != "" {
+		req.Filter = &filter
 	}
 
-	if maxPriceStr := c.Query("max_price"); maxPriceStr != "" {
-		if maxPrice, err := strconv.ParseFloat(maxPriceStr, 64); err == nil {
-			req.MaxPrice = &maxPrice
+	// With a currency the bounds are exact amounts in that currency
+	if currency := c.Query("currency_code"); currency != "" {
+		if req.MinPriceMoney, err = parseMoneyQuery(c, "min_price", currency); err != nil {
+			handleProductError(c, err)
+			return
+		}
+		if req.MaxPriceMoney, err = parseMoneyQuery(c, "max_price", currency); err != nil {
+			handleProductError(c, err)
+			return
 		}
+	} else {
+		if minPriceStr := c.Query("min_price"); minPriceStr != "" {
+			if minPrice, err := strconv.ParseFloat(minPriceStr, 64); err == nil {
+				req.MinPrice = &minPrice
+			}
+		}
+
+		if maxPriceStr := c.Query("max_price"); maxPriceStr != "" {
+			if maxPrice, err := strconv.ParseFloat(maxPriceStr, 64); err == nil {
+				req.MaxPrice = &maxPrice
+			}
+		}
+	}
+
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		req.CategoryID = &categoryID
+		req.IncludeDescendants, _ = strconv.ParseBool(c.Query("include_descendants"))
+	}
+
+	if facets := c.Query("facets"); facets != "" {
+		req.Facets = strings.Split(facets, ",")
+	}
+	if req.PriceBucketBounds, err = parsePriceBuckets(c); err != nil {
+		handleProductError(c, err)
+		return
 	}
 
-	products, totalCount, retPage, retPageSize, err := h.productService.SearchProducts(c.Request.Context(), req)
+	products, facets, totalCount, retPage, retPageSize, err := h.productService.SearchProductsWithFacets(c.Request.Context(), req)
 	if err != nil {
 		handleProductError(c, err)
 		return
@@ -124,9 +237,333 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 
 	c.JSON(http.StatusOK, model.ProductResponse{
 		Products:   products,
+		Facets:     facets,
 		TotalCount: totalCount,
 		Page:       retPage,
 		PageSize:   retPageSize,
 		Message:    "Products retrieved successfully",
 	})
 }
+
+// WatchProducts godoc
+// @Summary Watch product changes
+// @Description Stream product create, update and delete events as Server-Sent Events
+// @Tags products
+// @Produce text/event-stream
+// @Param category query string false "Only emit events for products in this category"
+// @Param since_sequence query int false "Resume after this sequence number (defaults to Last-Event-ID header)"
+// @Success 200 {object} model.ProductEvent
+// @Failure 400 {object} model.ProductResponse
+// @Router /products:watch [get]
+func (h *ProductHandler) WatchProducts(c *gin.Context) {
+	req := &model.WatchProductsRequest{}
+
+	if category := c.Query("category"); category != "" {
+		req.Category = &category
+	}
+
+	since, err := parseSinceSequence(c)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+	req.SinceSequence = since
+
+	watcher, err := h.productService.WatchProducts(c.Request.Context(), req)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	streamEvents(c, watcher, func(event model.ProductEvent) (int64, model.EventType) {
+		return event.Sequence, event.Type
+	})
+}
+
+// BatchCreateProducts godoc
+// @Summary Create products in batch
+// @Description Create several products in one call. With atomic=true either all products are created or none; otherwise each result reports its own error.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param batch body model.BatchCreateProductsRequest true "Products to create"
+// @Success 200 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Router /products:batchCreate [post]
+func (h *ProductHandler) BatchCreateProducts(c *gin.Context) {
+	var req model.BatchCreateProductsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleProductError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	results, err := h.productService.BatchCreateProducts(c.Request.Context(), &req)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ProductResponse{
+		Results: results,
+		Message: "Batch processed",
+	})
+}
+
+// BatchGetProducts godoc
+// @Summary Get products in batch
+// @Description Get several products by ID. With atomic=true a missing product fails the whole call.
+// @Tags products
+// @Produce json
+// @Param ids query []string true "Product IDs" collectionFormat(multi)
+// @Param atomic query bool false "Fail if any product is missing"
+// @Success 200 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
+// @Router /products:batchGet [get]
+func (h *ProductHandler) BatchGetProducts(c *gin.Context) {
+	var req model.BatchGetProductsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleProductError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	results, err := h.productService.BatchGetProducts(c.Request.Context(), &req)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ProductResponse{
+		Results: results,
+		Message: "Batch processed",
+	})
+}
+
+// BatchUpdateProducts godoc
+// @Summary Update products in batch
+// @Description Update several products in one call. With atomic=true either all products are updated or none; otherwise each result reports its own error.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param batch body model.BatchUpdateProductsRequest true "Product updates, each with its id"
+// @Success 200 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
+// @Router /products:batchUpdate [post]
+func (h *ProductHandler) BatchUpdateProducts(c *gin.Context) {
+	var req model.BatchUpdateProductsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleProductError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	results, err := h.productService.BatchUpdateProducts(c.Request.Context(), &req)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ProductResponse{
+		Results: results,
+		Message: "Batch processed",
+	})
+}
+
+// importContentTypes maps request media types to import formats
+var importContentTypes = map[string]string{
+	"text/csv":             model.ImportFormatCSV,
+	"application/x-ndjson": model.ImportFormatNDJSON,
+	"application/jsonl":    model.ImportFormatNDJSON,
+}
+
+// ImportProducts godoc
+// @Summary Import products
+// @Description Upsert products from a CSV (with header row) or NDJSON body, matching existing products by name and category
+// @Tags products
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "Document format (csv or ndjson); defaults to the Content-Type"
+// @Param data body string true "CSV or NDJSON document"
+// @Success 200 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Router /products:import [post]
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		format = importContentTypes[mediaType]
+	}
+	if format == "" {
+		handleProductError(c, errors.NewValidationError("format", "set format=csv|ndjson or a text/csv or application/x-ndjson Content-Type"))
+		return
+	}
+
+	summary, err := h.productService.ImportProducts(c.Request.Context(), format, c.Request.Body)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ProductResponse{
+		Summary: summary,
+		Message: "Import completed",
+	})
+}
+
+// ExportProducts godoc
+// @Summary Export products
+// @Description Stream a point-in-time snapshot of all matching products as NDJSON, CSV or length-delimited protobuf
+// @Tags products
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/x-protobuf
+// @Param format query string false "Export format (ndjson, csv, protobuf)" default(ndjson)
+// @Param query query string false "Search query (matches name or description)"
+// @Param category query string false "Filter by legacy category name"
+// @Param category_id query string false "Filter by category ID"
+// @Param include_descendants query bool false "Also match products in descendants of category_id"
+// @Param min_price query number false "Minimum price filter"
+// @Param max_price query number false "Maximum price filter"
+// @Param created_after query string false "Only products created at or after this RFC 3339 time"
+// @Param created_before query string false "Only products created before this RFC 3339 time"
+// @Param updated_after query string false "Only products updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only products updated before this RFC 3339 time"
+// @Param filter query string false "AIP-160 filter, e.g. \"quantity > 0 AND name:pro\"; a bare value matches name or description"
+// @Param order_by query string false "AIP-132 ordering, e.g. \"price desc, name\" (id, name, price, quantity, category, created_at, updated_at; default name)"
+// @Success 200 {string} string "Encoded products"
+// @Failure 400 {object} model.ProductResponse
+// @Router /products:export [get]
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	var req model.ExportProductsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleProductError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+	timeRange, err := parseTimeRange(c)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+	req.TimeRange = timeRange
+
+	enc, err := export.NewProductEncoder(req.Format, c.Writer)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	products, err := h.productService.ExportProducts(c.Request.Context(), &req)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	streamExport(c, req.Format, enc, products)
+}
+
+// AdjustStock godoc
+// @Summary Adjust product stock
+// @Description Atomically change a product's quantity by a signed delta. The quantity never goes below zero.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param adjustment body model.AdjustStockRequest true "Delta and reason"
+// @Success 200 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
+// @Failure 409 {object} model.ProductResponse
+// @Router /products/{id}/stock [post]
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	var req model.AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleProductError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	req.ProductID = c.Param("id")
+	product, err := h.productService.AdjustStock(c.Request.Context(), &req)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	respondProductSuccess(c, http.StatusOK, product)
+}
+
+// ReserveStock godoc
+// @Summary Reserve product stock
+// @Description Hold stock for a pending checkout. The reservation expires after ttl_seconds (default 900) unless committed or released.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param reservation body model.ReserveStockRequest true "Quantity and lifetime"
+// @Success 201 {object} model.ProductResponse
+// @Failure 400 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
+// @Failure 409 {object} model.ProductResponse
+// @Router /products/{id}/reservations [post]
+func (h *ProductHandler) ReserveStock(c *gin.Context) {
+	var req model.ReserveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleProductError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	req.ProductID = c.Param("id")
+	reservation, err := h.productService.ReserveStock(c.Request.Context(), &req)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.ProductResponse{
+		Reservation: reservation,
+		Message:     "Stock reserved successfully",
+	})
+}
+
+// CommitReservation godoc
+// @Summary Commit a reservation
+// @Description Finalize a reservation; the held stock stays consumed
+// @Tags inventory
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
+// @Failure 409 {object} model.ProductResponse
+// @Router /reservations/{id}/commit [post]
+func (h *ProductHandler) CommitReservation(c *gin.Context) {
+	reservation, err := h.productService.CommitReservation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ProductResponse{
+		Reservation: reservation,
+		Message:     "Reservation committed successfully",
+	})
+}
+
+// ReleaseReservation godoc
+// @Summary Release a reservation
+// @Description Cancel a reservation and return its stock to the product
+// @Tags inventory
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} model.ProductResponse
+// @Failure 404 {object} model.ProductResponse
+// @Failure 409 {object} model.ProductResponse
+// @Router /reservations/{id}/release [post]
+func (h *ProductHandler) ReleaseReservation(c *gin.Context) {
+	product, err := h.productService.ReleaseReservation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	respondProductSuccess(c, http.StatusOK, product)
+}
@@ -1,6 +1,9 @@
 package rest
 
 import (
+	"time"
+
+	"go-grpc-rest-demo/internal/server/idempotency"
 	"go-grpc-rest-demo/internal/server/service"
 
 	"github.com/gin-gonic/gin"
@@ -8,15 +11,23 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter(userService *service.UserService, productService *service.ProductService) *gin.Engine {
+func SetupRouter(userService *service.UserService, productService *service.ProductService, orderService *service.OrderService, categoryService *service.CategoryService, authService *service.AuthService, tenantService *service.TenantService, idempotencyTTL time.Duration) *gin.Engine {
 	r := gin.Default()
 
 	// Initialize handlers
 	userHandler := NewUserHandler(userService)
 	productHandler := NewProductHandler(productService)
+	orderHandler := NewOrderHandler(orderService)
+	categoryHandler := NewCategoryHandler(categoryService, productService)
+	authHandler := NewAuthHandler(authService)
+	tenantHandler := NewTenantHandler(tenantService)
 
 	// API v1 group
 	v1 := r.Group("/api/v1")
+	v1.Use(
+		tenantMiddleware(tenantService, authService),
+		idempotencyMiddleware(idempotency.NewStore[recordedResponse](idempotencyTTL), "/api/v1/products:import"),
+	)
 	{
 		// Health check
 		v1.GET("/health", func(c *gin.Context) {
@@ -26,6 +37,23 @@ func SetupRouter(userService *service.UserService, productService *service.Produ
 			})
 		})
 
+		// Change feeds (Server-Sent Events)
+		v1.GET("/users\\:watch", userHandler.WatchUsers)
+		v1.GET("/products\\:watch", productHandler.WatchProducts)
+
+		// Batch and bulk operations
+		v1.POST("/users\\:batchCreate", userHandler.BatchCreateUsers)
+		v1.GET("/users\\:batchGet", userHandler.BatchGetUsers)
+		v1.POST("/products\\:batchCreate", productHandler.BatchCreateProducts)
+		v1.GET("/products\\:batchGet", productHandler.BatchGetProducts)
+		v1.POST("/products\\:batchUpdate", productHandler.BatchUpdateProducts)
+		v1.POST("/products\\:import", productHandler.ImportProducts)
+		v1.GET("/users\\:export", userHandler.ExportUsers)
+		v1.GET("/products\\:export", productHandler.ExportProducts)
+		v1.POST("/categories\\:migrate", categoryHandler.MigrateProductCategories)
+		v1.POST("/users\\:checkPolicy", userHandler.CheckUserPolicy)
+		v1.POST("/users\\:verifyEmail", userHandler.VerifyEmail)
+
 		// User routes
 		users := v1.Group("/users")
 		{
@@ -34,6 +62,25 @@ func SetupRouter(userService *service.UserService, productService *service.Produ
 			users.GET("/:id", userHandler.GetUser)
 			users.PUT("/:id", userHandler.UpdateUser)
 			users.DELETE("/:id", userHandler.DeleteUser)
+			users.POST("/:id/resendVerification", userHandler.ResendVerification)
+			users.PUT("/:id/password", userHandler.SetPassword)
+			users.POST("/:id/changePassword", userHandler.ChangePassword)
+		}
+
+		// Tenant routes
+		tenants := v1.Group("/tenants")
+		{
+			tenants.POST("", tenantHandler.CreateTenant)
+			tenants.GET("", tenantHandler.ListTenants)
+			tenants.GET("/:id", tenantHandler.GetTenant)
+			tenants.DELETE("/:id", tenantHandler.DeleteTenant)
+		}
+
+		// Auth routes
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.RefreshToken)
 		}
 
 		// Product routes
@@ -42,6 +89,36 @@ func SetupRouter(userService *service.UserService, productService *service.Produ
 			products.POST("", productHandler.CreateProduct)
 			products.GET("/search", productHandler.SearchProducts) // Must come before /:id
 			products.GET("/:id", productHandler.GetProduct)
+			products.PUT("/:id", productHandler.UpdateProduct)
+			products.DELETE("/:id", productHandler.DeleteProduct)
+			products.POST("/:id/stock", productHandler.AdjustStock)
+			products.POST("/:id/reservations", productHandler.ReserveStock)
+		}
+
+		// Category routes
+		categories := v1.Group("/categories")
+		{
+			categories.POST("", categoryHandler.CreateCategory)
+			categories.GET("", categoryHandler.ListCategories)
+			categories.GET("/:id", categoryHandler.GetCategory)
+			categories.PUT("/:id", categoryHandler.UpdateCategory)
+			categories.DELETE("/:id", categoryHandler.DeleteCategory)
+		}
+
+		// Order routes
+		orders := v1.Group("/orders")
+		{
+			orders.POST("", orderHandler.CreateOrder)
+			orders.GET("", orderHandler.ListOrders)
+			orders.GET("/:id", orderHandler.GetOrder)
+			orders.POST("/:id/cancel", orderHandler.CancelOrder)
+		}
+
+		// Reservation routes
+		reservations := v1.Group("/reservations")
+		{
+			reservations.POST("/:id/commit", productHandler.CommitReservation)
+			reservations.POST("/:id/release", productHandler.ReleaseReservation)
 		}
 	}
 
@@ -49,4 +126,4 @@ func SetupRouter(userService *service.UserService, productService *service.Produ
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return r
-}
\ No newline at end of file
+}
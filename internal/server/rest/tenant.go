@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/service"
+	"go-grpc-rest-demo/internal/server/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantHeader names the tenant a request acts for
+const TenantHeader = "X-Tenant-ID"
+
+// tenantMiddleware resolves the tenant of each request from its X-Tenant-ID
+// header and its bearer access token, and puts it in the request context the
+// services read it from, along with the token's claims. Requests naming an
+// unknown tenant, or a tenant other than their token's, fail before reaching
+// a handler.
+func tenantMiddleware(tenants *service.TenantService, authService *service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		var tokenTenant string
+		if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			claims, err := authService.VerifyAccessToken(token)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			tokenTenant = claims.Tenant
+			ctx = auth.NewContext(ctx, claims)
+		}
+
+		id, err := tenants.Resolve(c.GetHeader(TenantHeader), tokenTenant)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.Request = c.Request.WithContext(tenant.NewContext(ctx, id))
+		c.Next()
+	}
+}
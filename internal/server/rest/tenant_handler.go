@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"net/http"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	tenantService *service.TenantService
+}
+
+func NewTenantHandler(tenantService *service.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+	}
+}
+
+// CreateTenant godoc
+// @Summary Create a new tenant
+// @Description Create a tenant with a chosen ID of 1 to 63 lower case letters, digits and inner hyphens. Requests act for it by sending its ID in the X-Tenant-ID header.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param tenant body model.CreateTenantRequest true "Tenant information"
+// @Success 201 {object} model.TenantResponse
+// @Failure 400 {object} model.TenantResponse
+// @Failure 409 {object} model.TenantResponse
+// @Router /tenants [post]
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req model.CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleTenantError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	tenant, err := h.tenantService.CreateTenant(c.Request.Context(), &req)
+	if err != nil {
+		handleTenantError(c, err)
+		return
+	}
+
+	respondTenantSuccess(c, http.StatusCreated, tenant)
+}
+
+// GetTenant godoc
+// @Summary Get tenant by ID
+// @Description Get a tenant by its ID
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} model.TenantResponse
+// @Failure 404 {object} model.TenantResponse
+// @Router /tenants/{id} [get]
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	tenant, err := h.tenantService.GetTenant(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleTenantError(c, err)
+		return
+	}
+
+	respondTenantSuccess(c, http.StatusOK, tenant)
+}
+
+// ListTenants godoc
+// @Summary List tenants
+// @Description List all tenants ordered by ID
+// @Tags tenants
+// @Produce json
+// @Success 200 {object} model.TenantResponse
+// @Router /tenants [get]
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantService.ListTenants(c.Request.Context())
+	if err != nil {
+		handleTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TenantResponse{
+		Tenants: tenants,
+		Message: "Tenants retrieved successfully",
+	})
+}
+
+// DeleteTenant godoc
+// @Summary Delete tenant
+// @Description Delete a tenant that has no users, products, categories or orders. The default tenant cannot be deleted.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} model.TenantResponse
+// @Failure 404 {object} model.TenantResponse
+// @Failure 409 {object} model.TenantResponse
+// @Router /tenants/{id} [delete]
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	if err := h.tenantService.DeleteTenant(c.Request.Context(), c.Param("id")); err != nil {
+		handleTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TenantResponse{Message: "Tenant deleted successfully"})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/export"
 	"go-grpc-rest-demo/internal/server/model"
 	"go-grpc-rest-demo/internal/server/service"
 )
@@ -141,17 +142,31 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Items per page" default(10)
-// @Param sort_by query string false "Sort by field (username, email, full_name, created_at)"
-// @Param filter query string false "Filter by username, email, or full_name"
+// @Param order_by query string false "AIP-132 ordering, e.g. \"created_at desc, username\" (id, username, email, full_name, created_at, updated_at)"
+// @Param sort_by query string false "Deprecated: use order_by. Sort by a single field"
+// @Param filter query string false "AIP-160 filter, e.g. `is_active = false AND email = \"*@corp.com\"`; a bare value matches username, email or full_name"
+// @Param created_after query string false "Only users created at or after this RFC 3339 time"
+// @Param created_before query string false "Only users created before this RFC 3339 time"
+// @Param updated_after query string false "Only users updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only users updated before this RFC 3339 time"
 // @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.UserResponse
 // @Router /users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 32)
 	pageSize, _ := strconv.ParseInt(c.DefaultQuery("page_size", "10"), 10, 32)
 
+	timeRange, err := parseTimeRange(c)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
 	req := &model.ListUsersRequest{
-		Page:     int32(page),
-		PageSize: int32(pageSize),
+		Page:      int32(page),
+		PageSize:  int32(pageSize),
+		TimeRange: timeRange,
+		OrderBy:   c.Query("order_by"),
 	}
 
 	if sortBy := c.Query("sort_by"); sortBy != "" {
@@ -175,4 +190,301 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		PageSize:   retPageSize,
 		Message:    "Users retrieved successfully",
 	})
-}
\ No newline at end of file
+}
+
+// WatchUsers godoc
+// @Summary Watch user changes
+// @Description Stream user create, update and delete events as Server-Sent Events
+// @Tags users
+// @Produce text/event-stream
+// @Param is_active query bool false "Only emit events for users with this active state"
+// @Param since_sequence query int false "Resume after this sequence number (defaults to Last-Event-ID header)"
+// @Success 200 {object} model.UserEvent
+// @Failure 400 {object} model.UserResponse
+// @Router /users:watch [get]
+func (h *UserHandler) WatchUsers(c *gin.Context) {
+	req := &model.WatchUsersRequest{}
+
+	if isActiveStr := c.Query("is_active"); isActiveStr != "" {
+		isActive, err := strconv.ParseBool(isActiveStr)
+		if err != nil {
+			handleUserError(c, errors.NewValidationError("is_active", "is_active must be a boolean"))
+			return
+		}
+		req.IsActive = &isActive
+	}
+
+	since, err := parseSinceSequence(c)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+	req.SinceSequence = since
+
+	watcher, err := h.userService.WatchUsers(c.Request.Context(), req)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	streamEvents(c, watcher, func(event model.UserEvent) (int64, model.EventType) {
+		return event.Sequence, event.Type
+	})
+}
+
+// BatchCreateUsers godoc
+// @Summary Create users in batch
+// @Description Create several users in one call. With atomic=true either all users are created or none; otherwise each result reports its own error.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param batch body model.BatchCreateUsersRequest true "Users to create"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.UserResponse
+// @Failure 409 {object} model.UserResponse
+// @Router /users:batchCreate [post]
+func (h *UserHandler) BatchCreateUsers(c *gin.Context) {
+	var req model.BatchCreateUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleUserError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	results, err := h.userService.BatchCreateUsers(c.Request.Context(), &req)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.UserResponse{
+		Success: true,
+		Results: results,
+		Message: "Batch processed",
+	})
+}
+
+// BatchGetUsers godoc
+// @Summary Get users in batch
+// @Description Get several users by ID. With atomic=true a missing user fails the whole call.
+// @Tags users
+// @Produce json
+// @Param ids query []string true "User IDs" collectionFormat(multi)
+// @Param atomic query bool false "Fail if any user is missing"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.UserResponse
+// @Failure 404 {object} model.UserResponse
+// @Router /users:batchGet [get]
+func (h *UserHandler) BatchGetUsers(c *gin.Context) {
+	var req model.BatchGetUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleUserError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	results, err := h.userService.BatchGetUsers(c.Request.Context(), &req)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.UserResponse{
+		Success: true,
+		Results: results,
+		Message: "Batch processed",
+	})
+}
+
+// ExportUsers godoc
+// @Summary Export users
+// @Description Stream a point-in-time snapshot of all matching users as NDJSON, CSV or length-delimited protobuf
+// @Tags users
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/x-protobuf
+// @Param format query string false "Export format (ndjson, csv, protobuf)" default(ndjson)
+// @Param order_by query string false "AIP-132 ordering, e.g. \"created_at desc, username\" (id, username, email, full_name, created_at, updated_at)"
+// @Param sort_by query string false "Deprecated: use order_by. Sort by a single field"
+// @Param filter query string false "AIP-160 filter, e.g. `is_active = false AND email = \"*@corp.com\"`; a bare value matches username, email or full_name"
+// @Param created_after query string false "Only users created at or after this RFC 3339 time"
+// @Param created_before query string false "Only users created before this RFC 3339 time"
+// @Param updated_after query string false "Only users updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only users updated before this RFC 3339 time"
+// @Success 200 {string} string "Encoded users"
+// @Failure 400 {object} model.UserResponse
+// @Router /users:export [get]
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	var req model.ExportUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleUserError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+	timeRange, err := parseTimeRange(c)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+	req.TimeRange = timeRange
+
+	enc, err := export.NewUserEncoder(req.Format, c.Writer)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	users, err := h.userService.ExportUsers(c.Request.Context(), &req)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	streamExport(c, req.Format, enc, users)
+}
+
+// CheckUserPolicy godoc
+// @Summary Check users against the identity policy
+// @Description Report stored users whose username or email breaks the username and email policy. With repair=true, values that only need Unicode or case normalization are rewritten; other violations, such as reserved names or identities that collide after case folding, are only reported.
+// @Tags users
+// @Produce json
+// @Param repair query bool false "Rewrite values that only need normalizing"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.UserResponse
+// @Router /users:checkPolicy [post]
+func (h *UserHandler) CheckUserPolicy(c *gin.Context) {
+	repair, err := strconv.ParseBool(c.DefaultQuery("repair", "false"))
+	if err != nil {
+		handleUserError(c, errors.NewValidationError("repair", "repair must be a boolean"))
+		return
+	}
+
+	report, err := h.userService.CheckUserPolicy(c.Request.Context(), repair)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.UserResponse{
+		Policy:  report,
+		Message: "User policy checked successfully",
+		Success: true,
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify a user's email
+// @Description Confirm a user's email with the token sent to it. Accounts created while verification is enabled are activated. Each token works once, and only while the user still has the email it was sent to.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param verification body model.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.UserResponse
+// @Router /users:verifyEmail [post]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleUserError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	user, err := h.userService.VerifyEmail(c.Request.Context(), &req)
+	if err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.UserResponse{
+		User:    user,
+		Message: "Email verified successfully",
+		Success: true,
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification email
+// @Description Send a new verification token to a user whose email is not verified, invalidating earlier tokens. Rate-limited per user.
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} model.UserResponse
+// @Failure 404 {object} model.UserResponse
+// @Failure 409 {object} model.UserResponse
+// @Failure 429 {object} model.UserResponse
+// @Router /users/{id}/resendVerification [post]
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	req := &model.ResendVerificationRequest{ID: c.Param("id")}
+	if err := h.userService.ResendVerification(c.Request.Context(), req); err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.UserResponse{
+		Message: "Verification email sent",
+		Success: true,
+	})
+}
+
+// SetPassword godoc
+// @Summary Set a user's password
+// @Description Set a user's password without the current one. A bearer token of the user is needed to replace an existing password; without one only the first password can be set, with the setup_token returned when the user was created. The password must satisfy the password policy; outstanding refresh tokens stop working.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param password body model.SetPasswordRequest true "New password"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.UserResponse
+// @Failure 401 {object} model.UserResponse
+// @Failure 403 {object} model.UserResponse
+// @Failure 404 {object} model.UserResponse
+// @Router /users/{id}/password [put]
+func (h *UserHandler) SetPassword(c *gin.Context) {
+	var req model.SetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleUserError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	req.ID = c.Param("id")
+	if err := h.userService.SetPassword(c.Request.Context(), &req); err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.UserResponse{
+		Message: "Password set successfully",
+		Success: true,
+	})
+}
+
+// ChangePassword godoc
+// @Summary Change a user's password
+// @Description Replace a user's password after checking the current one. A wrong current password counts towards the login lockout.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param password body model.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} model.UserResponse
+// @Failure 401 {object} model.UserResponse
+// @Failure 403 {object} model.UserResponse
+// @Failure 404 {object} model.UserResponse
+// @Router /users/{id}/changePassword [post]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleUserError(c, errors.NewInvalidRequestError("Invalid request: "+err.Error()))
+		return
+	}
+
+	req.ID = c.Param("id")
+	if err := h.userService.ChangePassword(c.Request.Context(), &req); err != nil {
+		handleUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.UserResponse{
+		Message: "Password changed successfully",
+		Success: true,
+	})
+}
@@ -5,10 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/idempotency"
 	"go-grpc-rest-demo/internal/server/model"
 	"go-grpc-rest-demo/internal/server/service"
 
@@ -36,6 +42,8 @@ func (suite *UserHandlerTestSuite) SetupTest() {
 		v1.PUT("/users/:id", userHandler.UpdateUser)
 		v1.DELETE("/users/:id", userHandler.DeleteUser)
 		v1.GET("/users", userHandler.ListUsers)
+		v1.GET("/users\\:watch", userHandler.WatchUsers)
+		v1.GET("/users\\:export", userHandler.ExportUsers)
 	}
 }
 
@@ -81,6 +89,14 @@ func (suite *UserHandlerTestSuite) TestCreateUserInvalidRequest() {
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var response model.UserResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "email is required; full_name is required", response.Message)
+	assert.Equal(suite.T(), []errors.FieldViolation{
+		{Field: "email", Description: "email is required"},
+		{Field: "full_name", Description: "full_name is required"},
+	}, response.Violations)
 }
 
 func (suite *UserHandlerTestSuite) TestGetUser() {
@@ -207,6 +223,232 @@ func (suite *UserHandlerTestSuite) TestListUsers() {
 	assert.Equal(suite.T(), float64(3), response["page_size"])
 }
 
+func (suite *UserHandlerTestSuite) TestWatchUsersResumesFromLastEventID() {
+	for _, username := range []string{"first", "second"} {
+		_, err := suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: username,
+			Email:    username + "@example.com",
+			FullName: "Watched User",
+		})
+		assert.NoError(suite.T(), err)
+	}
+
+	// A cancelled request still replays retained events before the stream closes
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/api/v1/users:watch", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	w := httptest.NewRecorder()
+
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "text/event-stream;charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(suite.T(), w.Body.String(), "id:2\nevent:created\n")
+	assert.Contains(suite.T(), w.Body.String(), `"username":"second"`)
+	assert.NotContains(suite.T(), w.Body.String(), `"username":"first"`)
+}
+
+func (suite *UserHandlerTestSuite) TestWatchUsersInvalidSequence() {
+	req, _ := http.NewRequest("GET", "/api/v1/users:watch?since_sequence=abc", nil)
+	w := httptest.NewRecorder()
+
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestExportUsersCSV() {
+	for i := range 3 {
+		_, err := suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: fmt.Sprintf("exportuser%d", i),
+			Email:    fmt.Sprintf("export%d@example.com", i),
+			FullName: fmt.Sprintf("Export User %d", i),
+		})
+		assert.NoError(suite.T(), err)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/users:export?format=csv&filter=exportuser1", nil)
+	w := httptest.NewRecorder()
+
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "text/csv", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(suite.T(), lines, 2)
+	assert.Equal(suite.T(), "id,username,email,full_name,is_active,created_at,updated_at", lines[0])
+	assert.True(suite.T(), strings.HasPrefix(lines[1], "2,exportuser1,export1@example.com,"))
+}
+
+func (suite *UserHandlerTestSuite) TestExportUsersOrderAndTimeRange() {
+	for _, username := range []string{"alice", "bob"} {
+		_, err := suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: username, Email: username + "@example.com", FullName: username,
+		})
+		assert.NoError(suite.T(), err)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/users:export?format=csv&order_by=username+desc", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	suite.Require().Len(lines, 3)
+	assert.Contains(suite.T(), lines[1], ",bob,")
+	assert.Contains(suite.T(), lines[2], ",alice,")
+
+	req, _ = http.NewRequest("GET", "/api/v1/users:export?created_after="+time.Now().Add(time.Hour).Format(time.RFC3339), nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Empty(suite.T(), w.Body.String())
+
+	req, _ = http.NewRequest("GET", "/api/v1/users:export?created_after=yesterday", nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestExportUsersUnsupportedFormat() {
+	req, _ := http.NewRequest("GET", "/api/v1/users:export?format=xml", nil)
+	w := httptest.NewRecorder()
+
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Header().Get("Content-Type"), "application/json")
+}
+
+func (suite *UserHandlerTestSuite) TestUsersAreScopedToTenant() {
+	products := service.NewProductService(service.NewCategoryService())
+	tenants := service.NewTenantService(suite.userService, products, service.NewOrderService(suite.userService, products))
+	_, err := tenants.CreateTenant(context.Background(), &model.CreateTenantRequest{ID: "acme", DisplayName: "Acme"})
+	suite.Require().NoError(err)
+	authService := service.NewAuthService(suite.userService, auth.NewTokenSigner([]byte("secret"), time.Minute), time.Hour)
+
+	router := gin.New()
+	v1 := router.Group("/api/v1", tenantMiddleware(tenants, authService))
+	userHandler := NewUserHandler(suite.userService)
+	v1.POST("/users", userHandler.CreateUser)
+	v1.GET("/users/:id", userHandler.GetUser)
+	v1.GET("/users", userHandler.ListUsers)
+
+	serve := func(method, path, tenantID string, body any) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		if tenantID != "" {
+			req.Header.Set(TenantHeader, tenantID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The same username is free in each tenant
+	alice := map[string]any{"username": "alice", "email": "alice@example.com", "full_name": "Alice"}
+	w := serve("POST", "/api/v1/users", "acme", alice)
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var created map[string]any
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	id := created["user"].(map[string]any)["id"].(string)
+	assert.Equal(suite.T(), "acme", created["user"].(map[string]any)["tenant_id"])
+	assert.Equal(suite.T(), http.StatusCreated, serve("POST", "/api/v1/users", "", alice).Code)
+
+	assert.Equal(suite.T(), http.StatusOK, serve("GET", "/api/v1/users/"+id, "acme", nil).Code)
+	assert.Equal(suite.T(), http.StatusNotFound, serve("GET", "/api/v1/users/"+id, "", nil).Code)
+
+	w = serve("GET", "/api/v1/users", "acme", nil)
+	var listed map[string]any
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(suite.T(), float64(1), listed["total_count"])
+
+	w = serve("GET", "/api/v1/users", "initech", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "tenant")
+}
+
+func (suite *UserHandlerTestSuite) TestSetPasswordNeedsTheUsersToken() {
+	suite.userService.ConfigurePasswords(service.PasswordConfig{
+		Hasher: &auth.Hasher{Time: 1, MemoryKiB: 64, Threads: 1, KeyLength: 32, SaltLength: 16},
+	})
+	products := service.NewProductService(service.NewCategoryService())
+	tenants := service.NewTenantService(suite.userService, products, service.NewOrderService(suite.userService, products))
+	authService := service.NewAuthService(suite.userService, auth.NewTokenSigner([]byte("secret"), time.Minute), time.Hour)
+
+	router := gin.New()
+	v1 := router.Group("/api/v1", tenantMiddleware(tenants, authService))
+	v1.PUT("/users/:id/password", NewUserHandler(suite.userService).SetPassword)
+
+	setPassword := func(id, password, setupToken, token string) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(map[string]any{"password": password, "setup_token": setupToken})
+		req, _ := http.NewRequest("PUT", "/api/v1/users/"+id+"/password", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var ids, setupTokens []string
+	for _, name := range []string{"alice", "bob"} {
+		user, err := suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: name, Email: name + "@example.com", FullName: name,
+		})
+		suite.Require().NoError(err)
+		ids = append(ids, user.ID)
+		setupTokens = append(setupTokens, user.PasswordSetupToken)
+	}
+
+	// Without a token only the first password can be set, with the setup token
+	assert.Equal(suite.T(), http.StatusUnauthorized, setPassword(ids[0], "correct-horse-battery", "", "").Code)
+	assert.Equal(suite.T(), http.StatusUnauthorized, setPassword(ids[0], "correct-horse-battery", setupTokens[1], "").Code)
+	assert.Equal(suite.T(), http.StatusOK, setPassword(ids[0], "correct-horse-battery", setupTokens[0], "").Code)
+	w := setPassword(ids[0], "taken-over-password", setupTokens[0], "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+	_, err := authService.Login(context.Background(), &model.LoginRequest{Username: "alice", Password: "taken-over-password"})
+	assert.Error(suite.T(), err)
+
+	tokens, err := authService.Login(context.Background(), &model.LoginRequest{Username: "alice", Password: "correct-horse-battery"})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), http.StatusForbidden, setPassword(ids[1], "correct-horse-battery", "", tokens.AccessToken).Code)
+	assert.Equal(suite.T(), http.StatusOK, setPassword(ids[0], "another-Secret-42", "", tokens.AccessToken).Code)
+}
+
+func (suite *UserHandlerTestSuite) TestIdempotentBodiesAreBounded() {
+	router := gin.New()
+	v1 := router.Group("/api/v1", idempotencyMiddleware(idempotency.NewStore[recordedResponse](time.Minute), "/api/v1/products:import"))
+	v1.POST("/users", NewUserHandler(suite.userService).CreateUser)
+	v1.POST("/products\\:import", func(c *gin.Context) {
+		n, err := io.Copy(io.Discard, c.Request.Body)
+		suite.Require().NoError(err)
+		c.JSON(http.StatusOK, gin.H{"read": n})
+	})
+
+	post := func(path string, body []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(IdempotencyKeyHeader, "bounded-body-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	large := bytes.Repeat([]byte(" "), maxIdempotentBodyBytes+1)
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, post("/api/v1/users", large).Code)
+
+	// Streaming imports are passed through without buffering the body
+	w := post("/api/v1/products:import", large)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.JSONEq(suite.T(), fmt.Sprintf(`{"read": %d}`, len(large)), w.Body.String())
+}
+
 func TestUserHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(UserHandlerTestSuite))
 }
\ No newline at end of file
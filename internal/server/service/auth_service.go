@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
+)
+
+// AuthService signs users in with their passwords and issues access and
+// refresh tokens. Refresh tokens are single-use: each refresh replaces the
+// token it was given.
+type AuthService struct {
+	userService *UserService
+	signer      *auth.TokenSigner
+	refreshTTL  time.Duration
+	mu          sync.Mutex
+	// sessions are keyed by the SHA-256 of their refresh token, so that a
+	// leaked copy of the map does not leak usable tokens
+	sessions map[string]*refreshSession
+}
+
+type refreshSession struct {
+	userID    string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+func NewAuthService(userService *UserService, signer *auth.TokenSigner, refreshTTL time.Duration) *AuthService {
+	return &AuthService{
+		userService: userService,
+		signer:      signer,
+		refreshTTL:  refreshTTL,
+		sessions:    make(map[string]*refreshSession),
+	}
+}
+
+// Login checks the username or email and password of a user of the
+// request's tenant and issues tokens. Inactive and locked accounts are
+// refused.
+func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*model.AuthTokens, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.authenticate(tenant.FromContext(ctx), req.Username, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(user), nil
+}
+
+// RefreshToken exchanges a refresh token for new tokens. It fails once the
+// user was deactivated, deleted or changed their password.
+func (s *AuthService) RefreshToken(ctx context.Context, req *model.RefreshTokenRequest) (*model.AuthTokens, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+
+	key := refreshTokenKey(req.RefreshToken)
+	s.mu.Lock()
+	session, exists := s.sessions[key]
+	delete(s.sessions, key)
+	s.mu.Unlock()
+	if !exists || !time.Now().Before(session.expiresAt) {
+		return nil, errors.NewUnauthorizedError("refresh token is invalid or has expired")
+	}
+
+	user, err := s.userService.sessionUser(session.userID, session.issuedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(user), nil
+}
+
+// VerifyAccessToken checks an access token this service issued and returns
+// its claims
+func (s *AuthService) VerifyAccessToken(token string) (auth.Claims, error) {
+	return s.signer.Verify(token, time.Now())
+}
+
+func (s *AuthService) issue(user *model.User) *model.AuthTokens {
+	now := time.Now()
+	accessToken, claims := s.signer.Issue(user.ID, user.Username, user.TenantID, now)
+
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	refreshToken := base64.RawURLEncoding.EncodeToString(secret)
+	session := &refreshSession{userID: user.ID, issuedAt: now, expiresAt: now.Add(s.refreshTTL)}
+
+	s.mu.Lock()
+	for key, other := range s.sessions {
+		if !now.Before(other.expiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+	s.sessions[refreshTokenKey(refreshToken)] = session
+	s.mu.Unlock()
+
+	return &model.AuthTokens{
+		AccessToken:           accessToken,
+		TokenType:             "Bearer",
+		AccessTokenExpiresAt:  time.Unix(claims.ExpiresAt, 0).UTC(),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.expiresAt.UTC(),
+		User:                  user,
+	}
+}
+
+func refreshTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const testPassword = "correct-horse-battery"
+
+type AuthServiceTestSuite struct {
+	suite.Suite
+	service     *AuthService
+	userService *UserService
+	signer      *auth.TokenSigner
+	user        *model.User
+}
+
+func (suite *AuthServiceTestSuite) SetupTest() {
+	suite.userService = NewUserService()
+	suite.userService.ConfigurePasswords(PasswordConfig{
+		Hasher:            &auth.Hasher{Time: 1, MemoryKiB: 64, Threads: 1, KeyLength: 32, SaltLength: 16},
+		MaxFailedAttempts: 3,
+		LockoutDuration:   time.Hour,
+	})
+	suite.signer = auth.NewTokenSigner([]byte("secret"), 15*time.Minute)
+	suite.service = NewAuthService(suite.userService, suite.signer, time.Hour)
+
+	var err error
+	suite.user, err = suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{
+		Username: "alice", Email: "alice@example.com", FullName: "Alice",
+	})
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.userService.SetPassword(context.Background(), &model.SetPasswordRequest{
+		ID: suite.user.ID, Password: testPassword, SetupToken: suite.user.PasswordSetupToken,
+	}))
+}
+
+func (suite *AuthServiceTestSuite) login(username, password string) (*model.AuthTokens, error) {
+	return suite.service.Login(context.Background(), &model.LoginRequest{Username: username, Password: password})
+}
+
+func (suite *AuthServiceTestSuite) TestLoginAndRefresh() {
+	tokens, err := suite.login("ALICE@example.com", testPassword)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Bearer", tokens.TokenType)
+	assert.Equal(suite.T(), suite.user.ID, tokens.User.ID)
+
+	claims, err := suite.signer.Verify(tokens.AccessToken, time.Now())
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), suite.user.ID, claims.Subject)
+
+	refreshed, err := suite.service.RefreshToken(context.Background(), &model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	suite.Require().NoError(err)
+	assert.NotEqual(suite.T(), tokens.RefreshToken, refreshed.RefreshToken)
+
+	// Refresh tokens are single-use
+	_, err = suite.service.RefreshToken(context.Background(), &model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code)
+
+	// Changing the password revokes outstanding refresh tokens
+	err = suite.userService.ChangePassword(context.Background(), &model.ChangePasswordRequest{
+		ID: suite.user.ID, CurrentPassword: testPassword, NewPassword: "another-Secret-42",
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.RefreshToken(context.Background(), &model.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken})
+	assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code)
+
+	_, err = suite.login("alice", "another-Secret-42")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AuthServiceTestSuite) TestLoginRefusesBadCredentials() {
+	for _, creds := range [][2]string{{"alice", "wrong-password"}, {"nobody", testPassword}} {
+		_, err := suite.login(creds[0], creds[1])
+		appErr := errors.AsAppError(err)
+		assert.Equal(suite.T(), errors.ErrCodeUnauthorized, appErr.Code)
+		assert.Equal(suite.T(), "invalid username or password", appErr.Message)
+	}
+
+	bob, err := suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{Username: "bob", Email: "bob@example.com", FullName: "Bob"})
+	suite.Require().NoError(err)
+	_, err = suite.login("bob", testPassword)
+	assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code, "users without a password cannot log in")
+
+	err = suite.userService.SetPassword(context.Background(), &model.SetPasswordRequest{ID: bob.ID, Password: "short", SetupToken: bob.PasswordSetupToken})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *AuthServiceTestSuite) TestLoginRefusesInactiveUsers() {
+	inactive := false
+	_, err := suite.userService.UpdateUser(context.Background(), &model.UpdateUserRequest{ID: suite.user.ID, IsActive: &inactive})
+	suite.Require().NoError(err)
+
+	_, err = suite.login("alice", testPassword)
+	assert.Equal(suite.T(), errors.ErrCodeForbidden, errors.AsAppError(err).Code)
+}
+
+func (suite *AuthServiceTestSuite) TestLockoutAfterRepeatedFailures() {
+	_, err := suite.login("alice", "wrong-1")
+	assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code)
+	_, err = suite.login("alice", testPassword)
+	suite.Require().NoError(err, "a success resets the failure count")
+
+	for range 3 {
+		_, err = suite.login("alice", "wrong")
+		assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code)
+	}
+	_, err = suite.login("alice", testPassword)
+	assert.Equal(suite.T(), errors.ErrCodeForbidden, errors.AsAppError(err).Code)
+	assert.Contains(suite.T(), err.Error(), "locked")
+
+	// Setting a new password, which takes the user's token, lifts the lock
+	signedIn := auth.NewContext(context.Background(), auth.Claims{Subject: suite.user.ID})
+	suite.Require().NoError(suite.userService.SetPassword(signedIn, &model.SetPasswordRequest{ID: suite.user.ID, Password: testPassword}))
+	_, err = suite.login("alice", testPassword)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AuthServiceTestSuite) TestSetPasswordReplacesOnlyWithTheUsersToken() {
+	err := suite.userService.SetPassword(context.Background(), &model.SetPasswordRequest{ID: suite.user.ID, Password: "taken-over-42"})
+	assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code)
+
+	bob, err := suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{Username: "bob", Email: "bob@example.com", FullName: "Bob"})
+	suite.Require().NoError(err)
+	asAlice := auth.NewContext(context.Background(), auth.Claims{Subject: suite.user.ID})
+	err = suite.userService.SetPassword(asAlice, &model.SetPasswordRequest{ID: bob.ID, Password: testPassword})
+	assert.Equal(suite.T(), errors.ErrCodeForbidden, errors.AsAppError(err).Code)
+
+	_, err = suite.login("alice", "taken-over-42")
+	assert.Error(suite.T(), err)
+	_, err = suite.login("alice", testPassword)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AuthServiceTestSuite) TestFirstPasswordNeedsTheSetupToken() {
+	bob, err := suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{Username: "bob", Email: "bob@example.com", FullName: "Bob"})
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(bob.PasswordSetupToken)
+
+	for _, token := range []string{"", "guessed-token"} {
+		err = suite.userService.SetPassword(context.Background(), &model.SetPasswordRequest{ID: bob.ID, Password: testPassword, SetupToken: token})
+		assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code)
+	}
+
+	stored, err := suite.userService.GetUser(context.Background(), bob.ID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), stored.PasswordSetupToken, "the setup token is only returned on creation")
+
+	req := &model.SetPasswordRequest{ID: bob.ID, Password: testPassword, SetupToken: bob.PasswordSetupToken}
+	suite.Require().NoError(suite.userService.SetPassword(context.Background(), req))
+	req.Password = "taken-over-42"
+	err = suite.userService.SetPassword(context.Background(), req)
+	assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code, "the setup token works once")
+}
+
+func TestAuthServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthServiceTestSuite))
+}
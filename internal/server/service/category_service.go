@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
+)
+
+// categoryProducts is the view of the product service the category tree
+// needs to keep product references consistent. Its methods are called with
+// the category lock held and take the product lock, so the category lock is
+// always taken before the product service's, never the other way round.
+type categoryProducts interface {
+	categoryInUse(tenantID, categoryID string) bool
+	renameCategory(tenantID, categoryID, name string)
+}
+
+// CategoryService manages the product category tree of each tenant. A
+// tenant only sees and links to its own categories.
+type CategoryService struct {
+	categories map[string]*model.Category
+	nextID     int64
+	mu         sync.RWMutex
+	products   categoryProducts
+	// tenants is nil unless a tenant service manages the tenants categories
+	// are created in
+	tenants *TenantService
+}
+
+func NewCategoryService() *CategoryService {
+	return &CategoryService{
+		categories: make(map[string]*model.Category),
+		nextID:     1,
+	}
+}
+
+func (s *CategoryService) CreateCategory(ctx context.Context, req *model.CreateCategoryRequest) (*model.Category, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	tenantID := tenant.FromContext(ctx)
+	release, err := s.tenants.hold(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.ParentID != "" {
+		if _, err := s.getCategoryLocked(tenantID, req.ParentID); err != nil {
+			return nil, errors.NewValidationError("parent_id", fmt.Sprintf("parent category %s does not exist", req.ParentID))
+		}
+	}
+	slug, err := s.checkSlugLocked(tenantID, "", req.Slug, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.insertCategoryLocked(tenantID, name, slug, req.ParentID), nil
+}
+
+// insertCategoryLocked stores a new category of a tenant. s.mu must be held.
+func (s *CategoryService) insertCategoryLocked(tenantID, name, slug, parentID string) *model.Category {
+	now := time.Now()
+	category := &model.Category{
+		ID:        strconv.FormatInt(s.nextID, 10),
+		TenantID:  tenantID,
+		Name:      name,
+		Slug:      slug,
+		ParentID:  parentID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.nextID++
+	s.categories[category.ID] = category
+	return category
+}
+
+// checkSlugLocked returns the slug to store, derived from name when none is
+// given, and rejects it if another category of the tenant already uses it.
+func (s *CategoryService) checkSlugLocked(tenantID, excludeID, slug, name string) (string, error) {
+	if slug == "" {
+		slug = name
+	}
+	slug = slugify(slug)
+	if slug == "" {
+		return "", errors.NewValidationError("slug", "slug must contain at least one letter or digit")
+	}
+	if existing := s.findBySlugLocked(tenantID, slug); existing != nil && existing.ID != excludeID {
+		return "", errors.NewAlreadyExistsError("category", "slug", slug)
+	}
+	return slug, nil
+}
+
+func (s *CategoryService) findBySlugLocked(tenantID, slug string) *model.Category {
+	for _, category := range s.categories {
+		if category.TenantID == tenantID && category.Slug == slug {
+			return category
+		}
+	}
+	return nil
+}
+
+// slugify lowercases s and joins its runs of letters and digits with single
+// hyphens, so "  Home & Garden " becomes "home-garden".
+func slugify(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
+
+func (s *CategoryService) GetCategory(ctx context.Context, id string) (*model.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.getCategoryLocked(tenant.FromContext(ctx), id)
+}
+
+// getCategoryLocked returns the category with id in a tenant. Categories of
+// other tenants are reported missing. s.mu must be held.
+func (s *CategoryService) getCategoryLocked(tenantID, id string) (*model.Category, error) {
+	category, exists := s.categories[id]
+	if !exists || category.TenantID != tenantID {
+		return nil, errors.NewNotFoundError("category", id)
+	}
+	return category, nil
+}
+
+// UpdateCategory renames or moves a category. Renaming also updates the
+// legacy category name of the products linked to it.
+func (s *CategoryService) UpdateCategory(ctx context.Context, req *model.UpdateCategoryRequest) (*model.Category, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category, err := s.getCategoryLocked(tenantID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var slug string
+	if req.Slug != nil {
+		if slug, err = s.checkSlugLocked(tenantID, category.ID, *req.Slug, ""); err != nil {
+			return nil, err
+		}
+	}
+	if req.ParentID != nil && *req.ParentID != "" {
+		if err := s.checkParentLocked(tenantID, category.ID, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Name != nil {
+		category.Name = strings.TrimSpace(*req.Name)
+		if s.products != nil {
+			s.products.renameCategory(tenantID, category.ID, category.Name)
+		}
+	}
+	if req.Slug != nil {
+		category.Slug = slug
+	}
+	if req.ParentID != nil {
+		category.ParentID = *req.ParentID
+	}
+	category.UpdatedAt = time.Now()
+	return category, nil
+}
+
+// checkParentLocked rejects a parent that does not exist or that would make
+// the category its own ancestor.
+func (s *CategoryService) checkParentLocked(tenantID, id, parentID string) error {
+	if _, err := s.getCategoryLocked(tenantID, parentID); err != nil {
+		return errors.NewValidationError("parent_id", fmt.Sprintf("parent category %s does not exist", parentID))
+	}
+	for ancestor := parentID; ancestor != ""; ancestor = s.categories[ancestor].ParentID {
+		if ancestor == id {
+			return errors.NewValidationError("parent_id", "a category cannot be moved under itself or its descendants")
+		}
+	}
+	return nil
+}
+
+// DeleteCategory deletes a category that has no children and is not
+// referenced by any product.
+func (s *CategoryService) DeleteCategory(ctx context.Context, id string) error {
+	tenantID := tenant.FromContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.getCategoryLocked(tenantID, id); err != nil {
+		return err
+	}
+	for _, category := range s.categories {
+		if category.ParentID == id {
+			return errors.NewFailedPreconditionError(fmt.Sprintf("category %s has child categories", id))
+		}
+	}
+	if s.products != nil && s.products.categoryInUse(tenantID, id) {
+		return errors.NewFailedPreconditionError(fmt.Sprintf("category %s is referenced by products", id))
+	}
+
+	delete(s.categories, id)
+	return nil
+}
+
+// ListCategories returns all categories of the tenant, or the direct
+// children of req.ParentID when it is set, ordered by name.
+func (s *CategoryService) ListCategories(ctx context.Context, req *model.ListCategoriesRequest) ([]model.Category, error) {
+	tenantID := tenant.FromContext(ctx)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if req.ParentID != nil && *req.ParentID != "" {
+		if _, err := s.getCategoryLocked(tenantID, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	categories := make([]model.Category, 0, len(s.categories))
+	for _, category := range s.categories {
+		if category.TenantID != tenantID || (req.ParentID != nil && category.ParentID != *req.ParentID) {
+			continue
+		}
+		categories = append(categories, *category)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+// tenantInUse reports whether a tenant has categories
+func (s *CategoryService) tenantInUse(tenantID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, category := range s.categories {
+		if category.TenantID == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// subtreeIDs returns the ID of a category of a tenant and, when
+// includeDescendants is set, the IDs of all categories below it.
+func (s *CategoryService) subtreeIDs(tenantID, id string, includeDescendants bool) (map[string]bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.getCategoryLocked(tenantID, id); err != nil {
+		return nil, err
+	}
+
+	ids := map[string]bool{id: true}
+	if !includeDescendants {
+		return ids, nil
+	}
+	// Parents are not ordered by ID once categories move, so repeat until
+	// no new descendant is found
+	for grew := true; grew; {
+		grew = false
+		for _, category := range s.categories {
+			if !ids[category.ID] && ids[category.ParentID] {
+				ids[category.ID] = true
+				grew = true
+			}
+		}
+	}
+	return ids, nil
+}
+
+// readLock holds the category tree steady while products referencing it are
+// written, so a category cannot be deleted in between. It must be taken
+// before the product lock.
+func (s *CategoryService) readLock() func() {
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// referenceLocked resolves the category a product of a tenant refers to,
+// nil when id is empty. s.mu must be held.
+func (s *CategoryService) referenceLocked(tenantID, id string) (*model.Category, error) {
+	if id == "" {
+		return nil, nil
+	}
+	category, err := s.getCategoryLocked(tenantID, id)
+	if err != nil {
+		return nil, errors.NewValidationError("category_id", fmt.Sprintf("category %s does not exist", id))
+	}
+	return category, nil
+}
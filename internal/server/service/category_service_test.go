@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CategoryServiceTestSuite struct {
+	suite.Suite
+	service        *CategoryService
+	productService *ProductService
+}
+
+func (suite *CategoryServiceTestSuite) SetupTest() {
+	suite.service = NewCategoryService()
+	suite.productService = NewProductService(suite.service)
+}
+
+func (suite *CategoryServiceTestSuite) createCategory(name, parentID string) *model.Category {
+	category, err := suite.service.CreateCategory(context.Background(), &model.CreateCategoryRequest{Name: name, ParentID: parentID})
+	suite.Require().NoError(err)
+	return category
+}
+
+func (suite *CategoryServiceTestSuite) createProduct(name, categoryID string) *model.Product {
+	product, err := suite.productService.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: name, Description: name, Price: 10, Quantity: 1, CategoryID: categoryID,
+	})
+	suite.Require().NoError(err)
+	return product
+}
+
+func (suite *CategoryServiceTestSuite) TestCreateCategory() {
+	category := suite.createCategory("  Home & Garden ", "")
+	assert.Equal(suite.T(), "Home & Garden", category.Name)
+	assert.Equal(suite.T(), "home-garden", category.Slug)
+	assert.Empty(suite.T(), category.ParentID)
+
+	_, err := suite.service.CreateCategory(context.Background(), &model.CreateCategoryRequest{Name: "home garden"})
+	assert.Equal(suite.T(), errors.ErrCodeAlreadyExists, errors.AsAppError(err).Code)
+
+	_, err = suite.service.CreateCategory(context.Background(), &model.CreateCategoryRequest{Name: "Tools", ParentID: "missing"})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+
+	_, err = suite.service.CreateCategory(context.Background(), &model.CreateCategoryRequest{Name: "Tools", Slug: "--"})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *CategoryServiceTestSuite) TestUpdateCategoryRejectsCycles() {
+	root := suite.createCategory("Electronics", "")
+	child := suite.createCategory("Computers", root.ID)
+	grandchild := suite.createCategory("Laptops", child.ID)
+
+	_, err := suite.service.UpdateCategory(context.Background(), &model.UpdateCategoryRequest{ID: root.ID, ParentID: &grandchild.ID})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+
+	top := ""
+	moved, err := suite.service.UpdateCategory(context.Background(), &model.UpdateCategoryRequest{ID: grandchild.ID, ParentID: &top})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), moved.ParentID)
+}
+
+func (suite *CategoryServiceTestSuite) TestRenameUpdatesLinkedProducts() {
+	category := suite.createCategory("Electronics", "")
+	product := suite.createProduct("Laptop", category.ID)
+	assert.Equal(suite.T(), "Electronics", product.Category)
+
+	name := "Consumer Electronics"
+	_, err := suite.service.UpdateCategory(context.Background(), &model.UpdateCategoryRequest{ID: category.ID, Name: &name})
+	assert.NoError(suite.T(), err)
+	product, err = suite.productService.GetProduct(context.Background(), product.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), name, product.Category)
+	assert.Equal(suite.T(), "electronics", category.Slug)
+}
+
+func (suite *CategoryServiceTestSuite) TestDeleteCategoryChecksReferences() {
+	root := suite.createCategory("Electronics", "")
+	child := suite.createCategory("Computers", root.ID)
+	product := suite.createProduct("Laptop", child.ID)
+
+	err := suite.service.DeleteCategory(context.Background(), root.ID)
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+	err = suite.service.DeleteCategory(context.Background(), child.ID)
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+
+	unlink := ""
+	_, err = suite.productService.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: product.ID, CategoryID: &unlink})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Computers", product.Category)
+
+	assert.NoError(suite.T(), suite.service.DeleteCategory(context.Background(), child.ID))
+	assert.NoError(suite.T(), suite.service.DeleteCategory(context.Background(), root.ID))
+}
+
+func (suite *CategoryServiceTestSuite) TestProductCategoryMustExist() {
+	_, err := suite.productService.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Laptop", Description: "Portable", Price: 10, Quantity: 1, CategoryID: "missing",
+	})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+
+	product := suite.createProduct("Laptop", suite.createCategory("Electronics", "").ID)
+	missing := "missing"
+	_, err = suite.productService.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: product.ID, CategoryID: &missing})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+
+	// A different legacy name detaches the product from its category
+	legacy := "Gadgets"
+	product, err = suite.productService.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: product.ID, Category: &legacy})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), product.CategoryID)
+}
+
+func (suite *CategoryServiceTestSuite) TestSearchProductsIncludesDescendants() {
+	root := suite.createCategory("Electronics", "")
+	child := suite.createCategory("Computers", root.ID)
+	grandchild := suite.createCategory("Laptops", child.ID)
+	suite.createProduct("Radio", root.ID)
+	suite.createProduct("Desktop", child.ID)
+	suite.createProduct("Notebook", grandchild.ID)
+
+	products, total, _, _, err := suite.productService.SearchProducts(context.Background(), &model.SearchProductsRequest{CategoryID: &child.ID})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), total)
+	assert.Equal(suite.T(), "Desktop", products[0].Name)
+
+	products, total, _, _, err = suite.productService.SearchProducts(context.Background(), &model.SearchProductsRequest{
+		CategoryID: &root.ID, IncludeDescendants: true,
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(3), total)
+	assert.Equal(suite.T(), "Desktop", products[0].Name)
+
+	missing := "missing"
+	_, _, _, _, err = suite.productService.SearchProducts(context.Background(), &model.SearchProductsRequest{CategoryID: &missing})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+}
+
+func (suite *CategoryServiceTestSuite) TestExportProductsIncludesDescendants() {
+	root := suite.createCategory("Electronics", "")
+	child := suite.createCategory("Computers", root.ID)
+	suite.createProduct("Radio", root.ID)
+	suite.createProduct("Desktop", child.ID)
+	suite.createProduct("Notebook", child.ID)
+	suite.createProduct("Novel", suite.createCategory("Books", "").ID)
+
+	filter := "NOT name:radio"
+	products, err := suite.productService.ExportProducts(context.Background(), &model.ExportProductsRequest{
+		CategoryID: &root.ID, IncludeDescendants: true, Filter: &filter, OrderBy: "name desc",
+	})
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(products, 2)
+	assert.Equal(suite.T(), "Notebook", products[0].Name)
+	assert.Equal(suite.T(), "Desktop", products[1].Name)
+
+	filter = "price >"
+	_, err = suite.productService.ExportProducts(context.Background(), &model.ExportProductsRequest{Filter: &filter})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *CategoryServiceTestSuite) TestMigrateCategories() {
+	existing := suite.createCategory("Books", "")
+	for _, category := range []string{"Electronics", "electronics ", "Books", "  "} {
+		_, err := suite.productService.CreateProduct(context.Background(), &model.CreateProductRequest{
+			Name: "Item", Description: "Item", Price: 1, Quantity: 1, Category: category,
+		})
+		suite.Require().NoError(err)
+	}
+
+	summary, err := suite.productService.MigrateCategories(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), summary.CategoriesCreated)
+	assert.Equal(suite.T(), int32(3), summary.ProductsUpdated)
+
+	products, _, _, _, err := suite.productService.SearchProducts(context.Background(), &model.SearchProductsRequest{CategoryID: &existing.ID})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), products, 1)
+
+	categories, err := suite.service.ListCategories(context.Background(), &model.ListCategoriesRequest{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), categories, 2)
+	assert.Equal(suite.T(), "Electronics", categories[1].Name)
+
+	summary, err = suite.productService.MigrateCategories(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.CategoryMigrationSummary{}, *summary)
+}
+
+func TestCategoryServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CategoryServiceTestSuite))
+}
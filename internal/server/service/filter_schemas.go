@@ -0,0 +1,43 @@
+package service
+
+import (
+	"time"
+
+	"go-grpc-rest-demo/internal/server/filter"
+	"go-grpc-rest-demo/internal/server/model"
+)
+
+// userFilterSchema lists the fields a ListUsers filter may use. A bare value
+// matches username, email or full name, like the filter did before it
+// accepted expressions.
+var userFilterSchema = &filter.Schema[model.User]{
+	Fields: map[string]filter.Field[model.User]{
+		"id":         filter.StringField(func(u *model.User) string { return u.ID }),
+		"username":   filter.StringField(func(u *model.User) string { return u.Username }),
+		"email":      filter.StringField(func(u *model.User) string { return u.Email }),
+		"full_name":  filter.StringField(func(u *model.User) string { return u.FullName }),
+		"is_active":  filter.BoolField(func(u *model.User) bool { return u.IsActive }),
+		"created_at": filter.TimeField(func(u *model.User) time.Time { return u.CreatedAt }),
+		"updated_at": filter.TimeField(func(u *model.User) time.Time { return u.UpdatedAt }),
+	},
+	Search: []string{"username", "email", "full_name"},
+}
+
+// productFilterSchema lists the fields a SearchProducts filter may use.
+// price compares the amount whatever its currency; combine it with
+// currency_code to compare like with like.
+var productFilterSchema = &filter.Schema[model.Product]{
+	Fields: map[string]filter.Field[model.Product]{
+		"id":            filter.StringField(func(p *model.Product) string { return p.ID }),
+		"name":          filter.StringField(func(p *model.Product) string { return p.Name }),
+		"description":   filter.StringField(func(p *model.Product) string { return p.Description }),
+		"category":      filter.StringField(func(p *model.Product) string { return p.Category }),
+		"category_id":   filter.StringField(func(p *model.Product) string { return p.CategoryID }),
+		"price":         filter.NumberField(func(p *model.Product) float64 { return p.PriceMoney.Float64() }),
+		"currency_code": filter.StringField(func(p *model.Product) string { return p.PriceMoney.CurrencyCode }),
+		"quantity":      filter.NumberField(func(p *model.Product) float64 { return float64(p.Quantity) }),
+		"created_at":    filter.TimeField(func(p *model.Product) time.Time { return p.CreatedAt }),
+		"updated_at":    filter.TimeField(func(p *model.Product) time.Time { return p.UpdatedAt }),
+	},
+	Search: []string{"name", "description"},
+}
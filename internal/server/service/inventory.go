@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
+)
+
+const (
+	// defaultReservationTTL applies when a reservation does not set its lifetime
+	defaultReservationTTL = 15 * time.Minute
+	// maxReservationTTL bounds how long stock can be held by one reservation
+	maxReservationTTL = 24 * time.Hour
+)
+
+// AdjustStock changes a product's quantity by a signed delta, such as a
+// delivery or a write-off. The quantity never goes below zero.
+func (s *ProductService) AdjustStock(ctx context.Context, req *model.AdjustStockRequest) (*model.Product, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	product, err := s.getProductLocked(tenant.FromContext(ctx), req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := int64(product.Quantity) + int64(req.Delta)
+	if quantity < 0 {
+		return nil, errors.NewFailedPreconditionError(
+			fmt.Sprintf("insufficient stock: product %s has %d, cannot remove %d", product.ID, product.Quantity, -req.Delta))
+	}
+	if quantity > math.MaxInt32 {
+		return nil, errors.NewValidationError("delta", "resulting quantity is too large")
+	}
+
+	s.setStockLocked(product, int32(quantity), req.Reason)
+	return copyProduct(product), nil
+}
+
+// ReserveStock takes quantity out of a product's stock and holds it under a
+// new reservation until it is committed, released or expires.
+func (s *ProductService) ReserveStock(ctx context.Context, req *model.ReserveStockRequest) (*model.Reservation, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+	ttl := defaultReservationTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl <= 0 || ttl > maxReservationTTL {
+		return nil, errors.NewValidationError("ttl_seconds",
+			fmt.Sprintf("ttl_seconds must be between 1 and %d", int(maxReservationTTL.Seconds())))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	product, err := s.getProductLocked(tenant.FromContext(ctx), req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Quantity < req.Quantity {
+		return nil, errors.NewFailedPreconditionError(
+			fmt.Sprintf("insufficient stock: product %s has %d, requested %d", product.ID, product.Quantity, req.Quantity))
+	}
+
+	now := time.Now()
+	reservation := &model.Reservation{
+		ID:        strconv.FormatInt(s.nextReservationID, 10),
+		ProductID: product.ID,
+		Quantity:  req.Quantity,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	s.nextReservationID++
+	s.reservations[reservation.ID] = reservation
+
+	s.setStockLocked(product, product.Quantity-req.Quantity, "reservation "+reservation.ID+" created")
+	return reservation, nil
+}
+
+// CommitReservation finalizes a reservation. The held stock stays consumed.
+func (s *ProductService) CommitReservation(ctx context.Context, id string) (*model.Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, err := s.activeReservationLocked(tenant.FromContext(ctx), id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	delete(s.reservations, id)
+	return reservation, nil
+}
+
+// ReleaseReservation cancels a reservation and returns its stock to the product.
+func (s *ProductService) ReleaseReservation(ctx context.Context, id string) (*model.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, err := s.activeReservationLocked(tenant.FromContext(ctx), id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	product := s.releaseLocked(reservation, "reservation "+id+" released")
+	if product == nil {
+		return nil, errors.NewNotFoundError("product", reservation.ProductID)
+	}
+	return copyProduct(product), nil
+}
+
+// DeductStock takes every line out of stock or, if any line cannot be
+// satisfied, none of them. It returns copies of the products as of the
+// deduction, in line order, so callers can record the prices they charged.
+func (s *ProductService) DeductStock(ctx context.Context, lines []model.StockLine, reason string) ([]model.Product, error) {
+	if err := validateStockLines(lines); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Lines may repeat a product, so check the totals before changing anything
+	tenantID := tenant.FromContext(ctx)
+	totals := make(map[string]int64, len(lines))
+	for i, line := range lines {
+		product, err := s.getProductLocked(tenantID, line.ProductID)
+		if err != nil {
+			return nil, batchItemError("items", i, err)
+		}
+		totals[line.ProductID] += int64(line.Quantity)
+		if totals[line.ProductID] > int64(product.Quantity) {
+			return nil, batchItemError("items", i, errors.NewFailedPreconditionError(
+				fmt.Sprintf("insufficient stock: product %s has %d, requested %d", product.ID, product.Quantity, totals[line.ProductID])))
+		}
+	}
+
+	for id, total := range totals {
+		product := s.products[id]
+		s.setStockLocked(product, product.Quantity-int32(total), reason)
+	}
+
+	products := make([]model.Product, len(lines))
+	for i, line := range lines {
+		products[i] = *s.products[line.ProductID]
+	}
+	return products, nil
+}
+
+// RestoreStock returns every line to stock, skipping products that no
+// longer exist in the tenant.
+func (s *ProductService) RestoreStock(ctx context.Context, lines []model.StockLine, reason string) error {
+	if err := validateStockLines(lines); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	for _, line := range lines {
+		product, err := s.getProductLocked(tenantID, line.ProductID)
+		if err != nil {
+			continue
+		}
+		quantity := min(int64(product.Quantity)+int64(line.Quantity), math.MaxInt32)
+		s.setStockLocked(product, int32(quantity), reason)
+	}
+	return nil
+}
+
+func validateStockLines(lines []model.StockLine) error {
+	if err := validateBatchSize("items", len(lines)); err != nil {
+		return err
+	}
+	for i, line := range lines {
+		if line.ProductID == "" {
+			return batchItemError("items", i, errors.NewValidationError("product_id", "product_id is required"))
+		}
+		if line.Quantity <= 0 {
+			return batchItemError("items", i, errors.NewValidationError("quantity", "quantity must be positive"))
+		}
+	}
+	return nil
+}
+
+// RunReservationReaper releases expired reservations every interval until
+// ctx is done.
+func (s *ProductService) RunReservationReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.expireReservations(now)
+		}
+	}
+}
+
+// expireReservations releases every reservation that expired before now and
+// reports how many there were.
+func (s *ProductService) expireReservations(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := 0
+	for id, reservation := range s.reservations {
+		if now.Before(reservation.ExpiresAt) {
+			continue
+		}
+		s.releaseLocked(reservation, "reservation "+id+" expired")
+		expired++
+	}
+	return expired
+}
+
+// activeReservationLocked looks up a reservation of a tenant's product that
+// can still be committed or released. An expired reservation the reaper has
+// not reached yet is released on the spot. s.mu must be held.
+func (s *ProductService) activeReservationLocked(tenantID, id string, now time.Time) (*model.Reservation, error) {
+	if id == "" {
+		return nil, errors.NewValidationError("reservation_id", "reservation_id is required")
+	}
+
+	reservation, exists := s.reservations[id]
+	if !exists {
+		return nil, errors.NewNotFoundError("reservation", id)
+	}
+	if _, err := s.getProductLocked(tenantID, reservation.ProductID); err != nil {
+		return nil, errors.NewNotFoundError("reservation", id)
+	}
+	if !now.Before(reservation.ExpiresAt) {
+		s.releaseLocked(reservation, "reservation "+id+" expired")
+		return nil, errors.NewFailedPreconditionError(fmt.Sprintf("reservation %s has expired", id))
+	}
+	return reservation, nil
+}
+
+// releaseLocked removes a reservation and returns its stock to the product,
+// which it returns; nil means the product no longer exists. s.mu must be held.
+func (s *ProductService) releaseLocked(reservation *model.Reservation, reason string) *model.Product {
+	delete(s.reservations, reservation.ID)
+
+	product, exists := s.products[reservation.ProductID]
+	if !exists {
+		return nil
+	}
+	quantity := min(int64(product.Quantity)+int64(reservation.Quantity), math.MaxInt32)
+	s.setStockLocked(product, int32(quantity), reason)
+	return product
+}
+
+// setStockLocked stores a new quantity and publishes the change. s.mu must be held.
+func (s *ProductService) setStockLocked(product *model.Product, quantity int32, reason string) {
+	product.Quantity = quantity
+	product.UpdatedAt = time.Now()
+	s.events.publish(func(seq int64) model.ProductEvent {
+		return model.ProductEvent{Sequence: seq, Type: model.EventUpdated, Product: *product, Reason: reason}
+	})
+}
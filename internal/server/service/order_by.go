@@ -0,0 +1,112 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+)
+
+// orderFields compares two items on each field that may appear in an
+// order_by clause. Every set must contain "id", the final tie-breaker.
+type orderFields[T any] map[string]func(a, b *T) int
+
+var userOrderFields = orderFields[model.User]{
+	"id":         func(a, b *model.User) int { return compareIDs(a.ID, b.ID) },
+	"username":   func(a, b *model.User) int { return strings.Compare(a.Username, b.Username) },
+	"email":      func(a, b *model.User) int { return strings.Compare(a.Email, b.Email) },
+	"full_name":  func(a, b *model.User) int { return strings.Compare(a.FullName, b.FullName) },
+	"created_at": func(a, b *model.User) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b *model.User) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// defaultProductOrder is the order of search results without an order_by
+const defaultProductOrder = "name"
+
+var productOrderFields = orderFields[model.Product]{
+	"id":   func(a, b *model.Product) int { return compareIDs(a.ID, b.ID) },
+	"name": func(a, b *model.Product) int { return strings.Compare(a.Name, b.Name) },
+	// Prices in different currencies are not comparable, so they are
+	// grouped by currency code first
+	"price": func(a, b *model.Product) int {
+		if c := strings.Compare(a.PriceMoney.CurrencyCode, b.PriceMoney.CurrencyCode); c != 0 {
+			return c
+		}
+		return a.PriceMoney.Cmp(b.PriceMoney)
+	},
+	"quantity":   func(a, b *model.Product) int { return cmp.Compare(a.Quantity, b.Quantity) },
+	"category":   func(a, b *model.Product) int { return strings.Compare(a.Category, b.Category) },
+	"created_at": func(a, b *model.Product) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b *model.Product) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// compareIDs orders the numeric IDs the services assign by value, so "10"
+// sorts after "9".
+func compareIDs(a, b string) int {
+	if c := cmp.Compare(len(a), len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+type orderTerm[T any] struct {
+	compare func(a, b *T) int
+	desc    bool
+}
+
+// parseOrderBy parses an AIP-132 order_by clause such as "price desc, name":
+// a comma-separated list of fields, each ascending unless followed by
+// "desc". Items that compare equal on every field are ordered by ID.
+func (fields orderFields[T]) parseOrderBy(param, orderBy string) ([]orderTerm[T], error) {
+	var terms []orderTerm[T]
+	seen := make(map[string]bool)
+	if strings.TrimSpace(orderBy) != "" {
+		for _, clause := range strings.Split(orderBy, ",") {
+			words := strings.Fields(clause)
+			if len(words) == 0 || len(words) > 2 || (len(words) == 2 && words[1] != "desc") {
+				return nil, errors.NewValidationError(param, fmt.Sprintf("invalid %s clause %q", param, strings.TrimSpace(clause)))
+			}
+			compare, ok := fields[words[0]]
+			if !ok {
+				return nil, errors.NewValidationError(param, fmt.Sprintf("cannot order by %q; allowed fields are %s", words[0], fields.names()))
+			}
+			if seen[words[0]] {
+				return nil, errors.NewValidationError(param, fmt.Sprintf("field %q is repeated in %s", words[0], param))
+			}
+			seen[words[0]] = true
+			terms = append(terms, orderTerm[T]{compare: compare, desc: len(words) == 2})
+		}
+	}
+	if !seen["id"] {
+		terms = append(terms, orderTerm[T]{compare: fields["id"]})
+	}
+	return terms, nil
+}
+
+func (fields orderFields[T]) names() string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// sortByTerms sorts items by the parsed order_by terms
+func sortByTerms[T any](items []T, terms []orderTerm[T]) {
+	slices.SortFunc(items, func(a, b T) int {
+		for _, term := range terms {
+			c := term.compare(&a, &b)
+			if term.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
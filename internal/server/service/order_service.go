@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
+)
+
+// OrderService places orders of products by users. Orders belong to the
+// tenant of their user and its products. Its lock is always taken before the
+// product service's, never the other way round.
+type OrderService struct {
+	orders         map[string]*model.Order
+	userOrders     map[string][]*model.Order
+	nextID         int64
+	mu             sync.RWMutex
+	userService    *UserService
+	productService *ProductService
+	// tenants is nil unless a tenant service manages the tenants orders are
+	// placed in
+	tenants *TenantService
+}
+
+func NewOrderService(userService *UserService, productService *ProductService) *OrderService {
+	return &OrderService{
+		orders:         make(map[string]*model.Order),
+		userOrders:     make(map[string][]*model.Order),
+		nextID:         1,
+		userService:    userService,
+		productService: productService,
+	}
+}
+
+// CreateOrder places an order for an active user. The ordered quantities are
+// taken out of stock atomically across all items, and product names and
+// prices are copied into the order.
+func (s *OrderService) CreateOrder(ctx context.Context, req *model.CreateOrderRequest) (*model.Order, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+
+	release, err := s.tenants.hold(tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	user, err := s.userService.GetUser(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, errors.NewFailedPreconditionError(fmt.Sprintf("user %s is not active", user.ID))
+	}
+
+	lines := make([]model.StockLine, len(req.Items))
+	for i, item := range req.Items {
+		lines[i] = model.StockLine{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The ID is only consumed once the stock has been taken
+	id := strconv.FormatInt(s.nextID, 10)
+	products, err := s.productService.DeductStock(ctx, lines, "order "+id+" placed")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order := &model.Order{
+		ID:        id,
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Items:     make([]model.OrderItem, len(lines)),
+		Status:    model.OrderStatusPlaced,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := priceOrder(order, products, lines); err != nil {
+		// Give the stock back; the order was never placed
+		if restoreErr := s.productService.RestoreStock(ctx, lines, "order "+id+" rejected"); restoreErr != nil {
+			return nil, restoreErr
+		}
+		return nil, err
+	}
+
+	s.nextID++
+	s.orders[order.ID] = order
+	s.userOrders[order.UserID] = append(s.userOrders[order.UserID], order)
+	return copyOrder(order), nil
+}
+
+// priceOrder fills in the items from the products and sums the total
+// exactly. All items must be priced in the same currency.
+func priceOrder(order *model.Order, products []model.Product, lines []model.StockLine) error {
+	for i, product := range products {
+		subtotal, err := product.PriceMoney.Mul(int64(lines[i].Quantity))
+		if err == nil {
+			if i == 0 {
+				order.TotalPriceMoney = subtotal
+			} else {
+				order.TotalPriceMoney, err = order.TotalPriceMoney.Add(subtotal)
+			}
+		}
+		if err != nil {
+			return batchItemError("items", i, err)
+		}
+
+		order.Items[i] = model.OrderItem{
+			ProductID:      product.ID,
+			ProductName:    product.Name,
+			Quantity:       lines[i].Quantity,
+			UnitPrice:      product.Price,
+			UnitPriceMoney: product.PriceMoney,
+		}
+	}
+	order.TotalPrice = order.TotalPriceMoney.Float64()
+	return nil
+}
+
+func (s *OrderService) GetOrder(ctx context.Context, id string) (*model.Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	order, err := s.getOrderLocked(tenant.FromContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	return copyOrder(order), nil
+}
+
+// getOrderLocked returns the order with id in a tenant. s.mu must be held.
+func (s *OrderService) getOrderLocked(tenantID, id string) (*model.Order, error) {
+	order, exists := s.orders[id]
+	if !exists || order.TenantID != tenantID {
+		return nil, errors.NewNotFoundError("order", id)
+	}
+	return order, nil
+}
+
+// copyOrder copies a stored order, so that it can be read once s.mu is
+// released. Items never change after an order is placed, so they are shared.
+// s.mu must be held.
+func copyOrder(order *model.Order) *model.Order {
+	copied := *order
+	return &copied
+}
+
+// tenantInUse reports whether a tenant has orders
+func (s *OrderService) tenantInUse(tenantID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, order := range s.orders {
+		if order.TenantID == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListOrders returns a user's orders, oldest first
+func (s *OrderService) ListOrders(ctx context.Context, req *model.ListOrdersRequest) ([]model.Order, int32, int32, int32, error) {
+	if req.UserID == "" {
+		return nil, 0, 0, 0, errors.NewValidationError("user_id", "user_id is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+	orders := make([]model.Order, 0, len(s.userOrders[req.UserID]))
+	for _, order := range s.userOrders[req.UserID] {
+		if order.TenantID == tenantID {
+			orders = append(orders, *order)
+		}
+	}
+
+	paged, total, page, pageSize := paginate(orders, req.Page, req.PageSize)
+	return paged, total, page, pageSize, nil
+}
+
+// CancelOrder cancels a placed order and returns its items to stock
+func (s *OrderService) CancelOrder(ctx context.Context, id string) (*model.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, err := s.getOrderLocked(tenant.FromContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != model.OrderStatusPlaced {
+		return nil, errors.NewFailedPreconditionError(fmt.Sprintf("order %s is already %s", id, strings.ToLower(string(order.Status))))
+	}
+
+	lines := make([]model.StockLine, len(order.Items))
+	for i, item := range order.Items {
+		lines[i] = model.StockLine{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	if err := s.productService.RestoreStock(ctx, lines, "order "+id+" cancelled"); err != nil {
+		return nil, err
+	}
+
+	order.Status = model.OrderStatusCancelled
+	order.UpdatedAt = time.Now()
+	return copyOrder(order), nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderServiceTestSuite struct {
+	suite.Suite
+	service        *OrderService
+	userService    *UserService
+	productService *ProductService
+	user           *model.User
+	laptop         *model.Product
+	mouse          *model.Product
+}
+
+func (suite *OrderServiceTestSuite) SetupTest() {
+	suite.userService = NewUserService()
+	suite.productService = NewProductService(NewCategoryService())
+	suite.service = NewOrderService(suite.userService, suite.productService)
+
+	var err error
+	suite.user, err = suite.userService.CreateUser(context.Background(), &model.CreateUserRequest{
+		Username: "buyer", Email: "buyer@example.com", FullName: "Buyer",
+	})
+	assert.NoError(suite.T(), err)
+
+	suite.laptop, err = suite.productService.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Laptop", Description: "Portable", Price: 1000, Quantity: 5, Category: "Electronics",
+	})
+	assert.NoError(suite.T(), err)
+	suite.mouse, err = suite.productService.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Mouse", Description: "Wireless", Price: 25.5, Quantity: 2, Category: "Electronics",
+	})
+	assert.NoError(suite.T(), err)
+}
+
+// quantity returns the stock a product has now
+func (suite *OrderServiceTestSuite) quantity(id string) int32 {
+	product, err := suite.productService.GetProduct(context.Background(), id)
+	suite.Require().NoError(err)
+	return product.Quantity
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder() {
+	order, err := suite.service.CreateOrder(context.Background(), &model.CreateOrderRequest{
+		UserID: suite.user.ID,
+		Items: []model.CreateOrderItem{
+			{ProductID: suite.laptop.ID, Quantity: 2},
+			{ProductID: suite.mouse.ID, Quantity: 1},
+		},
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.OrderStatusPlaced, order.Status)
+	assert.Equal(suite.T(), 2025.5, order.TotalPrice)
+	assert.Equal(suite.T(), "Laptop", order.Items[0].ProductName)
+	assert.Equal(suite.T(), int32(3), suite.quantity(suite.laptop.ID))
+	assert.Equal(suite.T(), int32(1), suite.quantity(suite.mouse.ID))
+
+	// Later price changes do not alter the order
+	price := 1200.0
+	_, err = suite.productService.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: suite.laptop.ID, Price: &price})
+	assert.NoError(suite.T(), err)
+	got, err := suite.service.GetOrder(context.Background(), order.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1000.0, got.Items[0].UnitPrice)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrderTotalIsExact() {
+	price := 0.1
+	_, err := suite.productService.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: suite.laptop.ID, Price: &price})
+	suite.Require().NoError(err)
+
+	order, err := suite.service.CreateOrder(context.Background(), &model.CreateOrderRequest{
+		UserID: suite.user.ID,
+		Items:  []model.CreateOrderItem{{ProductID: suite.laptop.ID, Quantity: 3}},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "0.3 USD", order.TotalPriceMoney.String())
+	assert.Equal(suite.T(), 0.3, order.TotalPrice)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrderRejectsMixedCurrencies() {
+	euros, err := model.ParseMoney("EUR", "20")
+	suite.Require().NoError(err)
+	_, err = suite.productService.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: suite.mouse.ID, PriceMoney: &euros})
+	suite.Require().NoError(err)
+
+	_, err = suite.service.CreateOrder(context.Background(), &model.CreateOrderRequest{
+		UserID: suite.user.ID,
+		Items: []model.CreateOrderItem{
+			{ProductID: suite.laptop.ID, Quantity: 1},
+			{ProductID: suite.mouse.ID, Quantity: 1},
+		},
+	})
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+	assert.Equal(suite.T(), int32(5), suite.quantity(suite.laptop.ID))
+	assert.Equal(suite.T(), int32(2), suite.quantity(suite.mouse.ID))
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrderInsufficientStockIsAtomic() {
+	_, err := suite.service.CreateOrder(context.Background(), &model.CreateOrderRequest{
+		UserID: suite.user.ID,
+		Items: []model.CreateOrderItem{
+			{ProductID: suite.laptop.ID, Quantity: 1},
+			{ProductID: suite.mouse.ID, Quantity: 2},
+			{ProductID: suite.mouse.ID, Quantity: 1},
+		},
+	})
+
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+	assert.Equal(suite.T(), int32(5), suite.quantity(suite.laptop.ID))
+	assert.Equal(suite.T(), int32(2), suite.quantity(suite.mouse.ID))
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrderRequiresActiveUser() {
+	inactive := false
+	_, err := suite.userService.UpdateUser(context.Background(), &model.UpdateUserRequest{ID: suite.user.ID, IsActive: &inactive})
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.service.CreateOrder(context.Background(), &model.CreateOrderRequest{
+		UserID: suite.user.ID,
+		Items:  []model.CreateOrderItem{{ProductID: suite.laptop.ID, Quantity: 1}},
+	})
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+
+	_, err = suite.service.CreateOrder(context.Background(), &model.CreateOrderRequest{
+		UserID: "missing",
+		Items:  []model.CreateOrderItem{{ProductID: suite.laptop.ID, Quantity: 1}},
+	})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+	assert.Equal(suite.T(), int32(5), suite.quantity(suite.laptop.ID))
+}
+
+func (suite *OrderServiceTestSuite) TestCancelOrderRestoresStock() {
+	order, err := suite.service.CreateOrder(context.Background(), &model.CreateOrderRequest{
+		UserID: suite.user.ID,
+		Items:  []model.CreateOrderItem{{ProductID: suite.laptop.ID, Quantity: 4}},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), suite.quantity(suite.laptop.ID))
+
+	cancelled, err := suite.service.CancelOrder(context.Background(), order.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.OrderStatusCancelled, cancelled.Status)
+	assert.Equal(suite.T(), int32(5), suite.quantity(suite.laptop.ID))
+
+	_, err = suite.service.CancelOrder(context.Background(), order.ID)
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+	assert.Equal(suite.T(), int32(5), suite.quantity(suite.laptop.ID))
+}
+
+func (suite *OrderServiceTestSuite) TestListOrders() {
+	for range 3 {
+		_, err := suite.service.CreateOrder(context.Background(), &model.CreateOrderRequest{
+			UserID: suite.user.ID,
+			Items:  []model.CreateOrderItem{{ProductID: suite.laptop.ID, Quantity: 1}},
+		})
+		assert.NoError(suite.T(), err)
+	}
+
+	orders, total, page, pageSize, err := suite.service.ListOrders(context.Background(), &model.ListOrdersRequest{
+		UserID: suite.user.ID, Page: 2, PageSize: 2,
+	})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), orders, 1)
+	assert.Equal(suite.T(), "3", orders[0].ID)
+	assert.Equal(suite.T(), int32(3), total)
+	assert.Equal(suite.T(), int32(2), page)
+	assert.Equal(suite.T(), int32(2), pageSize)
+
+	_, _, _, _, err = suite.service.ListOrders(context.Background(), &model.ListOrdersRequest{})
+	assert.Error(suite.T(), err)
+}
+
+func TestOrderServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderServiceTestSuite))
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+)
+
+// categoryInUse reports whether any product of a tenant is linked to the
+// category
+func (s *ProductService) categoryInUse(tenantID, categoryID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, product := range s.products {
+		if product.TenantID == tenantID && product.CategoryID == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// renameCategory keeps the legacy category name of a tenant's linked
+// products in step with the category they are linked to.
+func (s *ProductService) renameCategory(tenantID, categoryID, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, product := range s.products {
+		if product.TenantID == tenantID && product.CategoryID == categoryID && product.Category != name {
+			product.Category = name
+			product.UpdatedAt = now
+			s.publish(model.EventUpdated, product)
+		}
+	}
+}
+
+// MigrateCategories links every product of the tenant that only has a legacy
+// category name to a category record of the tenant. Names are matched by
+// slug, so "Electronics" and "electronics " share one category; missing
+// categories are created at the top level. Running it again changes nothing.
+func (s *ProductService) MigrateCategories(ctx context.Context) (*model.CategoryMigrationSummary, error) {
+	tenantID := tenant.FromContext(ctx)
+	release, err := s.tenants.hold(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	s.categories.mu.Lock()
+	defer s.categories.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Visit products in creation order so the first spelling of a name wins
+	products := make([]*model.Product, 0, len(s.products))
+	for _, product := range s.products {
+		if product.TenantID == tenantID && product.CategoryID == "" {
+			products = append(products, product)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].CreatedAt.Before(products[j].CreatedAt) ||
+			products[i].CreatedAt.Equal(products[j].CreatedAt) && products[i].ID < products[j].ID
+	})
+
+	summary := &model.CategoryMigrationSummary{}
+	now := time.Now()
+	for _, product := range products {
+		name := strings.TrimSpace(product.Category)
+		slug := slugify(name)
+		if slug == "" {
+			continue
+		}
+		category := s.categories.findBySlugLocked(tenantID, slug)
+		if category == nil {
+			category = s.categories.insertCategoryLocked(tenantID, name, slug, "")
+			summary.CategoriesCreated++
+		}
+
+		product.CategoryID = category.ID
+		product.Category = category.Name
+		product.UpdatedAt = now
+		s.publish(model.EventUpdated, product)
+		summary.ProductsUpdated++
+	}
+	return summary, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+)
+
+// facetPlan is a validated facet request
+type facetPlan struct {
+	category bool
+	price    bool
+	stock    bool
+	bounds   []model.Money
+}
+
+func newFacetPlan(req *model.SearchProductsRequest) (*facetPlan, error) {
+	if len(req.Facets) == 0 {
+		if len(req.PriceBucketBounds) > 0 {
+			return nil, errors.NewValidationError("price_bucket_bounds", "price_bucket_bounds requires the price facet")
+		}
+		return nil, nil
+	}
+
+	plan := &facetPlan{}
+	for _, facet := range req.Facets {
+		switch facet {
+		case model.FacetCategory:
+			plan.category = true
+		case model.FacetPrice:
+			plan.price = true
+		case model.FacetStock:
+			plan.stock = true
+		default:
+			return nil, errors.NewValidationError("facets", fmt.Sprintf("unknown facet %q", facet))
+		}
+	}
+
+	if len(req.PriceBucketBounds) > 0 && !plan.price {
+		return nil, errors.NewValidationError("price_bucket_bounds", "price_bucket_bounds requires the price facet")
+	}
+	// Copied because the buckets returned point into the bounds
+	plan.bounds = append([]model.Money(nil), req.PriceBucketBounds...)
+	if len(plan.bounds) == 0 {
+		plan.bounds = append(plan.bounds, model.DefaultPriceBucketBounds...)
+	}
+	for i := 1; i < len(plan.bounds); i++ {
+		if plan.bounds[i].CurrencyCode != plan.bounds[0].CurrencyCode {
+			return nil, errors.NewValidationError("price_bucket_bounds", "price_bucket_bounds must use one currency")
+		}
+		if plan.bounds[i].Cmp(plan.bounds[i-1]) <= 0 {
+			return nil, errors.NewValidationError("price_bucket_bounds", "price_bucket_bounds must be in ascending order")
+		}
+	}
+	return plan, nil
+}
+
+// compute counts the requested facets over every matching product
+func (p *facetPlan) compute(products []model.Product) *model.ProductFacets {
+	facets := &model.ProductFacets{}
+	if p.category {
+		facets.Categories = categoryFacet(products)
+	}
+	if p.price {
+		facets.PriceBuckets = priceFacet(products, p.bounds)
+	}
+	if p.stock {
+		facets.Stock = &model.StockFacetCount{}
+		for _, product := range products {
+			if product.Quantity > 0 {
+				facets.Stock.InStock++
+			} else {
+				facets.Stock.OutOfStock++
+			}
+		}
+	}
+	return facets
+}
+
+// categoryFacet counts products per linked category, or per legacy category
+// name for products not linked to one.
+func categoryFacet(products []model.Product) []model.CategoryFacetCount {
+	type key struct{ id, name string }
+	counts := make(map[key]int32)
+	for _, product := range products {
+		k := key{id: product.CategoryID, name: product.Category}
+		counts[k]++
+	}
+
+	result := make([]model.CategoryFacetCount, 0, len(counts))
+	for k, count := range counts {
+		result = append(result, model.CategoryFacetCount{Category: k.name, CategoryID: k.id, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		if result[i].Category != result[j].Category {
+			return result[i].Category < result[j].Category
+		}
+		return result[i].CategoryID < result[j].CategoryID
+	})
+	return result
+}
+
+// priceFacet counts products into the buckets delimited by bounds. Products
+// priced in another currency cannot be compared and are left out.
+func priceFacet(products []model.Product, bounds []model.Money) []model.PriceBucketCount {
+	buckets := make([]model.PriceBucketCount, len(bounds)+1)
+	for i := range bounds {
+		buckets[i].Max = &bounds[i]
+		buckets[i+1].Min = &bounds[i]
+	}
+
+	for _, product := range products {
+		if product.PriceMoney.CurrencyCode != bounds[0].CurrencyCode {
+			continue
+		}
+		i := sort.Search(len(bounds), func(i int) bool {
+			return product.PriceMoney.Cmp(bounds[i]) < 0
+		})
+		buckets[i].Count++
+	}
+	return buckets
+}
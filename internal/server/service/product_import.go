@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+)
+
+const (
+	// maxImportFailures bounds the failure details returned by an import
+	maxImportFailures = 100
+	// maxImportLineSize bounds a single NDJSON line
+	maxImportLineSize = 1 << 20
+)
+
+// importRow is one decoded row of an import document. Err is set when the
+// row could not be decoded; the import continues with the next row.
+type importRow struct {
+	line int32
+	req  model.CreateProductRequest
+	err  error
+}
+
+// rowDecoder yields rows until it returns io.EOF
+type rowDecoder interface {
+	next() (*importRow, error)
+}
+
+// ImportProducts stream-parses a CSV or NDJSON document and upserts each row
+// into the request's tenant, matching the tenant's products by name and
+// category (case-insensitive). Rows are validated like CreateProduct;
+// invalid rows are reported and skipped.
+func (s *ProductService) ImportProducts(ctx context.Context, format string, r io.Reader) (*model.ImportProductsSummary, error) {
+	var decoder rowDecoder
+	switch format {
+	case model.ImportFormatCSV:
+		d, err := newCSVRowDecoder(r)
+		if err != nil {
+			return nil, err
+		}
+		decoder = d
+	case model.ImportFormatNDJSON:
+		decoder = newNDJSONRowDecoder(r)
+	default:
+		return nil, errors.NewValidationError("format", fmt.Sprintf("unsupported import format %q (use csv or ndjson)", format))
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	summary := &model.ImportProductsSummary{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		row, err := decoder.next()
+		if err == io.EOF {
+			return summary, nil
+		}
+		if err != nil {
+			return nil, errors.NewInvalidRequestError("failed to read import data: " + err.Error())
+		}
+
+		if row.err == nil {
+			row.err = validateCreateProductRequest(&row.req)
+		}
+		var created bool
+		if row.err == nil {
+			created, row.err = s.upsertProduct(tenantID, &row.req)
+		}
+		if row.err != nil {
+			summary.Failed++
+			if len(summary.Failures) < maxImportFailures {
+				summary.Failures = append(summary.Failures, model.ImportFailure{
+					Line:    row.line,
+					Message: importErrorMessage(row.err),
+				})
+			}
+			continue
+		}
+
+		if created {
+			summary.Created++
+		} else {
+			summary.Updated++
+		}
+	}
+}
+
+// upsertProduct creates the product in a tenant or updates the tenant's
+// product sharing its natural key, reporting whether it was created.
+func (s *ProductService) upsertProduct(tenantID string, req *model.CreateProductRequest) (bool, error) {
+	release, err := s.tenants.hold(tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	unlock := s.categories.readLock()
+	defer unlock()
+
+	category, err := s.categories.referenceLocked(tenantID, req.CategoryID)
+	if err != nil {
+		return false, err
+	}
+	update := &model.UpdateProductRequest{
+		Name:        &req.Name,
+		Description: &req.Description,
+		PriceMoney:  req.PriceMoney,
+		Quantity:    &req.Quantity,
+		Category:    &req.Category,
+	}
+	if category != nil {
+		update.Category = &category.Name
+		update.CategoryID = &category.ID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, product := range s.products {
+		if product.TenantID == tenantID && strings.EqualFold(product.Name, req.Name) && strings.EqualFold(product.Category, *update.Category) {
+			applyProductUpdate(product, update, category)
+			s.publish(model.EventUpdated, product)
+			return false, nil
+		}
+	}
+
+	product := s.insertProductLocked(tenantID, req, category)
+	s.publish(model.EventCreated, product)
+	return true, nil
+}
+
+func importErrorMessage(err error) string {
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return err.Error()
+}
+
+// csvRowDecoder reads rows from a CSV document whose first record is a
+// header naming the columns in any order.
+type csvRowDecoder struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+var importColumns = []string{"name", "description", "price", "quantity", "category"}
+
+func newCSVRowDecoder(r io.Reader) (*csvRowDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.NewValidationError("data", "CSV document is empty")
+	}
+	if err != nil {
+		return nil, errors.NewInvalidRequestError("invalid CSV header: " + err.Error())
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.NewValidationError("data", fmt.Sprintf("CSV header is missing column %q", name))
+		}
+	}
+
+	return &csvRowDecoder{reader: reader, columns: columns}, nil
+}
+
+func (d *csvRowDecoder) next() (*importRow, error) {
+	record, err := d.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if stderrors.As(err, &parseErr) {
+		return &importRow{line: int32(parseErr.Line), err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := d.reader.FieldPos(0)
+	row := &importRow{line: int32(line)}
+	field := func(name string) string {
+		return strings.TrimSpace(record[d.columns[name]])
+	}
+
+	row.req = model.CreateProductRequest{
+		Name:        field("name"),
+		Description: field("description"),
+		Category:    field("category"),
+	}
+	// Prices are parsed as exact decimals in the optional currency_code column
+	currency := model.DefaultCurrency
+	if i, ok := d.columns["currency_code"]; ok && strings.TrimSpace(record[i]) != "" {
+		currency = strings.TrimSpace(record[i])
+	}
+	price, err := model.ParseMoney(currency, field("price"))
+	if err != nil {
+		row.err = err
+		return row, nil
+	}
+	row.req.Price = price.Float64()
+	row.req.PriceMoney = &price
+	quantity, err := strconv.ParseInt(field("quantity"), 10, 32)
+	if err != nil {
+		row.err = fmt.Errorf("invalid quantity %q", field("quantity"))
+		return row, nil
+	}
+	row.req.Quantity = int32(quantity)
+
+	return row, nil
+}
+
+// ndjsonRowDecoder reads one JSON product object per line, skipping blank lines
+type ndjsonRowDecoder struct {
+	scanner *bufio.Scanner
+	line    int32
+}
+
+func newNDJSONRowDecoder(r io.Reader) *ndjsonRowDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+	return &ndjsonRowDecoder{scanner: scanner}
+}
+
+func (d *ndjsonRowDecoder) next() (*importRow, error) {
+	for d.scanner.Scan() {
+		d.line++
+		data := bytes.TrimSpace(d.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		row := &importRow{line: d.line}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.req); err != nil {
+			row.err = fmt.Errorf("invalid JSON: %v", err)
+		}
+		return row, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -10,20 +9,38 @@ import (
 	"time"
 
 	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/filter"
 	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
 )
 
 type ProductService struct {
-	products map[string]*model.Product
-	nextID   int64
-	mu       sync.RWMutex
+	products          map[string]*model.Product
+	reservations      map[string]*model.Reservation
+	nextID            int64
+	nextReservationID int64
+	mu                sync.RWMutex
+	events            *eventBroker[model.ProductEvent]
+	categories        *CategoryService
+	// tenants is nil unless a tenant service manages the tenants products
+	// are created in
+	tenants *TenantService
 }
 
-func NewProductService() *ProductService {
-	return &ProductService{
-		products: make(map[string]*model.Product),
-		nextID:   1,
+// NewProductService creates a product service whose category references are
+// checked against categories.
+func NewProductService(categories *CategoryService) *ProductService {
+	s := &ProductService{
+		products:          make(map[string]*model.Product),
+		reservations:      make(map[string]*model.Reservation),
+		nextID:            1,
+		nextReservationID: 1,
+		events:            newEventBroker[model.ProductEvent](),
+		categories:        categories,
 	}
+	categories.products = s
+	return s
 }
 
 func (s *ProductService) generateID() string {
@@ -31,58 +48,434 @@ func (s *ProductService) generateID() string {
 }
 
 func (s *ProductService) CreateProduct(ctx context.Context, req *model.CreateProductRequest) (*model.Product, error) {
-	if req.Name == "" || req.Description == "" || req.Category == "" {
-		return nil, errors.NewValidationError("fields", "name, description, and category are required")
+	if err := validateCreateProductRequest(req); err != nil {
+		return nil, err
 	}
-	if req.Price < 0 || req.Quantity < 0 {
-		return nil, errors.NewValidationError("value", "price and quantity cannot be negative")
+
+	tenantID := tenant.FromContext(ctx)
+	release, err := s.tenants.hold(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	unlock := s.categories.readLock()
+	defer unlock()
+
+	category, err := s.categories.referenceLocked(tenantID, req.CategoryID)
+	if err != nil {
+		return nil, err
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	product := s.insertProductLocked(tenantID, req, category)
+	s.publish(model.EventCreated, product)
+	return copyProduct(product), nil
+}
+
+func validateCreateProductRequest(req *model.CreateProductRequest) error {
+	if err := validation.Validate(req); err != nil {
+		return err
+	}
+	// The legacy price is converted once, so later steps only see PriceMoney
+	if req.PriceMoney == nil {
+		price, err := model.MoneyFromFloat(model.DefaultCurrency, req.Price)
+		if err != nil {
+			return err
+		}
+		req.PriceMoney = &price
+	}
+	return nil
+}
+
+// insertProductLocked stores a new product of a tenant, linked to category
+// when it is not nil. s.mu must be held.
+func (s *ProductService) insertProductLocked(tenantID string, req *model.CreateProductRequest, category *model.Category) *model.Product {
 	now := time.Now()
 	product := &model.Product{
 		ID:          s.generateID(),
+		TenantID:    tenantID,
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       req.Price,
 		Quantity:    req.Quantity,
 		Category:    req.Category,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
+	setPrice(product, *req.PriceMoney)
+	if category != nil {
+		product.CategoryID = category.ID
+		product.Category = category.Name
+	}
 
 	s.products[product.ID] = product
-	return product, nil
+	return product
 }
 
 func (s *ProductService) GetProduct(ctx context.Context, id string) (*model.Product, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	product, err := s.getProductLocked(tenant.FromContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	return copyProduct(product), nil
+}
+
+// getProductLocked returns the product with id in a tenant. Products of other
+// tenants are reported missing, so that their IDs reveal nothing. s.mu must
+// be held.
+func (s *ProductService) getProductLocked(tenantID, id string) (*model.Product, error) {
 	product, exists := s.products[id]
-	if !exists {
+	if !exists || product.TenantID != tenantID {
 		return nil, errors.NewNotFoundError("product", id)
 	}
 	return product, nil
 }
 
+// copyProduct copies a stored product, so that it can be read once s.mu is
+// released while writers, such as the reservation reaper, change the
+// original. s.mu must be held.
+func copyProduct(product *model.Product) *model.Product {
+	copied := *product
+	return &copied
+}
+
+// tenantInUse reports whether a tenant has products
+func (s *ProductService) tenantInUse(tenantID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, product := range s.products {
+		if product.TenantID == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ProductService) UpdateProduct(ctx context.Context, req *model.UpdateProductRequest) (*model.Product, error) {
+	if err := validateUpdateProductRequest(req); err != nil {
+		return nil, err
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	unlock := s.categories.readLock()
+	defer unlock()
+
+	category, err := s.categoryForUpdateLocked(tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	product, err := s.getProductLocked(tenantID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyProductUpdate(product, req, category)
+	s.publish(model.EventUpdated, product)
+	return copyProduct(product), nil
+}
+
+// DeleteProduct removes a product along with its open reservations. Orders
+// keep their copy of the product, so cancelling them later skips its stock.
+func (s *ProductService) DeleteProduct(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	product, err := s.getProductLocked(tenant.FromContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	for reservationID, reservation := range s.reservations {
+		if reservation.ProductID == id {
+			delete(s.reservations, reservationID)
+		}
+	}
+	delete(s.products, id)
+	s.publish(model.EventDeleted, product)
+	return nil
+}
+
+func validateUpdateProductRequest(req *model.UpdateProductRequest) error {
+	if err := validation.Validate(req); err != nil {
+		return err
+	}
+	if req.Price != nil && req.PriceMoney == nil {
+		if _, err := model.MoneyFromFloat(model.DefaultCurrency, *req.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categoryForUpdateLocked resolves the category an update links a tenant's
+// product to, nil when it does not link one. The category lock must be held.
+func (s *ProductService) categoryForUpdateLocked(tenantID string, req *model.UpdateProductRequest) (*model.Category, error) {
+	if req.CategoryID == nil {
+		return nil, nil
+	}
+	return s.categories.referenceLocked(tenantID, *req.CategoryID)
+}
+
+func applyProductUpdate(product *model.Product, req *model.UpdateProductRequest, category *model.Category) {
+	if req.Name != nil {
+		product.Name = *req.Name
+	}
+	if req.Description != nil {
+		product.Description = *req.Description
+	}
+	if req.PriceMoney != nil {
+		setPrice(product, *req.PriceMoney)
+	} else if req.Price != nil {
+		// Validated by validateUpdateProductRequest
+		price, _ := model.MoneyFromFloat(product.PriceMoney.CurrencyCode, *req.Price)
+		setPrice(product, price)
+	}
+	if req.Quantity != nil {
+		product.Quantity = *req.Quantity
+	}
+	// A legacy name other than the linked category's detaches the product from it
+	if req.Category != nil && (product.CategoryID == "" || !strings.EqualFold(product.Category, *req.Category)) {
+		product.Category = *req.Category
+		product.CategoryID = ""
+	}
+	if req.CategoryID != nil {
+		product.CategoryID = *req.CategoryID
+	}
+	if category != nil {
+		product.Category = category.Name
+	}
+	product.UpdatedAt = time.Now()
+}
+
+// setPrice sets the price and its deprecated floating-point mirror
+func setPrice(product *model.Product, price model.Money) {
+	product.PriceMoney = price
+	product.Price = price.Float64()
+}
+
+// BatchCreateProducts creates products under a single lock with the same
+// rules as CreateProduct. In atomic mode the first invalid item aborts the
+// whole batch; otherwise each item reports its own outcome.
+func (s *ProductService) BatchCreateProducts(ctx context.Context, req *model.BatchCreateProductsRequest) ([]model.BatchProductResult, error) {
+	if err := validateBatchSize("requests", len(req.Requests)); err != nil {
+		return nil, err
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	release, err := s.tenants.hold(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	unlock := s.categories.readLock()
+	defer unlock()
+
+	results := make([]model.BatchProductResult, len(req.Requests))
+	categories := make([]*model.Category, len(req.Requests))
+	for i := range req.Requests {
+		err := validateCreateProductRequest(&req.Requests[i])
+		if err == nil {
+			categories[i], err = s.categories.referenceLocked(tenantID, req.Requests[i].CategoryID)
+		}
+		if err != nil {
+			if req.Atomic {
+				return nil, batchItemError("requests", i, err)
+			}
+			results[i].Error = errors.AsAppError(err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range req.Requests {
+		if results[i].Error != nil {
+			continue
+		}
+		product := s.insertProductLocked(tenantID, &req.Requests[i], categories[i])
+		s.publish(model.EventCreated, product)
+		results[i].Product = copyProduct(product)
+	}
+	return results, nil
+}
+
+// BatchGetProducts retrieves products in request order. In atomic mode a
+// missing product fails the whole call.
+func (s *ProductService) BatchGetProducts(ctx context.Context, req *model.BatchGetProductsRequest) ([]model.BatchProductResult, error) {
+	if err := validateBatchSize("ids", len(req.IDs)); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+	results := make([]model.BatchProductResult, len(req.IDs))
+	for i, id := range req.IDs {
+		product, err := s.getProductLocked(tenantID, id)
+		if err != nil {
+			if req.Atomic {
+				return nil, batchItemError("ids", i, err)
+			}
+			results[i].Error = errors.AsAppError(err)
+			continue
+		}
+		results[i].Product = copyProduct(product)
+	}
+	return results, nil
+}
+
+// BatchUpdateProducts updates products under a single lock with the same
+// rules as UpdateProduct. In atomic mode every item is checked before any
+// change is applied.
+func (s *ProductService) BatchUpdateProducts(ctx context.Context, req *model.BatchUpdateProductsRequest) ([]model.BatchProductResult, error) {
+	if err := validateBatchSize("requests", len(req.Requests)); err != nil {
+		return nil, err
+	}
+
+	unlock := s.categories.readLock()
+	defer unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	results := make([]model.BatchProductResult, len(req.Requests))
+	categories := make([]*model.Category, len(req.Requests))
+	for i := range req.Requests {
+		var err error
+		if categories[i], err = s.checkUpdateLocked(tenantID, &req.Requests[i]); err != nil {
+			if req.Atomic {
+				return nil, batchItemError("requests", i, err)
+			}
+			results[i].Error = errors.AsAppError(err)
+		}
+	}
+
+	for i := range req.Requests {
+		if results[i].Error != nil {
+			continue
+		}
+		product := s.products[req.Requests[i].ID]
+		applyProductUpdate(product, &req.Requests[i], categories[i])
+		s.publish(model.EventUpdated, product)
+		results[i].Product = copyProduct(product)
+	}
+	return results, nil
+}
+
+// checkUpdateLocked validates an update of a tenant's product and resolves
+// the category it links, if any. Both the category and product locks must be
+// held.
+func (s *ProductService) checkUpdateLocked(tenantID string, req *model.UpdateProductRequest) (*model.Category, error) {
+	if err := validateUpdateProductRequest(req); err != nil {
+		return nil, err
+	}
+	category, err := s.categoryForUpdateLocked(tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getProductLocked(tenantID, req.ID); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
 func (s *ProductService) SearchProducts(ctx context.Context, req *model.SearchProductsRequest) ([]model.Product, int32, int32, int32, error) {
+	products, _, total, page, pageSize, err := s.SearchProductsWithFacets(ctx, req)
+	return products, total, page, pageSize, err
+}
+
+// SearchProductsWithFacets searches like SearchProducts and also returns the
+// facets named in req.Facets, computed over all matching products before
+// pagination. The facets are nil when none are requested.
+func (s *ProductService) SearchProductsWithFacets(ctx context.Context, req *model.SearchProductsRequest) ([]model.Product, *model.ProductFacets, int32, int32, int32, error) {
+	plan, err := newFacetPlan(req)
+	if err != nil {
+		return nil, nil, 0, 0, 0, err
+	}
+	search, err := s.parseSearch(tenant.FromContext(ctx), req)
+	if err != nil {
+		return nil, nil, 0, 0, 0, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	products := s.filterProducts(req)
+	products := s.searchLocked(search)
 
-	sort.Slice(products, func(i, j int) bool {
-		return products[i].Name < products[j].Name
-	})
+	var facets *model.ProductFacets
+	if plan != nil {
+		facets = plan.compute(products)
+	}
 
 	paged, total, page, pageSize := paginate(products, req.Page, req.PageSize)
-	return paged, total, page, pageSize, nil
+	return paged, facets, total, page, pageSize, nil
+}
+
+// productSearch is a validated SearchProductsRequest with its order_by,
+// filter and category subtree parsed
+type productSearch struct {
+	tenantID    string
+	req         *model.SearchProductsRequest
+	terms       []orderTerm[model.Product]
+	match       *filter.Filter[model.Product]
+	categoryIDs map[string]bool
+}
+
+// parseSearch validates req and parses the parts of it that do not need the
+// product lock
+func (s *ProductService) parseSearch(tenantID string, req *model.SearchProductsRequest) (*productSearch, error) {
+	if req.MinPriceMoney != nil && req.MaxPriceMoney != nil && req.MinPriceMoney.CurrencyCode != req.MaxPriceMoney.CurrencyCode {
+		return nil, errors.NewValidationError("max_price_money", "min_price_money and max_price_money must use the same currency")
+	}
+	if err := req.TimeRange.Validate(); err != nil {
+		return nil, err
+	}
+	orderBy := req.OrderBy
+	if orderBy == "" {
+		orderBy = defaultProductOrder
+	}
+	terms, err := productOrderFields.parseOrderBy("order_by", orderBy)
+	if err != nil {
+		return nil, err
+	}
+	search := &productSearch{tenantID: tenantID, req: req, terms: terms}
+	if req.Filter != nil {
+		if search.match, err = productFilterSchema.Parse("filter", *req.Filter); err != nil {
+			return nil, err
+		}
+	}
+	if req.CategoryID != nil {
+		if search.categoryIDs, err = s.categories.subtreeIDs(tenantID, *req.CategoryID, req.IncludeDescendants); err != nil {
+			return nil, err
+		}
+	}
+	return search, nil
+}
+
+// searchLocked returns the ordered matches of a parsed search
+func (s *ProductService) searchLocked(search *productSearch) []model.Product {
+	products := s.filterProducts(search.tenantID, search.req, search.categoryIDs, search.match)
+	sortByTerms(products, search.terms)
+	return products
 }
 
-func (s *ProductService) filterProducts(req *model.SearchProductsRequest) []model.Product {
+// filterProducts returns copies of a tenant's products matching req and the
+// parsed req.Filter. When categoryIDs is not nil, only products linked to one
+// of them match.
+func (s *ProductService) filterProducts(tenantID string, req *model.SearchProductsRequest, categoryIDs map[string]bool, match *filter.Filter[model.Product]) []model.Product {
 	var products []model.Product
 	var queryLower string
 	if req.Query != nil {
@@ -90,7 +483,7 @@ func (s *ProductService) filterProducts(req *model.SearchProductsRequest) []mode
 	}
 
 	for _, product := range s.products {
-		if !s.matchesSearchCriteria(product, queryLower, req) {
+		if product.TenantID != tenantID || !s.matchesSearchCriteria(product, queryLower, req) || (categoryIDs != nil && !categoryIDs[product.CategoryID]) || !match.Match(product) {
 			continue
 		}
 		products = append(products, *product)
@@ -115,5 +508,66 @@ func (s *ProductService) matchesSearchCriteria(product *model.Product, query str
 	if req.MaxPrice != nil && product.Price > *req.MaxPrice {
 		return false
 	}
+	// Amounts in different currencies are not comparable, so they never match
+	if lower := req.MinPriceMoney; lower != nil && (product.PriceMoney.CurrencyCode != lower.CurrencyCode || product.PriceMoney.Cmp(*lower) < 0) {
+		return false
+	}
+	if upper := req.MaxPriceMoney; upper != nil && (product.PriceMoney.CurrencyCode != upper.CurrencyCode || product.PriceMoney.Cmp(*upper) > 0) {
+		return false
+	}
+	if !req.TimeRange.Contains(product.CreatedAt, product.UpdatedAt) {
+		return false
+	}
 	return true
 }
+
+// ExportProducts returns a point-in-time snapshot of the tenant's products
+// matching the search criteria, ordered like SearchProducts. Products are
+// copied under the read lock, so the snapshot is consistent and can be
+// encoded without holding it.
+func (s *ProductService) ExportProducts(ctx context.Context, req *model.ExportProductsRequest) ([]model.Product, error) {
+	search, err := s.parseSearch(tenant.FromContext(ctx), &model.SearchProductsRequest{
+		Query:              req.Query,
+		Category:           req.Category,
+		MinPrice:           req.MinPrice,
+		MaxPrice:           req.MaxPrice,
+		CategoryID:         req.CategoryID,
+		IncludeDescendants: req.IncludeDescendants,
+		TimeRange:          req.TimeRange,
+		OrderBy:            req.OrderBy,
+		Filter:             req.Filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.searchLocked(search), nil
+}
+
+// WatchProducts streams change events of the tenant's products, optionally
+// filtered by category. A non-zero SinceSequence replays retained events
+// published after it.
+func (s *ProductService) WatchProducts(ctx context.Context, req *model.WatchProductsRequest) (*Watcher[model.ProductEvent], error) {
+	tenantID := tenant.FromContext(ctx)
+	match := func(event model.ProductEvent) bool {
+		return event.Product.TenantID == tenantID && (req.Category == nil || strings.EqualFold(event.Product.Category, *req.Category))
+	}
+	return s.events.subscribe(ctx, req.SinceSequence, match)
+}
+
+// StopWatches ends every product watch with an unavailable error and
+// refuses new ones. Servers call it on shutdown, which open watches would
+// otherwise hold up.
+func (s *ProductService) StopWatches() {
+	s.events.close()
+}
+
+// publish must be called with s.mu held so events are sequenced in write order
+func (s *ProductService) publish(eventType model.EventType, product *model.Product) {
+	s.events.publish(func(seq int64) model.ProductEvent {
+		return model.ProductEvent{Sequence: seq, Type: eventType, Product: *product}
+	})
+}
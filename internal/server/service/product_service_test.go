@@ -3,9 +3,13 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"go-grpc-rest-demo/internal/server/errors"
 	"go-grpc-rest-demo/internal/server/model"
 
 	"github.com/stretchr/testify/assert"
@@ -18,7 +22,7 @@ type ProductServiceTestSuite struct {
 }
 
 func (suite *ProductServiceTestSuite) SetupTest() {
-	suite.service = NewProductService()
+	suite.service = NewProductService(NewCategoryService())
 }
 
 func (suite *ProductServiceTestSuite) TestCreateProduct() {
@@ -182,6 +186,481 @@ func (suite *ProductServiceTestSuite) TestSearchProductsWithNonexistentCategory(
 	assert.Equal(suite.T(), int32(0), totalCount)
 }
 
+func (suite *ProductServiceTestSuite) TestWatchProductsByCategory() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	category := "books"
+	watcher, err := suite.service.WatchProducts(ctx, &model.WatchProductsRequest{Category: &category})
+	assert.NoError(suite.T(), err)
+
+	for _, c := range []string{"Electronics", "Books"} {
+		_, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+			Name:        c + " Product",
+			Description: "Description",
+			Price:       9.99,
+			Quantity:    1,
+			Category:    c,
+		})
+		assert.NoError(suite.T(), err)
+	}
+
+	event := <-watcher.Events()
+	assert.Equal(suite.T(), int64(2), event.Sequence)
+	assert.Equal(suite.T(), model.EventCreated, event.Type)
+	assert.Equal(suite.T(), "Books", event.Product.Category)
+	assert.Len(suite.T(), watcher.Events(), 0)
+}
+
+func (suite *ProductServiceTestSuite) TestUpdateProduct() {
+	created, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Old Name", Description: "Description", Price: 5, Quantity: 1, Category: "Books",
+	})
+	assert.NoError(suite.T(), err)
+
+	newName := "New Name"
+	newPrice := 7.5
+	updated, err := suite.service.UpdateProduct(context.Background(), &model.UpdateProductRequest{
+		ID: created.ID, Name: &newName, Price: &newPrice,
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), newName, updated.Name)
+	assert.Equal(suite.T(), newPrice, updated.Price)
+	assert.Equal(suite.T(), "Books", updated.Category)
+
+	negative := int32(-1)
+	_, err = suite.service.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: created.ID, Quantity: &negative})
+	assert.Error(suite.T(), err)
+
+	_, err = suite.service.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: "nonexistent", Name: &newName})
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "not found")
+}
+
+func (suite *ProductServiceTestSuite) TestDeleteProduct() {
+	product := suite.createStockedProduct(5)
+	reservation, err := suite.service.ReserveStock(context.Background(), &model.ReserveStockRequest{ProductID: product.ID, Quantity: 2})
+	suite.Require().NoError(err)
+
+	assert.NoError(suite.T(), suite.service.DeleteProduct(context.Background(), product.ID))
+
+	_, err = suite.service.GetProduct(context.Background(), product.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+	_, err = suite.service.ReleaseReservation(context.Background(), reservation.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+
+	err = suite.service.DeleteProduct(context.Background(), product.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+}
+
+func (suite *ProductServiceTestSuite) TestBatchCreateProducts() {
+	requests := []model.CreateProductRequest{
+		{Name: "Valid", Description: "Description", Price: 1, Quantity: 1, Category: "Books"},
+		{Name: "Negative", Description: "Description", Price: -1, Quantity: 1, Category: "Books"},
+	}
+
+	_, err := suite.service.BatchCreateProducts(context.Background(), &model.BatchCreateProductsRequest{Requests: requests, Atomic: true})
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "requests[1]")
+	_, total, _, _, _ := suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{})
+	assert.Equal(suite.T(), int32(0), total)
+
+	results, err := suite.service.BatchCreateProducts(context.Background(), &model.BatchCreateProductsRequest{Requests: requests})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Valid", results[0].Product.Name)
+	assert.Nil(suite.T(), results[1].Product)
+	assert.NotNil(suite.T(), results[1].Error)
+	_, total, _, _, _ = suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{})
+	assert.Equal(suite.T(), int32(1), total)
+}
+
+func (suite *ProductServiceTestSuite) TestBatchUpdateProductsAtomic() {
+	created, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Original", Description: "Description", Price: 1, Quantity: 1, Category: "Books",
+	})
+	assert.NoError(suite.T(), err)
+
+	newName := "Renamed"
+	requests := []model.UpdateProductRequest{
+		{ID: created.ID, Name: &newName},
+		{ID: "nonexistent", Name: &newName},
+	}
+
+	_, err = suite.service.BatchUpdateProducts(context.Background(), &model.BatchUpdateProductsRequest{Requests: requests, Atomic: true})
+	assert.Error(suite.T(), err)
+	product, _ := suite.service.GetProduct(context.Background(), created.ID)
+	assert.Equal(suite.T(), "Original", product.Name)
+
+	results, err := suite.service.BatchUpdateProducts(context.Background(), &model.BatchUpdateProductsRequest{Requests: requests})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Renamed", results[0].Product.Name)
+	assert.NotNil(suite.T(), results[1].Error)
+
+	batch, err := suite.service.BatchGetProducts(context.Background(), &model.BatchGetProductsRequest{IDs: []string{created.ID}})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Renamed", batch[0].Product.Name)
+}
+
+func (suite *ProductServiceTestSuite) TestImportProductsCSV() {
+	_, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Laptop", Description: "Old", Price: 900, Quantity: 1, Category: "Electronics",
+	})
+	assert.NoError(suite.T(), err)
+
+	data := "category,name,description,price,quantity\n" +
+		"electronics,laptop,New model,999.5,10\n" +
+		"Books,Go Programming,A book,39.99,5\n" +
+		"Books,Broken,Bad price,abc,5\n" +
+		"Books,Negative,Bad quantity,1,-3\n"
+
+	summary, err := suite.service.ImportProducts(context.Background(), model.ImportFormatCSV, strings.NewReader(data))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), summary.Created)
+	assert.Equal(suite.T(), int32(1), summary.Updated)
+	assert.Equal(suite.T(), int32(2), summary.Failed)
+	assert.Equal(suite.T(), int32(4), summary.Failures[0].Line)
+	assert.Equal(suite.T(), int32(5), summary.Failures[1].Line)
+
+	product, err := suite.service.GetProduct(context.Background(), "1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "New model", product.Description)
+	assert.Equal(suite.T(), int32(10), product.Quantity)
+}
+
+func (suite *ProductServiceTestSuite) TestImportProductsNDJSON() {
+	data := `{"name":"Pen","description":"Blue pen","price":1.5,"quantity":100,"category":"Office"}
+
+{"name":"Pen","description":"Black pen","price":1.5,"quantity":50,"category":"office"}
+{"name":"","description":"Missing name","price":1,"quantity":1,"category":"Office"}
+not json
+`
+
+	summary, err := suite.service.ImportProducts(context.Background(), model.ImportFormatNDJSON, strings.NewReader(data))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), summary.Created)
+	assert.Equal(suite.T(), int32(1), summary.Updated)
+	assert.Equal(suite.T(), int32(2), summary.Failed)
+	assert.Equal(suite.T(), int32(4), summary.Failures[0].Line)
+	assert.Equal(suite.T(), int32(5), summary.Failures[1].Line)
+}
+
+func (suite *ProductServiceTestSuite) TestImportProductsInvalidInput() {
+	_, err := suite.service.ImportProducts(context.Background(), "xml", strings.NewReader(""))
+	assert.Error(suite.T(), err)
+
+	_, err = suite.service.ImportProducts(context.Background(), model.ImportFormatCSV, strings.NewReader("name,price\n"))
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "missing column")
+}
+
+func (suite *ProductServiceTestSuite) TestExportProductsFilters() {
+	for _, req := range []model.CreateProductRequest{
+		{Name: "Mouse", Description: "Wireless", Price: 25, Quantity: 10, Category: "Electronics"},
+		{Name: "Keyboard", Description: "Mechanical", Price: 80, Quantity: 5, Category: "Electronics"},
+		{Name: "Novel", Description: "Paperback", Price: 15, Quantity: 20, Category: "Books"},
+	} {
+		_, err := suite.service.CreateProduct(context.Background(), &req)
+		assert.NoError(suite.T(), err)
+	}
+
+	category, minPrice := "electronics", 20.0
+	products, err := suite.service.ExportProducts(context.Background(), &model.ExportProductsRequest{
+		Category: &category,
+		MinPrice: &minPrice,
+	})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), products, 2)
+	assert.Equal(suite.T(), "Keyboard", products[0].Name)
+	assert.Equal(suite.T(), "Mouse", products[1].Name)
+}
+
+func (suite *ProductServiceTestSuite) TestProductPriceMoney() {
+	legacy, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Pen", Description: "Blue", Price: 1.1, Quantity: 1, Category: "Office",
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.Money{CurrencyCode: "USD", Units: 1, Nanos: 100000000}, legacy.PriceMoney)
+
+	price, err := model.ParseMoney("EUR", "19.99")
+	suite.Require().NoError(err)
+	product, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Lamp", Description: "Desk", Price: 5, PriceMoney: &price, Quantity: 1, Category: "Office",
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), price, product.PriceMoney)
+	assert.Equal(suite.T(), 19.99, product.Price)
+
+	// A legacy price update keeps the product's currency
+	newPrice := 24.5
+	updated, err := suite.service.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: product.ID, Price: &newPrice})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "24.5 EUR", updated.PriceMoney.String())
+
+	negative := model.Money{CurrencyCode: "EUR", Units: -1}
+	_, err = suite.service.UpdateProduct(context.Background(), &model.UpdateProductRequest{ID: product.ID, PriceMoney: &negative})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *ProductServiceTestSuite) TestSearchProductsByPriceMoney() {
+	for _, p := range []struct{ name, currency, amount string }{
+		{"Cheap", "USD", "9.99"}, {"Exact", "USD", "10.00"}, {"Pricey", "USD", "10.01"}, {"Euro", "EUR", "10"},
+	} {
+		price, err := model.ParseMoney(p.currency, p.amount)
+		suite.Require().NoError(err)
+		_, err = suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+			Name: p.name, Description: p.name, PriceMoney: &price, Quantity: 1, Category: "Misc",
+		})
+		suite.Require().NoError(err)
+	}
+
+	lower, _ := model.ParseMoney("USD", "10")
+	upper, _ := model.ParseMoney("USD", "10.00")
+	products, total, _, _, err := suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{
+		MinPriceMoney: &lower, MaxPriceMoney: &upper,
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), total)
+	assert.Equal(suite.T(), "Exact", products[0].Name)
+
+	euros, _ := model.ParseMoney("EUR", "0")
+	_, _, _, _, err = suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{
+		MinPriceMoney: &euros, MaxPriceMoney: &upper,
+	})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *ProductServiceTestSuite) TestSearchProductsByUpdateTime() {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Old", "Recent"} {
+		product, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+			Name: name, Description: name, Price: 1, Quantity: 1, Category: "Misc",
+		})
+		suite.Require().NoError(err)
+		suite.service.products[product.ID].UpdatedAt = base.Add(time.Duration(i) * 24 * time.Hour)
+	}
+
+	// The lower bound is inclusive, so a product updated exactly then matches
+	after := base.Add(24 * time.Hour)
+	products, total, _, _, err := suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{
+		TimeRange: model.TimeRange{UpdatedAfter: &after},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), total)
+	assert.Equal(suite.T(), "Recent", products[0].Name)
+}
+
+func (suite *ProductServiceTestSuite) TestSearchProductsWithFilterExpression() {
+	for _, p := range []struct {
+		name     string
+		price    float64
+		quantity int32
+	}{
+		{"Laptop Pro", 1999, 0}, {"Laptop Air", 999, 3}, {"Mouse Pro", 49, 10},
+	} {
+		_, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+			Name: p.name, Description: p.name, Price: p.price, Quantity: p.quantity, Category: "Electronics",
+		})
+		suite.Require().NoError(err)
+	}
+
+	filter := "quantity > 0 AND (name:laptop OR price < 50)"
+	products, total, _, _, err := suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{Filter: &filter})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(2), total)
+	assert.Equal(suite.T(), "Laptop Air", products[0].Name)
+	assert.Equal(suite.T(), "Mouse Pro", products[1].Name)
+
+	filter = "stock > 0"
+	_, _, _, _, err = suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{Filter: &filter})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *ProductServiceTestSuite) TestSearchProductsOrderBy() {
+	for _, p := range []struct{ name, currency, amount string }{
+		{"Mouse", "USD", "25"}, {"Cable", "USD", "9.99"}, {"Keyboard", "USD", "25"}, {"Stand", "EUR", "30"},
+	} {
+		price, err := model.ParseMoney(p.currency, p.amount)
+		suite.Require().NoError(err)
+		_, err = suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+			Name: p.name, Description: p.name, PriceMoney: &price, Quantity: 1, Category: "Accessories",
+		})
+		suite.Require().NoError(err)
+	}
+
+	names := func(products []model.Product) []string {
+		result := make([]string, len(products))
+		for i, product := range products {
+			result[i] = product.Name
+		}
+		return result
+	}
+
+	products, _, _, _, err := suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Cable", "Keyboard", "Mouse", "Stand"}, names(products))
+
+	// Prices are grouped by currency, and equal prices are ordered by name
+	products, _, _, _, err = suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{OrderBy: "price desc, name"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Keyboard", "Mouse", "Cable", "Stand"}, names(products))
+
+	_, _, _, _, err = suite.service.SearchProducts(context.Background(), &model.SearchProductsRequest{OrderBy: "description"})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *ProductServiceTestSuite) TestSearchProductsWithFacets() {
+	for _, p := range []struct {
+		name, category, amount string
+		quantity               int32
+	}{
+		{"Laptop", "Electronics", "999", 5}, {"Phone", "Electronics", "499.99", 0},
+		{"Cable", "Electronics", "9.99", 10}, {"Novel", "Books", "15", 0},
+	} {
+		price, err := model.ParseMoney("USD", p.amount)
+		suite.Require().NoError(err)
+		_, err = suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+			Name: p.name, Description: p.name, PriceMoney: &price, Quantity: p.quantity, Category: p.category,
+		})
+		suite.Require().NoError(err)
+	}
+
+	products, facets, total, _, _, err := suite.service.SearchProductsWithFacets(context.Background(), &model.SearchProductsRequest{
+		PageSize: 1,
+		Facets:   []string{model.FacetCategory, model.FacetPrice, model.FacetStock},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), products, 1)
+	assert.Equal(suite.T(), int32(4), total)
+
+	// Facets cover every match, not just the returned page
+	assert.Equal(suite.T(), []model.CategoryFacetCount{
+		{Category: "Electronics", Count: 3}, {Category: "Books", Count: 1},
+	}, facets.Categories)
+	counts := make([]int32, len(facets.PriceBuckets))
+	for i, bucket := range facets.PriceBuckets {
+		counts[i] = bucket.Count
+	}
+	assert.Equal(suite.T(), []int32{1, 1, 0, 1, 1}, counts)
+	assert.Nil(suite.T(), facets.PriceBuckets[0].Min)
+	assert.Nil(suite.T(), facets.PriceBuckets[4].Max)
+	assert.Equal(suite.T(), model.StockFacetCount{InStock: 2, OutOfStock: 2}, *facets.Stock)
+
+	_, facets, _, _, _, err = suite.service.SearchProductsWithFacets(context.Background(), &model.SearchProductsRequest{})
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), facets)
+
+	_, _, _, _, _, err = suite.service.SearchProductsWithFacets(context.Background(), &model.SearchProductsRequest{Facets: []string{"color"}})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+
+	high, _ := model.ParseMoney("USD", "100")
+	low, _ := model.ParseMoney("USD", "10")
+	_, _, _, _, _, err = suite.service.SearchProductsWithFacets(context.Background(), &model.SearchProductsRequest{
+		Facets: []string{model.FacetPrice}, PriceBucketBounds: []model.Money{high, low},
+	})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *ProductServiceTestSuite) createStockedProduct(quantity int32) *model.Product {
+	product, err := suite.service.CreateProduct(context.Background(), &model.CreateProductRequest{
+		Name: "Widget", Description: "Stocked widget", Price: 5, Quantity: quantity, Category: "Parts",
+	})
+	assert.NoError(suite.T(), err)
+	return product
+}
+
+// quantity returns the stock a product has now
+func (suite *ProductServiceTestSuite) quantity(id string) int32 {
+	product, err := suite.service.GetProduct(context.Background(), id)
+	suite.Require().NoError(err)
+	return product.Quantity
+}
+
+func (suite *ProductServiceTestSuite) TestAdjustStock() {
+	product := suite.createStockedProduct(10)
+
+	updated, err := suite.service.AdjustStock(context.Background(), &model.AdjustStockRequest{
+		ProductID: product.ID, Delta: -4, Reason: "damaged",
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(6), updated.Quantity)
+
+	_, err = suite.service.AdjustStock(context.Background(), &model.AdjustStockRequest{
+		ProductID: product.ID, Delta: -7, Reason: "oversold",
+	})
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+	assert.Equal(suite.T(), int32(6), suite.quantity(product.ID))
+
+	_, err = suite.service.AdjustStock(context.Background(), &model.AdjustStockRequest{ProductID: product.ID, Delta: 1})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ProductServiceTestSuite) TestReservationLifecycle() {
+	product := suite.createStockedProduct(5)
+
+	reservation, err := suite.service.ReserveStock(context.Background(), &model.ReserveStockRequest{ProductID: product.ID, Quantity: 3})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(2), suite.quantity(product.ID))
+
+	_, err = suite.service.ReserveStock(context.Background(), &model.ReserveStockRequest{ProductID: product.ID, Quantity: 3})
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+
+	committed, err := suite.service.CommitReservation(context.Background(), reservation.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), reservation.ID, committed.ID)
+	assert.Equal(suite.T(), int32(2), suite.quantity(product.ID))
+
+	_, err = suite.service.ReleaseReservation(context.Background(), reservation.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+
+	reservation, err = suite.service.ReserveStock(context.Background(), &model.ReserveStockRequest{ProductID: product.ID, Quantity: 2})
+	assert.NoError(suite.T(), err)
+	released, err := suite.service.ReleaseReservation(context.Background(), reservation.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(2), released.Quantity)
+}
+
+func (suite *ProductServiceTestSuite) TestExpiredReservationsAreReleased() {
+	product := suite.createStockedProduct(5)
+
+	first, err := suite.service.ReserveStock(context.Background(), &model.ReserveStockRequest{ProductID: product.ID, Quantity: 2, TTLSeconds: 60})
+	assert.NoError(suite.T(), err)
+	_, err = suite.service.ReserveStock(context.Background(), &model.ReserveStockRequest{ProductID: product.ID, Quantity: 1, TTLSeconds: 3600})
+	assert.NoError(suite.T(), err)
+	held, err := suite.service.GetProduct(context.Background(), product.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int32(2), held.Quantity)
+
+	assert.Equal(suite.T(), 1, suite.service.expireReservations(time.Now().Add(2*time.Minute)))
+	assert.Equal(suite.T(), int32(4), suite.quantity(product.ID))
+	assert.Equal(suite.T(), int32(2), held.Quantity, "the reaper does not change products already returned")
+
+	_, err = suite.service.CommitReservation(context.Background(), first.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+}
+
+func (suite *ProductServiceTestSuite) TestConcurrentReservationsNeverOversell() {
+	product := suite.createStockedProduct(50)
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := suite.service.ReserveStock(context.Background(), &model.ReserveStockRequest{ProductID: product.ID, Quantity: 1})
+			if err == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(suite.T(), int32(50), succeeded.Load())
+	got, err := suite.service.GetProduct(context.Background(), product.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(0), got.Quantity)
+}
+
 func TestProductServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(ProductServiceTestSuite))
 }
\ No newline at end of file
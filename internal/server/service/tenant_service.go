@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
+)
+
+// tenantMembers is the view of a service with tenant-scoped records the
+// tenant service needs to refuse deleting a tenant that is still in use.
+type tenantMembers interface {
+	tenantInUse(tenantID string) bool
+}
+
+// TenantService manages the tenants users, products and orders are scoped to. The
+// default tenant always exists, so requests that name no tenant keep working.
+type TenantService struct {
+	tenants map[string]*model.Tenant
+	mu      sync.RWMutex
+	members []tenantMembers
+}
+
+func NewTenantService(userService *UserService, productService *ProductService, orderService *OrderService) *TenantService {
+	s := &TenantService{
+		tenants: map[string]*model.Tenant{
+			tenant.DefaultID: {ID: tenant.DefaultID, DisplayName: "Default", CreatedAt: time.Now()},
+		},
+		members: []tenantMembers{userService, productService, productService.categories, orderService},
+	}
+	userService.tenants = s
+	productService.tenants = s
+	productService.categories.tenants = s
+	orderService.tenants = s
+	return s
+}
+
+func (s *TenantService) CreateTenant(ctx context.Context, req *model.CreateTenantRequest) (*model.Tenant, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tenants[req.ID]; exists {
+		return nil, errors.NewAlreadyExistsError("tenant", "id", req.ID)
+	}
+	t := &model.Tenant{
+		ID:          req.ID,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		CreatedAt:   time.Now(),
+	}
+	s.tenants[t.ID] = t
+	return t, nil
+}
+
+func (s *TenantService) GetTenant(ctx context.Context, id string) (*model.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, exists := s.tenants[id]
+	if !exists {
+		return nil, errors.NewNotFoundError("tenant", id)
+	}
+	return t, nil
+}
+
+// ListTenants returns all tenants ordered by ID
+func (s *TenantService) ListTenants(ctx context.Context) ([]model.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenants := make([]model.Tenant, 0, len(s.tenants))
+	for _, t := range s.tenants {
+		tenants = append(tenants, *t)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants, nil
+}
+
+// DeleteTenant deletes a tenant that has no users, products, categories or
+// orders. The default tenant cannot be deleted.
+func (s *TenantService) DeleteTenant(ctx context.Context, id string) error {
+	if id == tenant.DefaultID {
+		return errors.NewFailedPreconditionError("the default tenant cannot be deleted")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tenants[id]; !exists {
+		return errors.NewNotFoundError("tenant", id)
+	}
+	for _, members := range s.members {
+		if members.tenantInUse(id) {
+			return errors.NewFailedPreconditionError(fmt.Sprintf("tenant %s still has users, products, categories or orders", id))
+		}
+	}
+
+	delete(s.tenants, id)
+	return nil
+}
+
+// hold keeps a tenant from being deleted until the returned function is
+// called, so that records can be inserted into it, and fails when the tenant
+// no longer exists. DeleteTenant checks the members while holding the tenant
+// lock, so hold must be taken before the locks of the member services. A nil
+// service holds nothing.
+func (s *TenantService) hold(id string) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	s.mu.RLock()
+	if _, exists := s.tenants[id]; !exists {
+		s.mu.RUnlock()
+		return nil, errors.NewNotFoundError("tenant", id)
+	}
+	return s.mu.RUnlock, nil
+}
+
+// Resolve returns the tenant a request acts for from the tenant it names and
+// the tenant of the access token it presents, either of which may be empty.
+// A token only works for its own tenant; with neither, the request acts for
+// the default tenant.
+func (s *TenantService) Resolve(requested, tokenTenant string) (string, error) {
+	id := requested
+	switch {
+	case id == "":
+		id = tokenTenant
+	case tokenTenant != "" && tokenTenant != id:
+		return "", errors.NewForbiddenError(fmt.Sprintf("access token is not valid for tenant %s", id))
+	}
+	if id == "" {
+		return tenant.DefaultID, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.tenants[id]; !exists {
+		return "", errors.NewNotFoundError("tenant", id)
+	}
+	return id, nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TenantServiceTestSuite struct {
+	suite.Suite
+	service        *TenantService
+	userService    *UserService
+	productService *ProductService
+	orderService   *OrderService
+	acme, globex   context.Context
+}
+
+func (suite *TenantServiceTestSuite) SetupTest() {
+	suite.userService = NewUserService()
+	suite.productService = NewProductService(NewCategoryService())
+	suite.orderService = NewOrderService(suite.userService, suite.productService)
+	suite.service = NewTenantService(suite.userService, suite.productService, suite.orderService)
+
+	for _, id := range []string{"acme", "globex"} {
+		_, err := suite.service.CreateTenant(context.Background(), &model.CreateTenantRequest{ID: id, DisplayName: id})
+		suite.Require().NoError(err)
+	}
+	suite.acme = tenant.NewContext(context.Background(), "acme")
+	suite.globex = tenant.NewContext(context.Background(), "globex")
+}
+
+func (suite *TenantServiceTestSuite) createUser(ctx context.Context, username string) *model.User {
+	user, err := suite.userService.CreateUser(ctx, &model.CreateUserRequest{
+		Username: username, Email: username + "@example.com", FullName: "Test User",
+	})
+	suite.Require().NoError(err)
+	return user
+}
+
+func (suite *TenantServiceTestSuite) createProduct(ctx context.Context, name string) *model.Product {
+	product, err := suite.productService.CreateProduct(ctx, &model.CreateProductRequest{
+		Name: name, Description: "A product", Price: 10, Quantity: 5, Category: "Tools",
+	})
+	suite.Require().NoError(err)
+	return product
+}
+
+func (suite *TenantServiceTestSuite) TestCreateAndListTenants() {
+	tenants, err := suite.service.ListTenants(context.Background())
+	suite.Require().NoError(err)
+	ids := make([]string, len(tenants))
+	for i, t := range tenants {
+		ids[i] = t.ID
+	}
+	assert.Equal(suite.T(), []string{"acme", "default", "globex"}, ids)
+
+	_, err = suite.service.CreateTenant(context.Background(), &model.CreateTenantRequest{ID: "acme", DisplayName: "Acme"})
+	assert.Equal(suite.T(), errors.ErrCodeAlreadyExists, errors.AsAppError(err).Code)
+
+	_, err = suite.service.CreateTenant(context.Background(), &model.CreateTenantRequest{ID: "Bad_ID", DisplayName: " "})
+	assert.Equal(suite.T(), []errors.FieldViolation{
+		{Field: "id", Description: "id must be 1 to 63 lower case letters, digits and inner hyphens"},
+		{Field: "display_name", Description: "display_name cannot be empty"},
+	}, errors.AsAppError(err).Violations)
+}
+
+func (suite *TenantServiceTestSuite) TestDeleteTenant() {
+	err := suite.service.DeleteTenant(context.Background(), tenant.DefaultID)
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+
+	suite.createProduct(suite.acme, "Hammer")
+	err = suite.service.DeleteTenant(context.Background(), "acme")
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+
+	suite.Require().NoError(suite.service.DeleteTenant(context.Background(), "globex"))
+	_, err = suite.service.GetTenant(context.Background(), "globex")
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+
+	// Requests resolved before the deletion cannot insert into it afterwards
+	_, err = suite.userService.CreateUser(suite.globex, &model.CreateUserRequest{Username: "bob", Email: "bob@example.com", FullName: "Bob"})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+	_, err = suite.productService.CreateProduct(suite.globex, &model.CreateProductRequest{Name: "Anvil", Description: "Heavy", Price: 10, Quantity: 1, Category: "Tools"})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+	_, err = suite.productService.categories.CreateCategory(suite.globex, &model.CreateCategoryRequest{Name: "Tools"})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+}
+
+func (suite *TenantServiceTestSuite) TestDeleteTenantWithOrders() {
+	user := suite.createUser(suite.acme, "alice")
+	product := suite.createProduct(suite.acme, "Hammer")
+	_, err := suite.orderService.CreateOrder(suite.acme, &model.CreateOrderRequest{
+		UserID: user.ID, Items: []model.CreateOrderItem{{ProductID: product.ID, Quantity: 1}},
+	})
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.userService.DeleteUser(suite.acme, user.ID))
+	suite.Require().NoError(suite.productService.DeleteProduct(suite.acme, product.ID))
+
+	// The orders outlive their user and products, and keep the tenant in use
+	err = suite.service.DeleteTenant(context.Background(), "acme")
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+	assert.True(suite.T(), suite.orderService.tenantInUse("acme"))
+
+	// Orders cannot be placed in a deleted tenant
+	suite.Require().NoError(suite.service.DeleteTenant(context.Background(), "globex"))
+	_, err = suite.orderService.CreateOrder(suite.globex, &model.CreateOrderRequest{
+		UserID: user.ID, Items: []model.CreateOrderItem{{ProductID: product.ID, Quantity: 1}},
+	})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+	assert.Contains(suite.T(), err.Error(), "tenant")
+}
+
+func (suite *TenantServiceTestSuite) TestResolve() {
+	for _, tc := range []struct {
+		requested, tokenTenant string
+		want                   string
+		code                   errors.ErrorCode
+	}{
+		{"", "", tenant.DefaultID, ""},
+		{"acme", "", "acme", ""},
+		{"", "globex", "globex", ""},
+		{"acme", "acme", "acme", ""},
+		{"acme", "globex", "", errors.ErrCodeForbidden},
+		{"initech", "", "", errors.ErrCodeNotFound},
+	} {
+		id, err := suite.service.Resolve(tc.requested, tc.tokenTenant)
+		if tc.code != "" {
+			assert.Equal(suite.T(), tc.code, errors.AsAppError(err).Code, "%+v", tc)
+			continue
+		}
+		suite.Require().NoError(err)
+		assert.Equal(suite.T(), tc.want, id)
+	}
+}
+
+func (suite *TenantServiceTestSuite) TestUsersAreIsolated() {
+	alice := suite.createUser(suite.acme, "alice")
+	// Usernames and emails are unique per tenant only
+	other := suite.createUser(suite.globex, "alice")
+	assert.NotEqual(suite.T(), alice.ID, other.ID)
+	assert.Equal(suite.T(), "acme", alice.TenantID)
+
+	_, err := suite.userService.CreateUser(suite.acme, &model.CreateUserRequest{
+		Username: "ALICE", Email: "new@example.com", FullName: "Test User",
+	})
+	assert.Equal(suite.T(), errors.ErrCodeAlreadyExists, errors.AsAppError(err).Code)
+
+	_, err = suite.userService.GetUser(suite.globex, alice.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+	_, err = suite.userService.GetUser(context.Background(), alice.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+
+	name := "Mallory"
+	_, err = suite.userService.UpdateUser(suite.globex, &model.UpdateUserRequest{ID: alice.ID, FullName: &name})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+	err = suite.userService.DeleteUser(suite.globex, alice.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+
+	users, total, _, _, err := suite.userService.ListUsers(suite.acme, &model.ListUsersRequest{})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int32(1), total)
+	assert.Equal(suite.T(), alice.ID, users[0].ID)
+
+	results, err := suite.userService.BatchGetUsers(suite.acme, &model.BatchGetUsersRequest{IDs: []string{alice.ID, other.ID}})
+	suite.Require().NoError(err)
+	assert.NotNil(suite.T(), results[0].User)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, results[1].Error.Code)
+}
+
+func (suite *TenantServiceTestSuite) TestProductsAreIsolated() {
+	hammer := suite.createProduct(suite.acme, "Hammer")
+	suite.createProduct(suite.globex, "Hammer")
+
+	products, total, _, _, err := suite.productService.SearchProducts(suite.acme, &model.SearchProductsRequest{})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int32(1), total)
+	assert.Equal(suite.T(), hammer.ID, products[0].ID)
+
+	_, err = suite.productService.GetProduct(suite.globex, hammer.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+
+	_, err = suite.productService.AdjustStock(suite.globex, &model.AdjustStockRequest{ProductID: hammer.ID, Delta: -1, Reason: "theft"})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+
+	reservation, err := suite.productService.ReserveStock(suite.acme, &model.ReserveStockRequest{ProductID: hammer.ID, Quantity: 1})
+	suite.Require().NoError(err)
+	_, err = suite.productService.ReleaseReservation(suite.globex, reservation.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+
+	// Orders of one tenant cannot take stock of another's products
+	buyer := suite.createUser(suite.globex, "bob")
+	orders := NewOrderService(suite.userService, suite.productService)
+	_, err = orders.CreateOrder(suite.globex, &model.CreateOrderRequest{
+		UserID: buyer.ID,
+		Items:  []model.CreateOrderItem{{ProductID: hammer.ID, Quantity: 1}},
+	})
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+}
+
+func (suite *TenantServiceTestSuite) TestCategoriesAreIsolated() {
+	categories := suite.productService.categories
+	tools, err := categories.CreateCategory(suite.acme, &model.CreateCategoryRequest{Name: "Tools"})
+	suite.Require().NoError(err)
+	_, err = categories.CreateCategory(suite.globex, &model.CreateCategoryRequest{Name: "Tools"})
+	suite.Require().NoError(err, "slugs are unique per tenant")
+
+	_, err = categories.GetCategory(suite.globex, tools.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, errors.AsAppError(err).Code)
+	listed, err := categories.ListCategories(suite.globex, &model.ListCategoriesRequest{})
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), listed, 1)
+	assert.NotEqual(suite.T(), tools.ID, listed[0].ID)
+
+	_, err = suite.productService.CreateProduct(suite.globex, &model.CreateProductRequest{
+		Name: "Saw", Description: "Sharp", Price: 10, Quantity: 1, CategoryID: tools.ID,
+	})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+
+	// Renaming and migrating only touch the tenant's own products
+	suite.createProduct(suite.acme, "Hammer")
+	globexHammer := suite.createProduct(suite.globex, "Hammer")
+	summary, err := suite.productService.MigrateCategories(suite.acme)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int32(1), summary.ProductsUpdated)
+	assert.Equal(suite.T(), int32(0), summary.CategoriesCreated)
+
+	name := "Hand Tools"
+	_, err = categories.UpdateCategory(suite.acme, &model.UpdateCategoryRequest{ID: tools.ID, Name: &name})
+	suite.Require().NoError(err)
+	product, err := suite.productService.GetProduct(suite.globex, globexHammer.ID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), product.CategoryID)
+	assert.Equal(suite.T(), "Tools", product.Category)
+
+	err = categories.DeleteCategory(suite.globex, listed[0].ID)
+	assert.NoError(suite.T(), err, "products of other tenants do not hold a category")
+}
+
+func (suite *TenantServiceTestSuite) TestLoginIsScopedToTenant() {
+	suite.userService.ConfigurePasswords(PasswordConfig{
+		Hasher: &auth.Hasher{Time: 1, MemoryKiB: 64, Threads: 1, KeyLength: 32, SaltLength: 16},
+	})
+	signer := auth.NewTokenSigner([]byte("secret"), 15*time.Minute)
+	authService := NewAuthService(suite.userService, signer, time.Hour)
+
+	alice := suite.createUser(suite.acme, "alice")
+	suite.Require().NoError(suite.userService.SetPassword(suite.acme, &model.SetPasswordRequest{
+		ID: alice.ID, Password: testPassword, SetupToken: alice.PasswordSetupToken,
+	}))
+
+	_, err := authService.Login(suite.globex, &model.LoginRequest{Username: "alice", Password: testPassword})
+	assert.Equal(suite.T(), errors.ErrCodeUnauthorized, errors.AsAppError(err).Code)
+
+	tokens, err := authService.Login(suite.acme, &model.LoginRequest{Username: "alice", Password: testPassword})
+	suite.Require().NoError(err)
+	claims, err := authService.VerifyAccessToken(tokens.AccessToken)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "acme", claims.Tenant)
+}
+
+func TestTenantServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantServiceTestSuite))
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/auth"
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/identity"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
+)
+
+// PasswordConfig configures user passwords and login lockout. Zero fields
+// take their defaults.
+type PasswordConfig struct {
+	Policy *auth.PasswordPolicy
+	Hasher *auth.Hasher
+	// MaxFailedAttempts wrong passwords in a row lock the account for
+	// LockoutDuration
+	MaxFailedAttempts int
+	LockoutDuration   time.Duration
+}
+
+// DefaultPasswordConfig uses the default policy and hasher and locks an
+// account for 15 minutes after 5 wrong passwords in a row
+func DefaultPasswordConfig() PasswordConfig {
+	var config PasswordConfig
+	config.setDefaults()
+	return config
+}
+
+func (c *PasswordConfig) setDefaults() {
+	if c.Policy == nil {
+		c.Policy = auth.DefaultPasswordPolicy()
+	}
+	if c.Hasher == nil {
+		c.Hasher = auth.DefaultHasher()
+	}
+	if c.MaxFailedAttempts <= 0 {
+		c.MaxFailedAttempts = 5
+	}
+	if c.LockoutDuration <= 0 {
+		c.LockoutDuration = 15 * time.Minute
+	}
+}
+
+// credential is the password of a user and its lockout state. It is kept
+// apart from model.User so that the hash never leaves the service.
+type credential struct {
+	hash           string
+	changedAt      time.Time
+	failedAttempts int
+	lockedUntil    time.Time
+}
+
+// ConfigurePasswords replaces the password policy, hasher and lockout
+// settings. It must be called before the service handles requests.
+func (s *UserService) ConfigurePasswords(config PasswordConfig) {
+	config.setDefaults()
+	s.passwords = config
+}
+
+// SetPassword sets a user's password without asking for the current one.
+// Users signed in with an access token may replace their own password;
+// without a token it only sets the first password of a user, and only with
+// the setup token returned when the user was created, so that the password
+// of an account cannot be taken over. Outstanding refresh tokens stop
+// working.
+func (s *UserService) SetPassword(ctx context.Context, req *model.SetPasswordRequest) error {
+	if err := validation.Validate(req); err != nil {
+		return err
+	}
+
+	claims, signedIn := auth.FromContext(ctx)
+	if signedIn && claims.Subject != req.ID {
+		return errors.NewForbiddenError("users can only set their own password")
+	}
+	return s.storePassword(tenant.FromContext(ctx), req.ID, "password", req.Password, req.SetupToken, signedIn)
+}
+
+// ChangePassword replaces a user's password after checking the current one.
+// A wrong current password counts towards the lockout like a failed login.
+func (s *UserService) ChangePassword(ctx context.Context, req *model.ChangePasswordRequest) error {
+	if err := validation.Validate(req); err != nil {
+		return err
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	s.mu.RLock()
+	user, err := s.getUserLocked(tenantID, req.ID)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if _, err := s.checkPassword(user, req.CurrentPassword); err != nil {
+		return err
+	}
+	return s.storePassword(tenantID, req.ID, "new_password", req.NewPassword, "", true)
+}
+
+// storePassword checks a new password against the policy and stores its
+// hash, replacing the current one only when replace is set. Without replace
+// it needs the user's setup token, which it uses up. Hashing is slow by
+// design, so it happens outside s.mu.
+func (s *UserService) storePassword(tenantID, id, field, password, setupToken string, replace bool) error {
+	s.mu.RLock()
+	user, err := s.getUserLocked(tenantID, id)
+	var username, email string
+	if err == nil {
+		username, email = user.Username, user.Email
+		err = s.checkStoreLocked(id, setupToken, replace)
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if problem := s.passwords.Policy.Check(password, username, email); problem != "" {
+		return errors.NewValidationError(field, strings.ReplaceAll(field, "_", " ")+" "+problem)
+	}
+
+	hash := s.passwords.Hasher.Hash(password)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.getUserLocked(tenantID, id); err != nil {
+		return err
+	}
+	if err := s.checkStoreLocked(id, setupToken, replace); err != nil {
+		return err
+	}
+	s.credentials[id] = &credential{hash: hash, changedAt: time.Now()}
+	delete(s.setupTokens, id)
+	return nil
+}
+
+// checkStoreLocked refuses to replace the password a user already has unless
+// replace is set, and to set the first one without the user's setup token
+func (s *UserService) checkStoreLocked(id, setupToken string, replace bool) error {
+	if replace {
+		return nil
+	}
+	if _, exists := s.credentials[id]; exists {
+		return errors.NewUnauthorizedError("user already has a password; sign in as the user to replace it")
+	}
+	want, exists := s.setupTokens[id]
+	got := sha256.Sum256([]byte(setupToken))
+	if !exists || subtle.ConstantTimeCompare(want[:], got[:]) != 1 {
+		return errors.NewUnauthorizedError("invalid password setup token")
+	}
+	return nil
+}
+
+// withSetupTokenLocked issues a new user a setup token for its first
+// password. Only the hash is kept; the token is returned once, on a copy of
+// the user.
+func (s *UserService) withSetupTokenLocked(user *model.User) *model.User {
+	token := rand.Text()
+	s.setupTokens[user.ID] = sha256.Sum256([]byte(token))
+	issued := *user
+	issued.PasswordSetupToken = token
+	return &issued
+}
+
+// authenticate returns the user of a tenant a login names by username or
+// email when the password is theirs. Unknown users, users without a password
+// and wrong passwords fail alike; locked and inactive accounts are refused.
+func (s *UserService) authenticate(tenantID, login, password string) (*model.User, error) {
+	key := identity.Key(strings.TrimSpace(login))
+	s.mu.RLock()
+	var user *model.User
+	for _, u := range s.users {
+		if u.TenantID == tenantID && (identity.Key(u.Username) == key || identity.Key(u.Email) == key) {
+			user = u
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if user == nil {
+		// Spend the time a real check takes, so timing does not reveal users
+		s.passwords.Hasher.Hash(password)
+		return nil, errInvalidCredentials
+	}
+
+	changedAt, err := s.checkPassword(user, password)
+	if err != nil {
+		return nil, err
+	}
+	return s.sessionUser(user.ID, changedAt)
+}
+
+var errInvalidCredentials = errors.NewUnauthorizedError("invalid username or password")
+
+// checkPassword verifies a user's password, counting failures towards the
+// lockout, and returns when the password was last changed
+func (s *UserService) checkPassword(user *model.User, password string) (time.Time, error) {
+	now := time.Now()
+	s.mu.RLock()
+	cred, exists := s.credentials[user.ID]
+	var hash string
+	var lockedUntil time.Time
+	if exists {
+		hash, lockedUntil = cred.hash, cred.lockedUntil
+	}
+	s.mu.RUnlock()
+
+	if !exists {
+		s.passwords.Hasher.Hash(password)
+		return time.Time{}, errInvalidCredentials
+	}
+	if now.Before(lockedUntil) {
+		return time.Time{}, errors.NewForbiddenError(
+			fmt.Sprintf("account is locked after too many failed attempts; retry in %s", lockedUntil.Sub(now).Round(time.Second)))
+	}
+
+	ok, err := auth.VerifyPassword(hash, password)
+	if err != nil {
+		log.Printf("Failed to verify password of user %s: %v", user.ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cred, exists = s.credentials[user.ID]
+	if !exists || cred.hash != hash {
+		// The password changed while this one was being checked
+		return time.Time{}, errInvalidCredentials
+	}
+	if !ok {
+		cred.failedAttempts++
+		if cred.failedAttempts >= s.passwords.MaxFailedAttempts {
+			cred.failedAttempts = 0
+			cred.lockedUntil = now.Add(s.passwords.LockoutDuration)
+		}
+		return time.Time{}, errInvalidCredentials
+	}
+	cred.failedAttempts = 0
+	return cred.changedAt, nil
+}
+
+// sessionUser returns the user a session issued at issuedAt belongs to,
+// unless the user was deleted or deactivated, or changed their password
+// since.
+func (s *UserService) sessionUser(id string, issuedAt time.Time) (*model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, exists := s.users[id]
+	cred, hasPassword := s.credentials[id]
+	if !exists || !hasPassword || cred.changedAt.After(issuedAt) {
+		return nil, errors.NewUnauthorizedError("session has been revoked; log in again")
+	}
+	if !user.IsActive {
+		return nil, errors.NewForbiddenError(fmt.Sprintf("user %s is not active", user.ID))
+	}
+	return user, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/identity"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/tenant"
+)
+
+// normalizeIdentity normalizes the username and email of a create or update
+// in place, so that validation sees the values that are stored. Nil fields
+// are left alone.
+func normalizeIdentity(username, email *string) {
+	if username != nil {
+		*username = identity.NormalizeUsername(*username)
+	}
+	if email != nil {
+		*email = identity.NormalizeEmail(*email)
+	}
+}
+
+// checkPolicy rejects the normalized username and email of a create or
+// update unless the identity policy allows them. Nil fields are left alone.
+func (s *UserService) checkPolicy(username, email *string) error {
+	var violations []errors.FieldViolation
+	if username != nil {
+		if problem := s.policy.CheckUsername(*username); problem != "" {
+			violations = append(violations, errors.FieldViolation{Field: "username", Description: "username " + problem})
+		}
+	}
+	if email != nil {
+		if problem := s.policy.CheckEmail(*email); problem != "" {
+			violations = append(violations, errors.FieldViolation{Field: "email", Description: "email " + problem})
+		}
+	}
+	if len(violations) > 0 {
+		return errors.NewViolationsError(violations)
+	}
+	return nil
+}
+
+// CheckUserPolicy reports the tenant's users whose username or email breaks
+// the identity policy, such as records created before it changed. Values
+// that only need normalizing are rewritten when repair is set; the others,
+// including identities that collide once case-folded, need manual attention.
+// Users are checked in ID order, so the older of two colliding users keeps
+// its identity.
+func (s *UserService) CheckUserPolicy(ctx context.Context, repair bool) (*model.UserPolicyReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	var users []*model.User
+	for _, user := range s.users {
+		if user.TenantID == tenantID {
+			users = append(users, user)
+		}
+	}
+	slices.SortFunc(users, func(a, b *model.User) int { return compareIDs(a.ID, b.ID) })
+
+	report := &model.UserPolicyReport{Checked: int32(len(users))}
+	owners := map[string]map[string]string{"username": {}, "email": {}}
+	var repaired []*model.User
+	for _, user := range users {
+		fields := []struct {
+			name      string
+			value     *string
+			normalize func(string) string
+			check     func(string) string
+		}{
+			{"username", &user.Username, identity.NormalizeUsername, s.policy.CheckUsername},
+			{"email", &user.Email, identity.NormalizeEmail, s.policy.CheckEmail},
+		}
+
+		changed := false
+		for _, field := range fields {
+			normalized := field.normalize(*field.value)
+			key := identity.Key(normalized)
+			problem := field.check(normalized)
+			if owner, taken := owners[field.name][key]; taken && problem == "" {
+				problem = fmt.Sprintf("conflicts with user %s after case folding", owner)
+			} else if !taken {
+				owners[field.name][key] = user.ID
+			}
+
+			violation := model.UserPolicyViolation{UserID: user.ID, Field: field.name, Value: *field.value}
+			switch {
+			case problem != "":
+				violation.Description = field.name + " " + problem
+			case normalized != *field.value:
+				violation.Description = field.name + " is not normalized"
+				violation.NormalizedValue = normalized
+				if repair {
+					*field.value = normalized
+					violation.Repaired = true
+					changed = true
+				}
+			default:
+				continue
+			}
+			report.Violations = append(report.Violations, violation)
+		}
+		if changed {
+			user.UpdatedAt = time.Now()
+			repaired = append(repaired, user)
+		}
+	}
+
+	report.Repaired = int32(len(repaired))
+	for _, user := range repaired {
+		s.publish(model.EventUpdated, user)
+	}
+	return report, nil
+}
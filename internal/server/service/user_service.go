@@ -2,28 +2,56 @@ package service
 
 import (
 	"context"
-	"sort"
 	"strconv"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/filter"
+	"go-grpc-rest-demo/internal/server/identity"
 	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/notify"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
 )
 
 type UserService struct {
 	users  map[string]*model.User
 	nextID int64
 	mu     sync.RWMutex
+	events *eventBroker[model.UserEvent]
+	policy *identity.Policy
+	// verification is nil unless email verification is enabled
+	verification *EmailVerification
+	pending      map[string]*pendingVerification
+	passwords    PasswordConfig
+	credentials  map[string]*credential
+	// setupTokens holds the hash of each user's unused password setup token
+	setupTokens map[string][32]byte
+	// tenants is nil unless a tenant service manages the tenants users are
+	// created in
+	tenants *TenantService
 }
 
 func NewUserService() *UserService {
-	return &UserService{
-		users:  make(map[string]*model.User),
-		nextID: 1,
+	return NewUserServiceWithPolicy(identity.DefaultPolicy())
+}
+
+// NewUserServiceWithPolicy creates a user service that enforces policy on
+// usernames and emails when users are created or updated.
+func NewUserServiceWithPolicy(policy *identity.Policy) *UserService {
+	s := &UserService{
+		users:       make(map[string]*model.User),
+		nextID:      1,
+		events:      newEventBroker[model.UserEvent](),
+		policy:      policy,
+		pending:     make(map[string]*pendingVerification),
+		credentials: make(map[string]*credential),
+		setupTokens: make(map[string][32]byte),
 	}
+	s.passwords.setDefaults()
+	return s
 }
 
 func (s *UserService) generateID() string {
@@ -31,18 +59,50 @@ func (s *UserService) generateID() string {
 }
 
 func (s *UserService) CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.User, error) {
-	if req.Username == "" || req.Email == "" || req.FullName == "" {
-		return nil, errors.NewValidationError("fields", "username, email, and full_name are required")
+	normalizeIdentity(&req.Username, &req.Email)
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkPolicy(&req.Username, &req.Email); err != nil {
+		return nil, err
 	}
 
+	tenantID := tenant.FromContext(ctx)
+	release, err := s.tenants.hold(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outbox []notify.Message
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer func() {
+		s.mu.Unlock()
+		release()
+		s.deliver(ctx, outbox)
+	}()
 
+	user, err := s.insertUserLocked(tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(model.EventCreated, user)
+	s.issueVerificationLocked(&outbox, user, true)
+	return s.withSetupTokenLocked(user), nil
+}
+
+// insertUserLocked enforces uniqueness within the tenant and stores a new
+// user in it. s.mu must be held.
+func (s *UserService) insertUserLocked(tenantID string, req *model.CreateUserRequest) (*model.User, error) {
+	usernameKey, emailKey := identity.Key(req.Username), identity.Key(req.Email)
 	for _, user := range s.users {
-		if user.Username == req.Username {
+		if user.TenantID != tenantID {
+			continue
+		}
+		if identity.Key(user.Username) == usernameKey {
 			return nil, errors.NewAlreadyExistsError("user", "username", req.Username)
 		}
-		if user.Email == req.Email {
+		if identity.Key(user.Email) == emailKey {
 			return nil, errors.NewAlreadyExistsError("user", "email", req.Email)
 		}
 	}
@@ -50,10 +110,11 @@ func (s *UserService) CreateUser(ctx context.Context, req *model.CreateUserReque
 	now := time.Now()
 	user := &model.User{
 		ID:        s.generateID(),
+		TenantID:  tenantID,
 		Username:  req.Username,
 		Email:     req.Email,
 		FullName:  req.FullName,
-		IsActive:  true,
+		IsActive:  s.verification == nil,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -62,38 +123,166 @@ func (s *UserService) CreateUser(ctx context.Context, req *model.CreateUserReque
 	return user, nil
 }
 
+// BatchCreateUsers creates users under a single lock with the same rules as
+// CreateUser. In atomic mode the first failing item aborts the whole batch;
+// otherwise each item reports its own outcome.
+func (s *UserService) BatchCreateUsers(ctx context.Context, req *model.BatchCreateUsersRequest) ([]model.BatchUserResult, error) {
+	if err := validateBatchSize("requests", len(req.Requests)); err != nil {
+		return nil, err
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	release, err := s.tenants.hold(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outbox []notify.Message
+	s.mu.Lock()
+	defer func() {
+		s.mu.Unlock()
+		release()
+		s.deliver(ctx, outbox)
+	}()
+
+	results := make([]model.BatchUserResult, len(req.Requests))
+	var created []*model.User
+	for i := range req.Requests {
+		user, err := s.createBatchItemLocked(tenantID, &req.Requests[i])
+		if err != nil {
+			if req.Atomic {
+				for _, u := range created {
+					delete(s.users, u.ID)
+					delete(s.setupTokens, u.ID)
+				}
+				return nil, batchItemError("requests", i, err)
+			}
+			results[i].Error = errors.AsAppError(err)
+			continue
+		}
+		created = append(created, user)
+		results[i].User = s.withSetupTokenLocked(user)
+	}
+
+	for _, user := range created {
+		s.publish(model.EventCreated, user)
+		s.issueVerificationLocked(&outbox, user, true)
+	}
+	return results, nil
+}
+
+func (s *UserService) createBatchItemLocked(tenantID string, req *model.CreateUserRequest) (*model.User, error) {
+	normalizeIdentity(&req.Username, &req.Email)
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkPolicy(&req.Username, &req.Email); err != nil {
+		return nil, err
+	}
+	return s.insertUserLocked(tenantID, req)
+}
+
+// BatchGetUsers retrieves users in request order. In atomic mode a missing
+// user fails the whole call.
+func (s *UserService) BatchGetUsers(ctx context.Context, req *model.BatchGetUsersRequest) ([]model.BatchUserResult, error) {
+	if err := validateBatchSize("ids", len(req.IDs)); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+	results := make([]model.BatchUserResult, len(req.IDs))
+	for i, id := range req.IDs {
+		user, err := s.getUserLocked(tenantID, id)
+		if err != nil {
+			if req.Atomic {
+				return nil, batchItemError("ids", i, err)
+			}
+			results[i].Error = errors.AsAppError(err)
+			continue
+		}
+		results[i].User = user
+	}
+	return results, nil
+}
+
 func (s *UserService) GetUser(ctx context.Context, id string) (*model.User, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	return s.getUserLocked(tenant.FromContext(ctx), id)
+}
+
+// getUserLocked returns the user with id in a tenant. Users of other tenants
+// are reported missing, so that their IDs reveal nothing. s.mu must be held.
+func (s *UserService) getUserLocked(tenantID, id string) (*model.User, error) {
 	user, exists := s.users[id]
-	if !exists {
+	if !exists || user.TenantID != tenantID {
 		return nil, errors.NewNotFoundError("user", id)
 	}
 	return user, nil
 }
 
+// tenantInUse reports whether a tenant has users
+func (s *UserService) tenantInUse(tenantID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.TenantID == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *UserService) UpdateUser(ctx context.Context, req *model.UpdateUserRequest) (*model.User, error) {
+	normalizeIdentity(req.Username, req.Email)
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkPolicy(req.Username, req.Email); err != nil {
+		return nil, err
+	}
+
+	var outbox []notify.Message
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer func() {
+		s.mu.Unlock()
+		s.deliver(ctx, outbox)
+	}()
 
-	user, exists := s.users[req.ID]
-	if !exists {
-		return nil, errors.NewNotFoundError("user", req.ID)
+	user, err := s.getUserLocked(tenant.FromContext(ctx), req.ID)
+	if err != nil {
+		return nil, err
 	}
 
+	// Check every field before changing any, so a rejected update leaves the
+	// user as it was
 	if req.Username != nil {
-		if err := s.checkUniqueField(req.ID, "username", *req.Username); err != nil {
+		if err := s.checkUniqueField(user, "username", *req.Username); err != nil {
 			return nil, err
 		}
-		user.Username = *req.Username
 	}
-
 	if req.Email != nil {
-		if err := s.checkUniqueField(req.ID, "email", *req.Email); err != nil {
+		if err := s.checkUniqueField(user, "email", *req.Email); err != nil {
 			return nil, err
 		}
-		user.Email = *req.Email
+	}
+
+	if req.Username != nil {
+		user.Username = *req.Username
+	}
+	if req.Email != nil {
+		if *req.Email != user.Email {
+			// A new address is unverified; a pending sign-up stays pending
+			user.Email = *req.Email
+			user.EmailVerified = false
+			pending, exists := s.pending[user.ID]
+			s.issueVerificationLocked(&outbox, user, exists && pending.activate)
+		}
 	}
 
 	if req.FullName != nil {
@@ -103,13 +292,17 @@ func (s *UserService) UpdateUser(ctx context.Context, req *model.UpdateUserReque
 		user.IsActive = *req.IsActive
 	}
 	user.UpdatedAt = time.Now()
+	s.publish(model.EventUpdated, user)
 
 	return user, nil
 }
 
-func (s *UserService) checkUniqueField(excludeID, field, value string) error {
+// checkUniqueField rejects a username or email another user of the same
+// tenant already has. s.mu must be held.
+func (s *UserService) checkUniqueField(user *model.User, field, value string) error {
+	key := identity.Key(value)
 	for id, u := range s.users {
-		if id == excludeID {
+		if id == user.ID || u.TenantID != user.TenantID {
 			continue
 		}
 		var existing string
@@ -118,7 +311,7 @@ func (s *UserService) checkUniqueField(excludeID, field, value string) error {
 		} else {
 			existing = u.Email
 		}
-		if existing == value {
+		if identity.Key(existing) == key {
 			return errors.NewAlreadyExistsError("user", field, value)
 		}
 	}
@@ -129,34 +322,48 @@ func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.users[id]; !exists {
-		return errors.NewNotFoundError("user", id)
+	user, err := s.getUserLocked(tenant.FromContext(ctx), id)
+	if err != nil {
+		return err
 	}
 
 	delete(s.users, id)
+	delete(s.pending, id)
+	delete(s.credentials, id)
+	delete(s.setupTokens, id)
+	s.publish(model.EventDeleted, user)
 	return nil
 }
 
 func (s *UserService) ListUsers(ctx context.Context, req *model.ListUsersRequest) ([]model.User, int32, int32, int32, error) {
+	if err := req.TimeRange.Validate(); err != nil {
+		return nil, 0, 0, 0, err
+	}
+	terms, err := userOrderTerms(req.OrderBy, req.SortBy)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	match, err := parseUserFilter(req.Filter)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	users := s.filterUsers(req.Filter)
-	s.sortUsers(users, req.SortBy)
+	users := s.filterUsers(tenant.FromContext(ctx), match, req.TimeRange)
+	sortByTerms(users, terms)
 
 	paged, total, page, pageSize := paginate(users, req.Page, req.PageSize)
 	return paged, total, page, pageSize, nil
 }
 
-func (s *UserService) filterUsers(filter *string) []model.User {
+// filterUsers returns copies of a tenant's users matching the filter and
+// time range
+func (s *UserService) filterUsers(tenantID string, match *filter.Filter[model.User], timeRange model.TimeRange) []model.User {
 	var users []model.User
-	var filterLower string
-	if filter != nil {
-		filterLower = strings.ToLower(*filter)
-	}
-
 	for _, user := range s.users {
-		if filterLower != "" && !s.matchesFilter(user, filterLower) {
+		if user.TenantID != tenantID || !match.Match(user) || !timeRange.Contains(user.CreatedAt, user.UpdatedAt) {
 			continue
 		}
 		users = append(users, *user)
@@ -164,30 +371,70 @@ func (s *UserService) filterUsers(filter *string) []model.User {
 	return users
 }
 
-func (s *UserService) matchesFilter(user *model.User, filter string) bool {
-	return strings.Contains(strings.ToLower(user.Username), filter) ||
-		strings.Contains(strings.ToLower(user.Email), filter) ||
-		strings.Contains(strings.ToLower(user.FullName), filter)
+// parseUserFilter parses an AIP-160 filter over user fields; nil and empty
+// filters match every user.
+func parseUserFilter(expr *string) (*filter.Filter[model.User], error) {
+	if expr == nil {
+		return nil, nil
+	}
+	return userFilterSchema.Parse("filter", *expr)
 }
 
-func (s *UserService) sortUsers(users []model.User, sortBy *string) {
-	field := "id"
-	if sortBy != nil && *sortBy != "" {
-		field = *sortBy
+// userOrderTerms parses order_by, falling back to the deprecated sort_by,
+// which names a single field.
+func userOrderTerms(orderBy string, sortBy *string) ([]orderTerm[model.User], error) {
+	if orderBy == "" && sortBy != nil {
+		return userOrderFields.parseOrderBy("sort_by", *sortBy)
 	}
+	return userOrderFields.parseOrderBy("order_by", orderBy)
+}
 
-	sort.Slice(users, func(i, j int) bool {
-		switch field {
-		case "username":
-			return users[i].Username < users[j].Username
-		case "email":
-			return users[i].Email < users[j].Email
-		case "full_name":
-			return users[i].FullName < users[j].FullName
-		case "created_at":
-			return users[i].CreatedAt.Before(users[j].CreatedAt)
-		default:
-			return users[i].ID < users[j].ID
-		}
+// ExportUsers returns a point-in-time snapshot of the tenant's users matching
+// the filter and time range, ordered like ListUsers. Users are copied under
+// the read lock, so the snapshot is consistent and can be encoded without
+// holding it.
+func (s *UserService) ExportUsers(ctx context.Context, req *model.ExportUsersRequest) ([]model.User, error) {
+	if err := req.TimeRange.Validate(); err != nil {
+		return nil, err
+	}
+	terms, err := userOrderTerms(req.OrderBy, req.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	match, err := parseUserFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := s.filterUsers(tenant.FromContext(ctx), match, req.TimeRange)
+	sortByTerms(users, terms)
+	return users, nil
+}
+
+// WatchUsers streams change events of the tenant's users, optionally
+// filtered by active state. A non-zero SinceSequence replays retained events
+// published after it.
+func (s *UserService) WatchUsers(ctx context.Context, req *model.WatchUsersRequest) (*Watcher[model.UserEvent], error) {
+	tenantID := tenant.FromContext(ctx)
+	match := func(event model.UserEvent) bool {
+		return event.User.TenantID == tenantID && (req.IsActive == nil || event.User.IsActive == *req.IsActive)
+	}
+	return s.events.subscribe(ctx, req.SinceSequence, match)
+}
+
+// StopWatches ends every user watch with an unavailable error and refuses
+// new ones. Servers call it on shutdown, which open watches would otherwise
+// hold up.
+func (s *UserService) StopWatches() {
+	s.events.close()
+}
+
+// publish must be called with s.mu held so events are sequenced in write order
+func (s *UserService) publish(eventType model.EventType, user *model.User) {
+	s.events.publish(func(seq int64) model.UserEvent {
+		return model.UserEvent{Sequence: seq, Type: eventType, User: *user}
 	})
 }
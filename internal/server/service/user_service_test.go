@@ -3,10 +3,16 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"go-grpc-rest-demo/internal/server/errors"
 	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/notify"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/verification"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -135,6 +141,27 @@ func (suite *UserServiceTestSuite) TestUpdateUser() {
 	assert.True(suite.T(), updated.UpdatedAt.After(updated.CreatedAt))
 }
 
+func (suite *UserServiceTestSuite) TestRejectedUpdateChangesNothing() {
+	ctx := context.Background()
+	for _, name := range []string{"first", "second"} {
+		_, err := suite.service.CreateUser(ctx, &model.CreateUserRequest{Username: name, Email: name + "@example.com", FullName: name})
+		suite.Require().NoError(err)
+	}
+	users, _, _, _, err := suite.service.ListUsers(ctx, &model.ListUsersRequest{OrderBy: "username"})
+	suite.Require().NoError(err)
+	first := users[0]
+
+	// The username is free, but the email belongs to the other user
+	username, email := "renamed", "second@example.com"
+	_, err = suite.service.UpdateUser(ctx, &model.UpdateUserRequest{ID: first.ID, Username: &username, Email: &email})
+	assert.Equal(suite.T(), errors.ErrCodeAlreadyExists, errors.AsAppError(err).Code)
+
+	stored, err := suite.service.GetUser(ctx, first.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "first", stored.Username)
+	assert.Equal(suite.T(), first.UpdatedAt, stored.UpdatedAt)
+}
+
 func (suite *UserServiceTestSuite) TestDeleteUser() {
 	createReq := &model.CreateUserRequest{
 		Username: "deleteuser",
@@ -208,6 +235,465 @@ func (suite *UserServiceTestSuite) TestListUsersWithFilter() {
 	assert.Equal(suite.T(), "alice", result[0].Username)
 }
 
+func (suite *UserServiceTestSuite) TestListUsersWithFilterExpression() {
+	inactive := false
+	for _, u := range []struct {
+		username, domain string
+		active           bool
+	}{
+		{"alice", "corp.com", true}, {"bob", "corp.com", false}, {"carol", "example.com", false},
+	} {
+		user, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: u.username, Email: u.username + "@" + u.domain, FullName: u.username,
+		})
+		suite.Require().NoError(err)
+		if !u.active {
+			_, err = suite.service.UpdateUser(context.Background(), &model.UpdateUserRequest{ID: user.ID, IsActive: &inactive})
+			suite.Require().NoError(err)
+		}
+	}
+
+	filter := `is_active = false AND email = "*@corp.com" AND created_at >= "2020-01-01T00:00:00Z"`
+	users, total, _, _, err := suite.service.ListUsers(context.Background(), &model.ListUsersRequest{Filter: &filter})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), total)
+	assert.Equal(suite.T(), "bob", users[0].Username)
+
+	filter = "is_active = false AND"
+	_, _, _, _, err = suite.service.ListUsers(context.Background(), &model.ListUsersRequest{Filter: &filter})
+	appErr := errors.AsAppError(err)
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, appErr.Code)
+	assert.Contains(suite.T(), appErr.Message, "position 22")
+}
+
+func (suite *UserServiceTestSuite) TestListUsersOrderBy() {
+	for _, u := range []struct{ username, fullName string }{
+		{"carol", "Smith"}, {"alice", "Jones"}, {"bob", "Smith"},
+	} {
+		_, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: u.username, Email: u.username + "@example.com", FullName: u.fullName,
+		})
+		suite.Require().NoError(err)
+	}
+
+	users, _, _, _, err := suite.service.ListUsers(context.Background(), &model.ListUsersRequest{OrderBy: "full_name desc, username"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"bob", "carol", "alice"}, []string{users[0].Username, users[1].Username, users[2].Username})
+
+	// Equal keys fall back to ascending ID
+	users, _, _, _, err = suite.service.ListUsers(context.Background(), &model.ListUsersRequest{OrderBy: "full_name desc"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "carol", users[0].Username)
+
+	for _, orderBy := range []string{"password", "username asc", "username,", "email, email"} {
+		_, _, _, _, err = suite.service.ListUsers(context.Background(), &model.ListUsersRequest{OrderBy: orderBy})
+		assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code, orderBy)
+	}
+
+	sortBy := "unknown"
+	_, _, _, _, err = suite.service.ListUsers(context.Background(), &model.ListUsersRequest{SortBy: &sortBy})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *UserServiceTestSuite) TestListUsersWithTimeRange() {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"alice", "bob", "charlie"} {
+		user, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: name, Email: name + "@example.com", FullName: name,
+		})
+		suite.Require().NoError(err)
+		stored := suite.service.users[user.ID]
+		stored.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		stored.UpdatedAt = base.Add(time.Duration(i)*time.Hour + time.Minute)
+	}
+
+	after, before := base.Add(time.Hour), base.Add(2*time.Hour)
+	result, totalCount, _, _, err := suite.service.ListUsers(context.Background(), &model.ListUsersRequest{
+		TimeRange: model.TimeRange{CreatedAfter: &after, CreatedBefore: &before},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), totalCount)
+	assert.Equal(suite.T(), "bob", result[0].Username)
+
+	_, _, _, _, err = suite.service.ListUsers(context.Background(), &model.ListUsersRequest{
+		TimeRange: model.TimeRange{UpdatedAfter: &before, UpdatedBefore: &after},
+	})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+}
+
+func (suite *UserServiceTestSuite) TestWatchUsers() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher, err := suite.service.WatchUsers(ctx, &model.WatchUsersRequest{})
+	assert.NoError(suite.T(), err)
+
+	user, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+		Username: "watched",
+		Email:    "watched@example.com",
+		FullName: "Watched User",
+	})
+	assert.NoError(suite.T(), err)
+
+	newFullName := "Renamed User"
+	_, err = suite.service.UpdateUser(context.Background(), &model.UpdateUserRequest{ID: user.ID, FullName: &newFullName})
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.service.DeleteUser(context.Background(), user.ID))
+
+	expected := []model.EventType{model.EventCreated, model.EventUpdated, model.EventDeleted}
+	for i, eventType := range expected {
+		event := <-watcher.Events()
+		assert.Equal(suite.T(), int64(i+1), event.Sequence)
+		assert.Equal(suite.T(), eventType, event.Type)
+		assert.Equal(suite.T(), user.ID, event.User.ID)
+	}
+
+	cancel()
+	_, open := <-watcher.Events()
+	assert.False(suite.T(), open)
+	assert.NoError(suite.T(), watcher.Err())
+}
+
+func (suite *UserServiceTestSuite) TestWatchUsersResumeWithFilter() {
+	for i := 0; i < 3; i++ {
+		_, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: fmt.Sprintf("resume%d", i),
+			Email:    fmt.Sprintf("resume%d@example.com", i),
+			FullName: fmt.Sprintf("Resume User %d", i),
+		})
+		assert.NoError(suite.T(), err)
+	}
+	isActive := false
+	_, err := suite.service.UpdateUser(context.Background(), &model.UpdateUserRequest{ID: "3", IsActive: &isActive})
+	assert.NoError(suite.T(), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher, err := suite.service.WatchUsers(ctx, &model.WatchUsersRequest{SinceSequence: 1, IsActive: &isActive})
+	assert.NoError(suite.T(), err)
+
+	event := <-watcher.Events()
+	assert.Equal(suite.T(), int64(4), event.Sequence)
+	assert.Equal(suite.T(), model.EventUpdated, event.Type)
+	assert.Equal(suite.T(), "3", event.User.ID)
+	assert.Len(suite.T(), watcher.Events(), 0)
+
+	_, err = suite.service.WatchUsers(ctx, &model.WatchUsersRequest{SinceSequence: 5})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *UserServiceTestSuite) TestWatchUsersSlowConsumerDoesNotBlockWriters() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher, err := suite.service.WatchUsers(ctx, &model.WatchUsersRequest{})
+	assert.NoError(suite.T(), err)
+
+	for i := 0; i <= watchBufferSize; i++ {
+		_, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: fmt.Sprintf("slow%d", i),
+			Email:    fmt.Sprintf("slow%d@example.com", i),
+			FullName: fmt.Sprintf("Slow User %d", i),
+		})
+		assert.NoError(suite.T(), err)
+	}
+
+	received := 0
+	for range watcher.Events() {
+		received++
+	}
+	assert.Equal(suite.T(), watchBufferSize, received)
+	assert.Error(suite.T(), watcher.Err())
+	assert.Contains(suite.T(), watcher.Err().Error(), "resume from sequence 256")
+}
+
+func (suite *UserServiceTestSuite) TestStopWatchesEndsOpenWatches() {
+	watcher, err := suite.service.WatchUsers(context.Background(), &model.WatchUsersRequest{})
+	suite.Require().NoError(err)
+
+	suite.service.StopWatches()
+	_, open := <-watcher.Events()
+	assert.False(suite.T(), open)
+	assert.Equal(suite.T(), errors.ErrCodeServiceDown, errors.AsAppError(watcher.Err()).Code)
+
+	_, err = suite.service.WatchUsers(context.Background(), &model.WatchUsersRequest{})
+	assert.Equal(suite.T(), errors.ErrCodeServiceDown, errors.AsAppError(err).Code)
+}
+
+func (suite *UserServiceTestSuite) TestBatchCreateUsersBestEffort() {
+	req := &model.BatchCreateUsersRequest{
+		Requests: []model.CreateUserRequest{
+			{Username: "batch1", Email: "batch1@example.com", FullName: "Batch One"},
+			{Username: "batch1", Email: "other@example.com", FullName: "Duplicate In Batch"},
+			{Username: "batch3", Email: "", FullName: "Missing Email"},
+			{Username: "batch4", Email: "batch4@example.com", FullName: "Batch Four"},
+		},
+	}
+
+	results, err := suite.service.BatchCreateUsers(context.Background(), req)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 4)
+	assert.NotNil(suite.T(), results[0].User)
+	assert.Nil(suite.T(), results[0].Error)
+	assert.Equal(suite.T(), errors.ErrCodeAlreadyExists, results[1].Error.Code)
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, results[2].Error.Code)
+	assert.Equal(suite.T(), "batch4", results[3].User.Username)
+
+	_, total, _, _, err := suite.service.ListUsers(context.Background(), &model.ListUsersRequest{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(2), total)
+}
+
+func (suite *UserServiceTestSuite) TestBatchCreateUsersAtomic() {
+	_, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+		Username: "existing", Email: "existing@example.com", FullName: "Existing User",
+	})
+	assert.NoError(suite.T(), err)
+
+	req := &model.BatchCreateUsersRequest{
+		Atomic: true,
+		Requests: []model.CreateUserRequest{
+			{Username: "atomic1", Email: "atomic1@example.com", FullName: "Atomic One"},
+			{Username: "atomic2", Email: "existing@example.com", FullName: "Atomic Two"},
+		},
+	}
+
+	results, err := suite.service.BatchCreateUsers(context.Background(), req)
+	assert.Nil(suite.T(), results)
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), errors.ErrCodeAlreadyExists, errors.AsAppError(err).Code)
+	assert.Equal(suite.T(), "requests[1].email", errors.AsAppError(err).Field)
+
+	_, total, _, _, err := suite.service.ListUsers(context.Background(), &model.ListUsersRequest{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), total)
+}
+
+func (suite *UserServiceTestSuite) TestBatchCreateUsersAtomicPrefixesViolations() {
+	req := &model.BatchCreateUsersRequest{
+		Atomic: true,
+		Requests: []model.CreateUserRequest{
+			{Username: "atomic1", Email: "atomic1@example.com", FullName: "Atomic One"},
+			{Username: "atomic2", Email: "not-an-email", FullName: "Atomic Two"},
+		},
+	}
+
+	_, err := suite.service.BatchCreateUsers(context.Background(), req)
+	appErr := errors.AsAppError(err)
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, appErr.Code)
+	assert.Equal(suite.T(), "requests[1].email", appErr.Field)
+	suite.Require().Len(appErr.Violations, 1)
+	assert.Equal(suite.T(), "requests[1].email", appErr.Violations[0].Field)
+	assert.True(suite.T(), strings.HasPrefix(appErr.Violations[0].Description, "requests[1].email "), appErr.Violations[0].Description)
+}
+
+func (suite *UserServiceTestSuite) TestBatchGetUsers() {
+	user, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+		Username: "batchget", Email: "batchget@example.com", FullName: "Batch Get",
+	})
+	assert.NoError(suite.T(), err)
+
+	results, err := suite.service.BatchGetUsers(context.Background(), &model.BatchGetUsersRequest{IDs: []string{user.ID, "missing"}})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), user.ID, results[0].User.ID)
+	assert.Equal(suite.T(), errors.ErrCodeNotFound, results[1].Error.Code)
+
+	_, err = suite.service.BatchGetUsers(context.Background(), &model.BatchGetUsersRequest{IDs: []string{user.ID, "missing"}, Atomic: true})
+	assert.Error(suite.T(), err)
+
+	_, err = suite.service.BatchGetUsers(context.Background(), &model.BatchGetUsersRequest{})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *UserServiceTestSuite) TestExportUsersSnapshot() {
+	for _, username := range []string{"carol", "alice", "bob"} {
+		_, err := suite.service.CreateUser(context.Background(), &model.CreateUserRequest{
+			Username: username, Email: username + "@test.dev", FullName: username,
+		})
+		assert.NoError(suite.T(), err)
+	}
+
+	sortBy, filter := "username", "o"
+	users, err := suite.service.ExportUsers(context.Background(), &model.ExportUsersRequest{SortBy: &sortBy, Filter: &filter})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), users, 2)
+	assert.Equal(suite.T(), "bob", users[0].Username)
+	assert.Equal(suite.T(), "carol", users[1].Username)
+
+	// Later writes do not change a snapshot that is still being streamed
+	renamed := "robert"
+	_, err = suite.service.UpdateUser(context.Background(), &model.UpdateUserRequest{ID: users[0].ID, Username: &renamed})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "bob", users[0].Username)
+
+	users, err = suite.service.ExportUsers(context.Background(), &model.ExportUsersRequest{OrderBy: "username desc"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"robert", "carol", "alice"}, []string{users[0].Username, users[1].Username, users[2].Username})
+
+	future := time.Now().Add(time.Hour)
+	users, err = suite.service.ExportUsers(context.Background(), &model.ExportUsersRequest{TimeRange: model.TimeRange{CreatedAfter: &future}})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), users)
+}
+
+func (suite *UserServiceTestSuite) TestIdentityIsNormalizedBeforeValidation() {
+	ctx := context.Background()
+	user, err := suite.service.CreateUser(ctx, &model.CreateUserRequest{
+		Username: "carol", Email: " carol＠Example.com ", FullName: "Carol",
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "carol@example.com", user.Email)
+
+	email := "caroline＠example.org"
+	updated, err := suite.service.UpdateUser(ctx, &model.UpdateUserRequest{ID: user.ID, Email: &email})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "caroline@example.org", updated.Email)
+}
+
+func (suite *UserServiceTestSuite) TestCreateUserAppliesIdentityPolicy() {
+	ctx := context.Background()
+	user, err := suite.service.CreateUser(ctx, &model.CreateUserRequest{
+		Username: " Ａlice ",
+		Email:    "Alice@Example.COM",
+		FullName: "Alice",
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Alice", user.Username)
+	assert.Equal(suite.T(), "Alice@example.com", user.Email)
+
+	_, err = suite.service.CreateUser(ctx, &model.CreateUserRequest{Username: "alice", Email: "other@example.com", FullName: "A"})
+	assert.Equal(suite.T(), errors.ErrCodeAlreadyExists, errors.AsAppError(err).Code)
+	_, err = suite.service.CreateUser(ctx, &model.CreateUserRequest{Username: "alice2", Email: "ALICE@example.com", FullName: "A"})
+	assert.Equal(suite.T(), errors.ErrCodeAlreadyExists, errors.AsAppError(err).Code)
+
+	_, err = suite.service.CreateUser(ctx, &model.CreateUserRequest{Username: "Admin", Email: "root@localhost", FullName: "A"})
+	suite.Require().Error(err)
+	assert.Equal(suite.T(), []errors.FieldViolation{
+		{Field: "username", Description: "username is reserved"},
+		{Field: "email", Description: "email must have a domain such as example.com"},
+	}, errors.AsAppError(err).Violations)
+
+	bad := "-bob"
+	_, err = suite.service.UpdateUser(ctx, &model.UpdateUserRequest{ID: user.ID, Username: &bad})
+	assert.Equal(suite.T(), "username must start and end with a letter or digit", errors.AsAppError(err).Message)
+}
+
+func (suite *UserServiceTestSuite) TestCheckUserPolicy() {
+	now := time.Now()
+	for _, u := range []model.User{
+		{ID: "1", Username: "alice", Email: "alice@example.com"},
+		{ID: "2", Username: "Alice", Email: "bob@EXAMPLE.com"},
+		{ID: "3", Username: "root", Email: "root@example.com"},
+	} {
+		u.TenantID = tenant.DefaultID
+		u.CreatedAt, u.UpdatedAt = now, now
+		suite.service.users[u.ID] = &u
+	}
+
+	report, err := suite.service.CheckUserPolicy(context.Background(), false)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int32(3), report.Checked)
+	assert.Equal(suite.T(), int32(0), report.Repaired)
+	assert.Equal(suite.T(), []model.UserPolicyViolation{
+		{UserID: "2", Field: "username", Value: "Alice", Description: "username conflicts with user 1 after case folding"},
+		{UserID: "2", Field: "email", Value: "bob@EXAMPLE.com", Description: "email is not normalized", NormalizedValue: "bob@example.com"},
+		{UserID: "3", Field: "username", Value: "root", Description: "username is reserved"},
+	}, report.Violations)
+	assert.Equal(suite.T(), "bob@EXAMPLE.com", suite.service.users["2"].Email)
+
+	report, err = suite.service.CheckUserPolicy(context.Background(), true)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int32(1), report.Repaired)
+	assert.True(suite.T(), report.Violations[1].Repaired)
+	assert.Equal(suite.T(), "bob@example.com", suite.service.users["2"].Email)
+	assert.Equal(suite.T(), "Alice", suite.service.users["2"].Username)
+}
+
+// outbox records sent messages instead of delivering them
+type outbox struct {
+	mu       sync.Mutex
+	messages []notify.Message
+}
+
+func (o *outbox) Send(ctx context.Context, msg notify.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// lastToken returns the token carried by the latest message to the address
+func (o *outbox) lastToken(to string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].To == to {
+			return strings.Split(o.messages[i].Body, "\n\n")[2]
+		}
+	}
+	return ""
+}
+
+func (suite *UserServiceTestSuite) TestEmailVerification() {
+	ctx := context.Background()
+	sent := &outbox{}
+	suite.service.EnableEmailVerification(EmailVerification{
+		Sender:         sent,
+		Signer:         verification.NewSigner([]byte("secret"), time.Hour),
+		ResendInterval: time.Hour,
+	})
+
+	user, err := suite.service.CreateUser(ctx, &model.CreateUserRequest{Username: "alice", Email: "alice@example.com", FullName: "Alice"})
+	suite.Require().NoError(err)
+	assert.False(suite.T(), user.IsActive)
+	assert.False(suite.T(), user.EmailVerified)
+	suite.Require().Len(sent.messages, 1)
+	token := sent.lastToken("alice@example.com")
+
+	// Resending is rate-limited
+	err = suite.service.ResendVerification(ctx, &model.ResendVerificationRequest{ID: user.ID})
+	assert.Equal(suite.T(), errors.ErrCodeResourceExhausted, errors.AsAppError(err).Code)
+
+	_, err = suite.service.VerifyEmail(ctx, &model.VerifyEmailRequest{Token: "bogus"})
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, errors.AsAppError(err).Code)
+
+	verified, err := suite.service.VerifyEmail(ctx, &model.VerifyEmailRequest{Token: token})
+	suite.Require().NoError(err)
+	assert.True(suite.T(), verified.IsActive)
+	assert.True(suite.T(), verified.EmailVerified)
+
+	// Tokens are single-use
+	_, err = suite.service.VerifyEmail(ctx, &model.VerifyEmailRequest{Token: token})
+	assert.Error(suite.T(), err)
+	err = suite.service.ResendVerification(ctx, &model.ResendVerificationRequest{ID: user.ID})
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+
+	// A new email must be verified again, but the account stays active
+	email := "alice@corp.example.com"
+	updated, err := suite.service.UpdateUser(ctx, &model.UpdateUserRequest{ID: user.ID, Email: &email})
+	suite.Require().NoError(err)
+	assert.True(suite.T(), updated.IsActive)
+	assert.False(suite.T(), updated.EmailVerified)
+	suite.Require().Len(sent.messages, 2)
+
+	verified, err = suite.service.VerifyEmail(ctx, &model.VerifyEmailRequest{Token: sent.lastToken(email)})
+	suite.Require().NoError(err)
+	assert.True(suite.T(), verified.EmailVerified)
+}
+
+func (suite *UserServiceTestSuite) TestEmailVerificationDisabled() {
+	ctx := context.Background()
+	user, err := suite.service.CreateUser(ctx, &model.CreateUserRequest{Username: "bob", Email: "bob@example.com", FullName: "Bob"})
+	suite.Require().NoError(err)
+	assert.True(suite.T(), user.IsActive)
+
+	_, err = suite.service.VerifyEmail(ctx, &model.VerifyEmailRequest{Token: "token"})
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+	err = suite.service.ResendVerification(ctx, &model.ResendVerificationRequest{ID: user.ID})
+	assert.Equal(suite.T(), errors.ErrCodeFailedPrecondition, errors.AsAppError(err).Code)
+}
+
 func TestUserServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(UserServiceTestSuite))
 }
\ No newline at end of file
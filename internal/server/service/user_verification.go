@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-grpc-rest-demo/internal/server/errors"
+	"go-grpc-rest-demo/internal/server/model"
+	"go-grpc-rest-demo/internal/server/notify"
+	"go-grpc-rest-demo/internal/server/tenant"
+	"go-grpc-rest-demo/internal/server/validation"
+	"go-grpc-rest-demo/internal/server/verification"
+)
+
+// defaultResendInterval applies when EmailVerification does not set one
+const defaultResendInterval = time.Minute
+
+// EmailVerification configures the email verification flow
+type EmailVerification struct {
+	Sender notify.Sender
+	Signer *verification.Signer
+	// ResendInterval is the least time between two messages to one user
+	ResendInterval time.Duration
+}
+
+// pendingVerification is the token a user may currently verify with.
+// Issuing a new token replaces it, so only the latest one is accepted.
+type pendingVerification struct {
+	nonce string
+	// activate is set for sign-ups, whose account is activated on verification
+	activate bool
+	sentAt   time.Time
+}
+
+// EnableEmailVerification makes new users start inactive until they verify
+// their email, and sends a new token whenever a user's email changes. It
+// must be called before the service handles requests.
+func (s *UserService) EnableEmailVerification(config EmailVerification) {
+	if config.ResendInterval <= 0 {
+		config.ResendInterval = defaultResendInterval
+	}
+	s.verification = &config
+}
+
+// issueVerificationLocked issues a token for the user's current email,
+// replacing any earlier one, and queues the message carrying it. It does
+// nothing when verification is disabled. s.mu must be held.
+func (s *UserService) issueVerificationLocked(outbox *[]notify.Message, user *model.User, activate bool) {
+	if s.verification == nil {
+		return
+	}
+
+	now := time.Now()
+	token, claims := s.verification.Signer.Issue(user.ID, user.Email, now)
+	s.pending[user.ID] = &pendingVerification{nonce: claims.Nonce, activate: activate, sentAt: now}
+	*outbox = append(*outbox, notify.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm %s with this token before %s:\n\n%s\n\n"+
+			"Submit it to POST /api/v1/users:verifyEmail or the VerifyEmail RPC.\n",
+			user.FullName, user.Email, claims.ExpiresAt.Format(time.RFC3339), token),
+		SentAt: now,
+	})
+}
+
+// deliver sends queued messages once s.mu is released. Failures are logged:
+// the change that queued them has been made, and the user can ask for the
+// message again.
+func (s *UserService) deliver(ctx context.Context, outbox []notify.Message) {
+	for _, msg := range outbox {
+		if err := s.verification.Sender.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}
+}
+
+// VerifyEmail marks the email a token was issued for as verified, and
+// activates the account when the token was issued at sign-up. Each token can
+// be used once, and only while the user still has that email. The signed
+// token names its user, so it works without naming the user's tenant.
+func (s *UserService) VerifyEmail(ctx context.Context, req *model.VerifyEmailRequest) (*model.User, error) {
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+	if s.verification == nil {
+		return nil, errors.NewFailedPreconditionError("email verification is not enabled")
+	}
+	claims, err := s.verification.Signer.Verify(req.Token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, exists := s.pending[claims.UserID]
+	user := s.users[claims.UserID]
+	if !exists || pending.nonce != claims.Nonce || user == nil || user.Email != claims.Email {
+		return nil, errors.NewValidationError("token", "token is no longer valid; request a new one")
+	}
+
+	delete(s.pending, user.ID)
+	user.EmailVerified = true
+	if pending.activate {
+		user.IsActive = true
+	}
+	user.UpdatedAt = time.Now()
+	s.publish(model.EventUpdated, user)
+	return user, nil
+}
+
+// ResendVerification sends a new token to a user whose email is not
+// verified, invalidating earlier ones. Messages to one user are at least
+// the configured ResendInterval apart.
+func (s *UserService) ResendVerification(ctx context.Context, req *model.ResendVerificationRequest) error {
+	if err := validation.Validate(req); err != nil {
+		return err
+	}
+	if s.verification == nil {
+		return errors.NewFailedPreconditionError("email verification is not enabled")
+	}
+
+	var outbox []notify.Message
+	s.mu.Lock()
+	defer func() {
+		s.mu.Unlock()
+		s.deliver(ctx, outbox)
+	}()
+
+	user, err := s.getUserLocked(tenant.FromContext(ctx), req.ID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return errors.NewFailedPreconditionError(fmt.Sprintf("email of user %s is already verified", user.ID))
+	}
+
+	activate := false
+	if pending, exists := s.pending[user.ID]; exists {
+		if wait := time.Until(pending.sentAt.Add(s.verification.ResendInterval)); wait > 0 {
+			return errors.NewResourceExhaustedError(
+				fmt.Sprintf("a verification email was sent recently; retry in %s", wait.Round(time.Second)))
+		}
+		activate = pending.activate
+	}
+	s.issueVerificationLocked(&outbox, user, activate)
+	return nil
+}
@@ -1,5 +1,15 @@
 package service
 
+import (
+	"fmt"
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// maxBatchSize bounds the number of items handled by one batch call
+const maxBatchSize = 1000
+
 func paginate[T any](items []T, page, pageSize int32) ([]T, int32, int32, int32) {
 	if page < 1 {
 		page = 1
@@ -19,3 +29,45 @@ func paginate[T any](items []T, page, pageSize int32) ([]T, int32, int32, int32)
 
 	return items[start:end], total, page, pageSize
 }
+
+func validateBatchSize(field string, size int) error {
+	if size == 0 {
+		return errors.NewValidationError(field, fmt.Sprintf("%s must not be empty", field))
+	}
+	if size > maxBatchSize {
+		return errors.NewValidationError(field, fmt.Sprintf("%s must not contain more than %d items", field, maxBatchSize))
+	}
+	return nil
+}
+
+// batchItemError attributes the failure of one item to its position, which
+// is how an atomic batch reports the item that aborted it. The field paths of
+// its violations are prefixed alike.
+func batchItemError(field string, index int, err error) error {
+	item := fmt.Sprintf("%s[%d]", field, index)
+	appErr := *errors.AsAppError(err)
+	appErr.Message = item + ": " + appErr.Message
+	appErr.Field = itemPath(item, appErr.Field)
+	if appErr.Violations != nil {
+		appErr.Violations = make([]errors.FieldViolation, len(appErr.Violations))
+		for i, v := range errors.AsAppError(err).Violations {
+			path := itemPath(item, v.Field)
+			if rest, ok := strings.CutPrefix(v.Description, v.Field); ok && v.Field != "" {
+				v.Description = path + rest
+			} else {
+				v.Description = item + ": " + v.Description
+			}
+			v.Field = path
+			appErr.Violations[i] = v
+		}
+	}
+	return &appErr
+}
+
+// itemPath returns the path of field within a batch item
+func itemPath(item, field string) string {
+	if field == "" {
+		return item
+	}
+	return item + "." + field
+}
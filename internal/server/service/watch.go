@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+const (
+	// watchHistorySize is the number of past events kept for resuming watchers
+	watchHistorySize = 1024
+	// watchBufferSize is the number of live events a watcher may fall behind by
+	watchBufferSize = 256
+)
+
+// Watcher delivers change events until its context ends or it falls behind.
+type Watcher[E any] struct {
+	events chan E
+	err    error
+}
+
+// Events returns the channel of events. It is closed when the watch ends.
+func (w *Watcher[E]) Events() <-chan E {
+	return w.events
+}
+
+// Err reports why the event channel was closed. It is nil when the watch
+// ended because its context was done.
+func (w *Watcher[E]) Err() error {
+	return w.err
+}
+
+type watchEntry[E any] struct {
+	seq   int64
+	event E
+}
+
+type watchSubscriber[E any] struct {
+	watcher *Watcher[E]
+	match   func(E) bool
+	lastSeq int64
+}
+
+// eventBroker assigns sequence numbers to change events, keeps a bounded
+// history for resumption and fans events out to watchers. Publishing never
+// blocks: a watcher whose buffer is full is dropped and has to resume from
+// the last sequence it received.
+type eventBroker[E any] struct {
+	mu      sync.Mutex
+	seq     int64
+	history []watchEntry[E]
+	subs    map[*watchSubscriber[E]]struct{}
+	closed  bool
+}
+
+// errWatchesStopped ends the watches of a server that is shutting down
+var errWatchesStopped = &errors.AppError{Code: errors.ErrCodeServiceDown, Message: "server is shutting down"}
+
+func newEventBroker[E any]() *eventBroker[E] {
+	return &eventBroker[E]{
+		subs: make(map[*watchSubscriber[E]]struct{}),
+	}
+}
+
+// publish records the event built for the next sequence number and delivers
+// it to every matching watcher.
+func (b *eventBroker[E]) publish(build func(seq int64) E) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	entry := watchEntry[E]{seq: b.seq, event: build(b.seq)}
+	if len(b.history) == watchHistorySize {
+		b.history = append(b.history[:0], b.history[1:]...)
+	}
+	b.history = append(b.history, entry)
+
+	for sub := range b.subs {
+		if !sub.match(entry.event) {
+			continue
+		}
+		select {
+		case sub.watcher.events <- entry.event:
+			sub.lastSeq = entry.seq
+		default:
+			sub.watcher.err = errors.NewResourceExhaustedError(
+				fmt.Sprintf("watcher fell behind; resume from sequence %d", sub.lastSeq))
+			b.removeLocked(sub)
+		}
+	}
+}
+
+// subscribe registers a watcher that first replays retained events after
+// since and then receives live events until ctx is done.
+func (b *eventBroker[E]) subscribe(ctx context.Context, since int64, match func(E) bool) (*Watcher[E], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, errWatchesStopped
+	}
+	if since < 0 || since > b.seq {
+		return nil, errors.NewValidationError("since_sequence",
+			fmt.Sprintf("since_sequence must be between 0 and %d", b.seq))
+	}
+
+	var replay []E
+	if since > 0 {
+		oldest := b.seq + 1
+		if len(b.history) > 0 {
+			oldest = b.history[0].seq
+		}
+		if since < oldest-1 {
+			return nil, errors.NewFailedPreconditionError(
+				fmt.Sprintf("sequence %d is no longer retained; oldest available is %d", since, oldest))
+		}
+		for _, entry := range b.history {
+			if entry.seq > since && match(entry.event) {
+				replay = append(replay, entry.event)
+			}
+		}
+	}
+
+	watcher := &Watcher[E]{events: make(chan E, len(replay)+watchBufferSize)}
+	for _, event := range replay {
+		watcher.events <- event
+	}
+
+	sub := &watchSubscriber[E]{watcher: watcher, match: match, lastSeq: b.seq}
+	b.subs[sub] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.removeLocked(sub)
+	}()
+
+	return watcher, nil
+}
+
+// close ends every watch and refuses new ones
+func (b *eventBroker[E]) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		sub.watcher.err = errWatchesStopped
+		b.removeLocked(sub)
+	}
+}
+
+func (b *eventBroker[E]) removeLocked(sub *watchSubscriber[E]) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	close(sub.watcher.events)
+}
@@ -0,0 +1,26 @@
+// Package tenant carries the tenant a request acts for through its context.
+// The transports resolve the tenant once per request; services read it with
+// FromContext to scope every read and write.
+package tenant
+
+import (
+	"context"
+)
+
+// DefaultID is the tenant of requests that name none. It always exists.
+const DefaultID = "default"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx acting for the tenant id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx acts for, DefaultID when none was set
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(contextKey{}).(string); ok && id != "" {
+		return id
+	}
+	return DefaultID
+}
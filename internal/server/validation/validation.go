@@ -0,0 +1,191 @@
+// Package validation declares the rules a request must satisfy once per
+// request type and checks them in one place for both transports. Every
+// violation is reported at once, with the path of the offending field, as a
+// validation error carrying errors.FieldViolation entries.
+//
+// Rules are registered from package init functions:
+//
+//	validation.Register(
+//		validation.Field("username", func(r *CreateUserRequest) string { return r.Username }, validation.Required[string]()),
+//		validation.Field("email", func(r *CreateUserRequest) string { return r.Email }, validation.Required[string](), validation.Email()),
+//	)
+package validation
+
+import (
+	"cmp"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strings"
+
+	"go-grpc-rest-demo/internal/server/errors"
+)
+
+// Check inspects one value and returns what is wrong with it, such as
+// "is required", or "" when it is valid. The field path is prepended to
+// form the violation's description.
+type Check[V any] func(value V) string
+
+// Rule adds the violations of a request to v
+type Rule[T any] func(req *T, v *Violations)
+
+// Violations collects the violations of a request. Nested rules add to it
+// under a field prefix, such as "items[2]".
+type Violations struct {
+	prefix string
+	list   *[]errors.FieldViolation
+}
+
+// Add records that field violates a constraint. The description is
+// prefixed with the field path.
+func (v *Violations) Add(field, description string) {
+	path := v.path(field)
+	*v.list = append(*v.list, errors.FieldViolation{Field: path, Description: path + " " + description})
+}
+
+func (v *Violations) path(field string) string {
+	switch {
+	case v.prefix == "":
+		return field
+	case field == "":
+		return v.prefix
+	}
+	return v.prefix + "." + field
+}
+
+func (v *Violations) nested(prefix string) *Violations {
+	return &Violations{prefix: v.path(prefix), list: v.list}
+}
+
+var registry = map[reflect.Type]func(req any, v *Violations){}
+
+// Register declares the rules of request type T. It is meant to be called
+// from init functions, before requests are validated.
+func Register[T any](rules ...Rule[T]) {
+	registry[reflect.TypeFor[T]()] = func(req any, v *Violations) {
+		r := req.(*T)
+		for _, rule := range rules {
+			rule(r, v)
+		}
+	}
+}
+
+// Validate checks req, a pointer to a registered request type, against its
+// rules and returns a validation error listing every violation. Types
+// without rules are always valid.
+func Validate(req any) error {
+	var list []errors.FieldViolation
+	collect(req, &Violations{list: &list})
+	if len(list) == 0 {
+		return nil
+	}
+	return errors.NewViolationsError(list)
+}
+
+func collect(req any, v *Violations) {
+	t := reflect.TypeOf(req)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return
+	}
+	if rules, ok := registry[t.Elem()]; ok {
+		rules(req, v)
+	}
+}
+
+// Field applies checks to the value get extracts from a request, stopping at
+// the first failed check.
+func Field[T, V any](field string, get func(*T) V, checks ...Check[V]) Rule[T] {
+	return func(req *T, v *Violations) {
+		value := get(req)
+		for _, check := range checks {
+			if description := check(value); description != "" {
+				v.Add(field, description)
+				return
+			}
+		}
+	}
+}
+
+// Optional applies checks to a field only when it is set, as for partial
+// updates.
+func Optional[T, V any](field string, get func(*T) *V, checks ...Check[V]) Rule[T] {
+	return func(req *T, v *Violations) {
+		if value := get(req); value != nil {
+			Field(field, func(*T) V { return *value }, checks...)(req, v)
+		}
+	}
+}
+
+// Assert reports field with description unless ok holds, for constraints
+// that span several fields.
+func Assert[T any](field string, ok func(*T) bool, description string) Rule[T] {
+	return func(req *T, v *Violations) {
+		if !ok(req) {
+			v.Add(field, description)
+		}
+	}
+}
+
+// Each validates every element of a list field against the rules registered
+// for its type, reporting violations as "field[i].name".
+func Each[T, E any](field string, get func(*T) []E) Rule[T] {
+	return func(req *T, v *Violations) {
+		for i := range get(req) {
+			collect(&get(req)[i], v.nested(fmt.Sprintf("%s[%d]", field, i)))
+		}
+	}
+}
+
+// Required rejects the zero value
+func Required[V comparable]() Check[V] {
+	return func(value V) string {
+		var zero V
+		if value == zero {
+			return "is required"
+		}
+		return ""
+	}
+}
+
+// NotBlank rejects strings that are empty or only whitespace, for fields that
+// may be omitted but not cleared.
+func NotBlank() Check[string] {
+	return func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return "cannot be empty"
+		}
+		return ""
+	}
+}
+
+// Email requires a bare address such as "jane@example.com"
+func Email() Check[string] {
+	return func(value string) string {
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value {
+			return "must be a valid email address"
+		}
+		return ""
+	}
+}
+
+// Min requires a value of at least minimum
+func Min[V cmp.Ordered](minimum V) Check[V] {
+	return func(value V) string {
+		if value < minimum {
+			return fmt.Sprintf("must be at least %v", minimum)
+		}
+		return ""
+	}
+}
+
+// NonZero rejects zero, for signed amounts such as a stock delta
+func NonZero[V cmp.Ordered]() Check[V] {
+	return func(value V) string {
+		var zero V
+		if value == zero {
+			return "must not be zero"
+		}
+		return ""
+	}
+}
@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"testing"
+
+	"go-grpc-rest-demo/internal/server/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type line struct {
+	sku      string
+	quantity int32
+}
+
+type basket struct {
+	owner *string
+	lines []line
+}
+
+type unregistered struct{}
+
+func init() {
+	Register(
+		Field("sku", func(l *line) string { return l.sku }, Required[string]()),
+		Field("quantity", func(l *line) int32 { return l.quantity }, Min[int32](1)),
+	)
+	Register(
+		Optional("owner", func(b *basket) *string { return b.owner }, NotBlank(), Email()),
+		Each("lines", func(b *basket) []line { return b.lines }),
+	)
+}
+
+type ValidationTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ValidationTestSuite) TestValidRequest() {
+	owner := "jane@example.com"
+	assert.NoError(suite.T(), Validate(&basket{owner: &owner, lines: []line{{"a", 1}}}))
+	assert.NoError(suite.T(), Validate(&basket{lines: []line{{"a", 1}}}))
+	assert.NoError(suite.T(), Validate(&unregistered{}))
+}
+
+func (suite *ValidationTestSuite) TestReportsEveryViolationWithPath() {
+	owner := " "
+	err := Validate(&basket{owner: &owner, lines: []line{{"a", 1}, {"", 0}, {"c", 3}}})
+	suite.Require().Error(err)
+
+	appErr := errors.AsAppError(err)
+	assert.Equal(suite.T(), errors.ErrCodeValidationFailed, appErr.Code)
+	assert.Equal(suite.T(), "owner", appErr.Field)
+	assert.Equal(suite.T(), []errors.FieldViolation{
+		{Field: "owner", Description: "owner cannot be empty"},
+		{Field: "lines[1].sku", Description: "lines[1].sku is required"},
+		{Field: "lines[1].quantity", Description: "lines[1].quantity must be at least 1"},
+	}, appErr.Violations)
+	assert.Equal(suite.T(), "owner cannot be empty; lines[1].sku is required; lines[1].quantity must be at least 1", appErr.Message)
+}
+
+func (suite *ValidationTestSuite) TestEmail() {
+	check := Email()
+	assert.Empty(suite.T(), check("jane@example.com"))
+	assert.NotEmpty(suite.T(), check("Jane <jane@example.com>"))
+	assert.NotEmpty(suite.T(), check("jane"))
+}
+
+func TestValidationTestSuite(t *testing.T) {
+	suite.Run(t, new(ValidationTestSuite))
+}
@@ -0,0 +1,52 @@
+package verification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SignerTestSuite struct {
+	suite.Suite
+	signer *Signer
+	now    time.Time
+}
+
+func (suite *SignerTestSuite) SetupTest() {
+	suite.signer = NewSigner([]byte("secret"), time.Hour)
+	suite.now = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+}
+
+func (suite *SignerTestSuite) TestIssueAndVerify() {
+	token, issued := suite.signer.Issue("1", "alice@example.com", suite.now)
+
+	claims, err := suite.signer.Verify(token, suite.now.Add(59*time.Minute))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), issued, claims)
+	assert.Equal(suite.T(), "1", claims.UserID)
+	assert.Equal(suite.T(), "alice@example.com", claims.Email)
+
+	other, _ := suite.signer.Issue("1", "alice@example.com", suite.now)
+	assert.NotEqual(suite.T(), token, other)
+}
+
+func (suite *SignerTestSuite) TestVerifyRejectsExpiredAndTampered() {
+	token, _ := suite.signer.Issue("1", "alice@example.com", suite.now)
+
+	_, err := suite.signer.Verify(token, suite.now.Add(time.Hour))
+	assert.ErrorContains(suite.T(), err, "expired")
+
+	for _, bad := range []string{"", "abc", token + "x", "x" + token} {
+		_, err := suite.signer.Verify(bad, suite.now)
+		assert.ErrorContains(suite.T(), err, "invalid", bad)
+	}
+
+	_, err = NewSigner([]byte("other"), time.Hour).Verify(token, suite.now)
+	assert.ErrorContains(suite.T(), err, "invalid")
+}
+
+func TestSignerTestSuite(t *testing.T) {
+	suite.Run(t, new(SignerTestSuite))
+}